@@ -5,40 +5,80 @@ package admin
 
 import (
 	"context"
+	"fmt"
+	"strings"
 
-	adminapi "github.com/DioneProtocol/odysseygo/api/admin"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/rpc"
+
+	"github.com/DioneProtocol/opm/api"
 )
 
+// adminAPIPath is the path of the admin API relative to the node's URI.
+const adminAPIPath = "/ext/admin"
+
 var _ Client = &client{}
 
+// LoadVMsResult is the outcome of asking the node to load new VMs.
+type LoadVMsResult struct {
+	// NewVMs maps each VM the node loaded to its aliases.
+	NewVMs map[ids.ID][]string
+	// FailedVMs maps each VM the node failed to load to the reason it failed.
+	FailedVMs map[ids.ID]string
+}
+
 type Client interface {
-	LoadVMs() error
-	WhitelistSubnet(subnetID string) error
+	LoadVMs() (LoadVMsResult, error)
 }
 
 type client struct {
-	client adminapi.Client
+	requester rpc.EndpointRequester
 }
 
-func NewClient(url string) Client {
+// NewClient returns a client for the admin API at [url] that sends requests
+// through [apiClient]. [url] may be either the node's URI or the admin API
+// endpoint itself.
+func NewClient(url string, apiClient *api.Client) Client {
+	uri := strings.TrimSuffix(strings.TrimSuffix(url, "/"), adminAPIPath)
+
 	return &client{
-		client: adminapi.NewClient(url),
+		requester: apiClient.Requester(uri + adminAPIPath),
 	}
 }
 
-func (c *client) LoadVMs() error {
-	_, _, err := c.client.LoadVMs(context.Background())
-
-	return err
+// loadVMsReply mirrors the node's reply to admin.loadVMs. The odysseygo client
+// can't decode VM IDs used as map keys, so they're decoded here instead.
+type loadVMsReply struct {
+	NewVMs    map[string][]string `json:"newVMs"`
+	FailedVMs map[string]string   `json:"failedVMs"`
 }
 
-func (c *client) WhitelistSubnet(subnetID string) error {
-	// id, err := ids.FromString(subnetID)
-	// if err != nil {
-	// 	return err
-	// }
-	//
-	// _, err = c.client.WhitelistSubnet(context.Background(), id)
-	// return err
-	return nil
+func (c *client) LoadVMs() (LoadVMsResult, error) {
+	reply := &loadVMsReply{}
+	if err := c.requester.SendRequest(context.Background(), "admin.loadVMs", struct{}{}, reply); err != nil {
+		return LoadVMsResult{}, err
+	}
+
+	result := LoadVMsResult{
+		NewVMs:    make(map[ids.ID][]string, len(reply.NewVMs)),
+		FailedVMs: make(map[ids.ID]string, len(reply.FailedVMs)),
+	}
+
+	for vmID, aliases := range reply.NewVMs {
+		id, err := ids.FromString(vmID)
+		if err != nil {
+			return LoadVMsResult{}, fmt.Errorf("node returned invalid VM ID %s: %w", vmID, err)
+		}
+		result.NewVMs[id] = aliases
+	}
+
+	for vmID, reason := range reply.FailedVMs {
+		id, err := ids.FromString(vmID)
+		if err != nil {
+			return LoadVMsResult{}, fmt.Errorf("node returned invalid VM ID %s: %w", vmID, err)
+		}
+		result.FailedVMs[id] = reason
+	}
+
+	return result, nil
 }
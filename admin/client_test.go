@@ -0,0 +1,103 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package admin
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAdminAPI serves the admin API's loadVMs method with a canned reply.
+func fakeAdminAPI(t *testing.T, status int, reply string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, adminAPIPath, r.URL.Path)
+
+		request := struct {
+			Method string          `json:"method"`
+			ID     json.RawMessage `json:"id"`
+		}{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "admin.loadVMs", request.Method)
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "id": ` + string(request.ID) + `, ` + reply + `}`))
+	}))
+}
+
+func TestClientLoadVMs(t *testing.T) {
+	loadedID := ids.GenerateTestID()
+	failedID := ids.GenerateTestID()
+
+	tests := []struct {
+		name    string
+		status  int
+		reply   string
+		suffix  string
+		want    LoadVMsResult
+		wantErr bool
+	}{
+		{
+			name:   "loaded and failed",
+			status: http.StatusOK,
+			reply:  `"result": {"newVMs": {"` + loadedID.String() + `": ["foovm"]}, "failedVMs": {"` + failedID.String() + `": "bad binary"}}`,
+			suffix: adminAPIPath,
+			want: LoadVMsResult{
+				NewVMs:    map[ids.ID][]string{loadedID: {"foovm"}},
+				FailedVMs: map[ids.ID]string{failedID: "bad binary"},
+			},
+		},
+		{
+			name:   "nothing new",
+			status: http.StatusOK,
+			reply:  `"result": {"newVMs": {}}`,
+			want: LoadVMsResult{
+				NewVMs:    map[ids.ID][]string{},
+				FailedVMs: map[ids.ID]string{},
+			},
+		},
+		{
+			name:    "invalid VM ID",
+			status:  http.StatusOK,
+			reply:   `"result": {"newVMs": {"foobar": []}}`,
+			suffix:  adminAPIPath,
+			wantErr: true,
+		},
+		{
+			name:    "rpc error",
+			status:  http.StatusOK,
+			reply:   `"error": {"code": -32000, "message": "registry failure"}`,
+			suffix:  adminAPIPath,
+			wantErr: true,
+		},
+		{
+			name:    "http error",
+			status:  http.StatusInternalServerError,
+			reply:   `"result": {}`,
+			suffix:  adminAPIPath,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := fakeAdminAPI(t, test.status, test.reply)
+			defer server.Close()
+
+			got, err := NewClient(server.URL+test.suffix, nil).LoadVMs()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
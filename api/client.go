@@ -0,0 +1,231 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/DioneProtocol/odysseygo/utils/rpc"
+	"github.com/spf13/afero"
+
+	"github.com/DioneProtocol/opm/config"
+)
+
+const (
+	jsonRPCVersion = "2.0"
+	adminAPIPath   = "/ext/admin"
+)
+
+var (
+	errMissingClientKey  = errors.New("clientCert requires clientKey to be set")
+	errMissingClientCert = errors.New("clientKey requires clientCert to be set")
+	errInvalidCACert     = errors.New("no certificates found in caCert")
+	errEmptyResponse     = errors.New("response has neither a result nor an error")
+)
+
+// Client sends requests to a node's APIs using the configured TLS settings
+// and credentials.
+type Client struct {
+	httpClient *http.Client
+	credential config.NodeCredential
+}
+
+// NewClient returns a client for the node's APIs. Certificate files in
+// [credential] are read from [fs].
+func NewClient(fs afero.Fs, credential config.NodeCredential) (*Client, error) {
+	tlsConfig, err := newTLSConfig(fs, credential)
+	if err != nil {
+		return nil, err
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return &Client{
+		httpClient: &http.Client{Transport: transport},
+		credential: credential,
+	}, nil
+}
+
+func newTLSConfig(fs afero.Fs, credential config.NodeCredential) (*tls.Config, error) {
+	tlsConfig := &tls.Config{MinVersion: tls.VersionTLS12}
+
+	if credential.CACert != "" {
+		caCert, err := afero.ReadFile(fs, credential.CACert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read caCert: %w", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("%w: %s", errInvalidCACert, credential.CACert)
+		}
+		tlsConfig.RootCAs = pool
+	}
+
+	switch {
+	case credential.ClientCert != "" && credential.ClientKey == "":
+		return nil, errMissingClientKey
+	case credential.ClientCert == "" && credential.ClientKey != "":
+		return nil, errMissingClientCert
+	case credential.ClientCert != "":
+		clientCert, err := afero.ReadFile(fs, credential.ClientCert)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read clientCert: %w", err)
+		}
+		clientKey, err := afero.ReadFile(fs, credential.ClientKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read clientKey: %w", err)
+		}
+
+		certificate, err := tls.X509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("invalid client certificate: %w", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{certificate}
+	}
+
+	return tlsConfig, nil
+}
+
+// Requester returns a requester for the API at [uri]. A nil client sends
+// requests without any credentials.
+func (c *Client) Requester(uri string) rpc.EndpointRequester {
+	return &requester{
+		client: c,
+		uri:    uri,
+	}
+}
+
+// NodeURI returns the URI of the node serving the admin API at [endpoint].
+// Endpoints without a scheme default to http.
+// e.g. 127.0.0.1:9650/ext/admin => http://127.0.0.1:9650
+func NodeURI(endpoint string) string {
+	if !strings.HasPrefix(endpoint, "http://") && !strings.HasPrefix(endpoint, "https://") {
+		endpoint = fmt.Sprintf("http://%s", endpoint)
+	}
+
+	return strings.TrimSuffix(strings.TrimSuffix(endpoint, "/"), adminAPIPath)
+}
+
+var _ rpc.EndpointRequester = &requester{}
+
+type requester struct {
+	client *Client
+	uri    string
+}
+
+type jsonRPCRequest struct {
+	Version string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params"`
+	ID      uint64      `json:"id"`
+}
+
+type jsonRPCResponse struct {
+	Result json.RawMessage `json:"result"`
+	Error  *jsonRPCError   `json:"error"`
+}
+
+type jsonRPCError struct {
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+func (e *jsonRPCError) Error() string {
+	return e.Message
+}
+
+func (r *requester) SendRequest(
+	ctx context.Context,
+	method string,
+	params interface{},
+	reply interface{},
+	options ...rpc.Option,
+) error {
+	uri, err := url.Parse(r.uri)
+	if err != nil {
+		return err
+	}
+
+	ops := rpc.NewOptions(options)
+	uri.RawQuery = ops.QueryParams().Encode()
+
+	body, err := json.Marshal(jsonRPCRequest{
+		Version: jsonRPCVersion,
+		Method:  method,
+		Params:  params,
+		ID:      rand.Uint64(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode client params: %w", err)
+	}
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, uri.String(), bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+
+	request.Header = ops.Headers()
+	request.Header.Set("Content-Type", "application/json")
+
+	httpClient := http.DefaultClient
+	if r.client != nil {
+		httpClient = r.client.httpClient
+		r.client.authenticate(request)
+	}
+
+	resp, err := httpClient.Do(request)
+	if err != nil {
+		return fmt.Errorf("failed to issue request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("received status code: %d", resp.StatusCode)
+	}
+
+	response := jsonRPCResponse{}
+	if err := json.NewDecoder(resp.Body).Decode(&response); err != nil {
+		return fmt.Errorf("failed to decode client response: %w", err)
+	}
+
+	switch {
+	case response.Error != nil:
+		return response.Error
+	case response.Result == nil:
+		return errEmptyResponse
+	}
+
+	if err := json.Unmarshal(response.Result, reply); err != nil {
+		return fmt.Errorf("failed to decode client response: %w", err)
+	}
+
+	return nil
+}
+
+// authenticate adds the configured headers and credentials to [request].
+func (c *Client) authenticate(request *http.Request) {
+	for key, value := range c.credential.Headers {
+		request.Header.Set(key, value)
+	}
+
+	switch {
+	case c.credential.Token != "":
+		request.Header.Set("Authorization", fmt.Sprintf("Bearer %s", c.credential.Token))
+	case c.credential.Username != "" || c.credential.Password != "":
+		request.SetBasicAuth(c.credential.Username, c.credential.Password)
+	}
+}
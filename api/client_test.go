@@ -0,0 +1,312 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package api
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/DioneProtocol/odysseygo/utils/rpc"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/config"
+)
+
+type echoReply struct {
+	Authorization string `json:"authorization"`
+	APIKey        string `json:"apiKey"`
+	Query         string `json:"query"`
+	Method        string `json:"method"`
+}
+
+// echoHandler replies to every request with the request's method and
+// credentials.
+func echoHandler(t *testing.T) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		request := jsonRPCRequest{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, jsonRPCVersion, request.Version)
+
+		result, err := json.Marshal(echoReply{
+			Authorization: r.Header.Get("Authorization"),
+			APIKey:        r.Header.Get("X-Api-Key"),
+			Query:         r.URL.RawQuery,
+			Method:        request.Method,
+		})
+		assert.NoError(t, err)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "id": 1, "result": ` + string(result) + `}`))
+	})
+}
+
+func TestNodeURI(t *testing.T) {
+	tests := []struct {
+		endpoint string
+		want     string
+	}{
+		{endpoint: "127.0.0.1:9650/ext/admin", want: "http://127.0.0.1:9650"},
+		{endpoint: "127.0.0.1:9650", want: "http://127.0.0.1:9650"},
+		{endpoint: "https://node.example.com/ext/admin/", want: "https://node.example.com"},
+		{endpoint: "http://node.example.com:9650/", want: "http://node.example.com:9650"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.endpoint, func(t *testing.T) {
+			assert.Equal(t, test.want, NodeURI(test.endpoint))
+		})
+	}
+}
+
+func TestRequesterCredentials(t *testing.T) {
+	tests := []struct {
+		name       string
+		credential config.NodeCredential
+		want       echoReply
+	}{
+		{
+			name: "no credentials",
+			want: echoReply{Method: "foo.bar"},
+		},
+		{
+			name: "token and headers",
+			credential: config.NodeCredential{
+				Token:   "secret",
+				Headers: map[string]string{"X-Api-Key": "key"},
+			},
+			want: echoReply{
+				Authorization: "Bearer secret",
+				APIKey:        "key",
+				Method:        "foo.bar",
+			},
+		},
+		{
+			name: "basic auth",
+			credential: config.NodeCredential{
+				Username: "user",
+				Password: "pass",
+			},
+			want: echoReply{
+				Authorization: "Basic dXNlcjpwYXNz",
+				Method:        "foo.bar",
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(echoHandler(t))
+			defer server.Close()
+
+			client, err := NewClient(afero.NewMemMapFs(), test.credential)
+			assert.NoError(t, err)
+
+			reply := echoReply{}
+			assert.NoError(t, client.Requester(server.URL).SendRequest(context.Background(), "foo.bar", struct{}{}, &reply))
+			assert.Equal(t, test.want, reply)
+		})
+	}
+}
+
+func TestRequesterOptions(t *testing.T) {
+	server := httptest.NewServer(echoHandler(t))
+	defer server.Close()
+
+	reply := echoReply{}
+	assert.NoError(t, (*Client)(nil).Requester(server.URL).SendRequest(
+		context.Background(),
+		"foo.bar",
+		struct{}{},
+		&reply,
+		rpc.WithHeader("X-Api-Key", "key"),
+		rpc.WithQueryParam("a", "b"),
+	))
+	assert.Equal(t, echoReply{APIKey: "key", Query: "a=b", Method: "foo.bar"}, reply)
+}
+
+func TestRequesterErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		response string
+	}{
+		{
+			name:     "rpc error",
+			response: `{"jsonrpc": "2.0", "id": 1, "error": {"code": -32000, "message": "oops"}}`,
+		},
+		{
+			name:     "empty response",
+			response: `{"jsonrpc": "2.0", "id": 1}`,
+		},
+		{
+			name:     "malformed response",
+			response: `{`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+				_, _ = w.Write([]byte(test.response))
+			}))
+			defer server.Close()
+
+			reply := echoReply{}
+			assert.Error(t, (*Client)(nil).Requester(server.URL).SendRequest(context.Background(), "foo.bar", struct{}{}, &reply))
+		})
+	}
+}
+
+func TestRequesterTLS(t *testing.T) {
+	clientCert, clientKey := generateCertificate(t)
+
+	server := httptest.NewUnstartedServer(echoHandler(t))
+	clientCAs := x509.NewCertPool()
+	assert.True(t, clientCAs.AppendCertsFromPEM(clientCert))
+	server.TLS = &tls.Config{
+		ClientAuth: tls.RequireAndVerifyClientCert,
+		ClientCAs:  clientCAs,
+		MinVersion: tls.VersionTLS12,
+	}
+	server.StartTLS()
+	defer server.Close()
+
+	fs := afero.NewMemMapFs()
+	serverCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+	assert.NoError(t, afero.WriteFile(fs, "ca.pem", serverCert, perms.ReadWrite))
+	assert.NoError(t, afero.WriteFile(fs, "client.pem", clientCert, perms.ReadWrite))
+	assert.NoError(t, afero.WriteFile(fs, "client-key.pem", clientKey, perms.ReadWrite))
+
+	tests := []struct {
+		name       string
+		credential config.NodeCredential
+		wantErr    bool
+	}{
+		{
+			name: "trusted with client certificate",
+			credential: config.NodeCredential{
+				CACert:     "ca.pem",
+				ClientCert: "client.pem",
+				ClientKey:  "client-key.pem",
+			},
+		},
+		{
+			name: "missing client certificate",
+			credential: config.NodeCredential{
+				CACert: "ca.pem",
+			},
+			wantErr: true,
+		},
+		{
+			name: "untrusted server",
+			credential: config.NodeCredential{
+				ClientCert: "client.pem",
+				ClientKey:  "client-key.pem",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, err := NewClient(fs, test.credential)
+			assert.NoError(t, err)
+
+			reply := echoReply{}
+			err = client.Requester(server.URL).SendRequest(context.Background(), "foo.bar", struct{}{}, &reply)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, "foo.bar", reply.Method)
+		})
+	}
+}
+
+func TestNewClientInvalidTLS(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	assert.NoError(t, afero.WriteFile(fs, "invalid.pem", []byte("foobar"), perms.ReadWrite))
+
+	tests := []struct {
+		name       string
+		credential config.NodeCredential
+		wantErr    error
+	}{
+		{
+			name:       "missing ca file",
+			credential: config.NodeCredential{CACert: "missing.pem"},
+		},
+		{
+			name:       "invalid ca file",
+			credential: config.NodeCredential{CACert: "invalid.pem"},
+			wantErr:    errInvalidCACert,
+		},
+		{
+			name:       "missing client key",
+			credential: config.NodeCredential{ClientCert: "invalid.pem"},
+			wantErr:    errMissingClientKey,
+		},
+		{
+			name:       "missing client cert",
+			credential: config.NodeCredential{ClientKey: "invalid.pem"},
+			wantErr:    errMissingClientCert,
+		},
+		{
+			name:       "invalid key pair",
+			credential: config.NodeCredential{ClientCert: "invalid.pem", ClientKey: "invalid.pem"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := NewClient(fs, test.credential)
+			if test.wantErr == nil {
+				assert.Error(t, err)
+			} else {
+				assert.ErrorIs(t, err, test.wantErr)
+			}
+		})
+	}
+}
+
+// generateCertificate returns a PEM-encoded self-signed client certificate
+// and its key.
+func generateCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "opm"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	assert.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
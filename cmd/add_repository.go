@@ -4,14 +4,23 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
+
+	"github.com/DioneProtocol/opm/storage"
 )
 
 func addRepository(fs afero.Fs) *cobra.Command {
 	url := ""
 	alias := ""
 	branch := ""
+	tag := ""
+	commit := ""
+	tagPattern := ""
+	source := ""
+	publicKey := ""
 
 	command := &cobra.Command{
 		Use:   "add-repository",
@@ -24,25 +33,66 @@ func addRepository(fs afero.Fs) *cobra.Command {
 		panic(err)
 	}
 
-	command.PersistentFlags().StringVar(&url, "url", "", "url to the repository")
+	command.PersistentFlags().StringVar(&url, "url", "", "url to the repository, or its path for local repositories")
 	err = command.MarkPersistentFlagRequired("url")
 	if err != nil {
 		panic(err)
 	}
 
 	command.PersistentFlags().StringVar(&branch, "branch", "", "branch name to track")
-	err = command.MarkPersistentFlagRequired("branch")
-	if err != nil {
-		panic(err)
-	}
+	command.PersistentFlags().StringVar(&tag, "tag", "", "tag to stay at")
+	command.PersistentFlags().StringVar(&commit, "commit", "", "full hash of the commit to stay at")
+	command.PersistentFlags().StringVar(&tagPattern, "tag-pattern", "", "pattern of the tags to track (e.g. 'v1.*'), following the newest by semantic version")
+	command.PersistentFlags().StringVar(&source, "source", string(storage.GitSource), "where the repository comes from (git, http or local)")
+	command.PersistentFlags().StringVar(&publicKey, "public-key", "", "hex-encoded ed25519 key the index of an http repository is signed with")
 
 	command.RunE = func(_ *cobra.Command, _ []string) error {
+		var (
+			referenceType storage.ReferenceType
+			reference     string
+			set           int
+		)
+		for _, flag := range []struct {
+			referenceType storage.ReferenceType
+			value         string
+		}{
+			{referenceType: storage.BranchReference, value: branch},
+			{referenceType: storage.TagReference, value: tag},
+			{referenceType: storage.CommitReference, value: commit},
+			{referenceType: storage.TagPatternReference, value: tagPattern},
+		} {
+			if flag.value != "" {
+				referenceType, reference = flag.referenceType, flag.value
+				set++
+			}
+		}
+
+		switch sourceType := storage.SourceType(source); sourceType {
+		case storage.GitSource:
+			if set != 1 {
+				return fmt.Errorf("exactly one of --branch, --tag, --commit or --tag-pattern is required")
+			}
+		case storage.HTTPSource, storage.LocalSource:
+			if set != 0 {
+				return fmt.Errorf("%s repositories can't be tracked by a branch, tag, commit or tag pattern", sourceType)
+			}
+		default:
+			return fmt.Errorf("unknown source %q (must be git, http or local)", source)
+		}
+
 		opm, err := initOPM(fs)
 		if err != nil {
 			return err
 		}
 
-		return opm.AddRepository(alias, url, branch)
+		switch storage.SourceType(source) {
+		case storage.HTTPSource:
+			return opm.AddIndexRepository(alias, url, publicKey)
+		case storage.LocalSource:
+			return opm.AddLocalRepository(alias, url)
+		default:
+			return opm.AddRepository(alias, url, referenceType, reference)
+		}
 	}
 
 	return command
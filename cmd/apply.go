@@ -0,0 +1,41 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func apply(fs afero.Fs) *cobra.Command {
+	file := ""
+	locked := ""
+	dryRun := false
+	force := false
+	command := &cobra.Command{
+		Use:   "apply",
+		Short: "Converges repositories, virtual machines and subnets on a manifest",
+		Long: "Converges the tracked repositories, installed virtual machines and joined subnets on the " +
+			"desired state declared in a manifest. Anything not declared in the manifest is removed.",
+	}
+	command.PersistentFlags().StringVarP(&file, "file", "f", "", "path to the manifest")
+	command.PersistentFlags().StringVar(&locked, "locked", "", "path to a lockfile to install virtual machines from")
+	command.PersistentFlags().BoolVar(&dryRun, "dry-run", false, "only show the plan without changing anything")
+	command.PersistentFlags().BoolVar(&force, "force", false, "install even if a virtual machine is incompatible with the node")
+	err := command.MarkPersistentFlagRequired("file")
+	if err != nil {
+		panic(err)
+	}
+
+	command.RunE = func(_ *cobra.Command, _ []string) error {
+		opm, err := initOPM(fs)
+		if err != nil {
+			return err
+		}
+
+		return opm.Apply(file, locked, dryRun, force)
+	}
+
+	return command
+}
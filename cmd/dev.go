@@ -0,0 +1,76 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func dev(fs afero.Fs) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "dev",
+		Short: "Develops plugin definitions against a local working tree.",
+	}
+
+	command.AddCommand(
+		link(fs),
+		watch(fs),
+	)
+
+	return command
+}
+
+func link(fs afero.Fs) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "link <alias> <path>",
+		Short: "Tracks a local working tree as a repository, and syncs its definitions.",
+		Args:  cobra.ExactArgs(2),
+	}
+
+	command.RunE = func(_ *cobra.Command, args []string) error {
+		opm, err := initOPM(fs)
+		if err != nil {
+			return err
+		}
+
+		return opm.Link(args[0], args[1])
+	}
+
+	return command
+}
+
+func watch(fs afero.Fs) *cobra.Command {
+	interval := time.Second
+	install := false
+	force := false
+	command := &cobra.Command{
+		Use:   "watch",
+		Short: "Syncs local repositories whenever their definitions change.",
+		Args:  cobra.NoArgs,
+	}
+	command.Flags().DurationVar(&interval, "interval", time.Second, "how often to check for changes")
+	command.Flags().BoolVar(&install, "install", false, "reinstall installed virtual machines whose artifact changed")
+	command.Flags().BoolVar(&force, "force", false, "reinstall even if a virtual machine is incompatible with the node")
+
+	command.RunE = func(_ *cobra.Command, _ []string) error {
+		opm, err := initOPM(fs)
+		if err != nil {
+			return err
+		}
+
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+		defer stop()
+
+		return opm.Watch(ctx, interval, install, force)
+	}
+
+	return command
+}
@@ -0,0 +1,29 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func freeze(fs afero.Fs) *cobra.Command {
+	output := ""
+	command := &cobra.Command{
+		Use:   "freeze",
+		Short: "Writes a lockfile recording every installed virtual machine",
+	}
+	command.PersistentFlags().StringVarP(&output, "output", "o", "opm.lock", "path to write the lockfile to")
+
+	command.RunE = func(_ *cobra.Command, _ []string) error {
+		opm, err := initOPM(fs)
+		if err != nil {
+			return err
+		}
+
+		return opm.Freeze(output)
+	}
+
+	return command
+}
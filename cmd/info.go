@@ -0,0 +1,27 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func info(fs afero.Fs) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "info <alias>",
+		Short: "Shows the definition and installation state of a virtual machine or subnet.",
+		Args:  cobra.ExactArgs(1),
+	}
+	command.RunE = func(_ *cobra.Command, args []string) error {
+		opm, err := initOPM(fs)
+		if err != nil {
+			return err
+		}
+
+		return opm.Info(args[0])
+	}
+
+	return command
+}
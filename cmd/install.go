@@ -4,29 +4,39 @@
 package cmd
 
 import (
+	"fmt"
+
 	"github.com/spf13/afero"
 	"github.com/spf13/cobra"
 )
 
 func install(fs afero.Fs) *cobra.Command {
 	vm := ""
+	locked := ""
+	force := false
 	command := &cobra.Command{
 		Use:   "install-vm",
 		Short: "Installs a virtual machine by its alias",
 	}
-	command.PersistentFlags().StringVar(&vm, "vm", "", "vm alias to install")
-	err := command.MarkPersistentFlagRequired("vm")
-	if err != nil {
-		panic(err)
-	}
+	command.PersistentFlags().StringVar(&vm, "vm", "", "reference to the vm to install (e.g. spacesvm, organization/repository:spacesvm@^v1.2.0)")
+	command.PersistentFlags().StringVar(&locked, "locked", "", "path to a lockfile to install all locked virtual machines from")
+	command.PersistentFlags().BoolVar(&force, "force", false, "install even if a virtual machine is incompatible with the node")
 
 	command.RunE = func(_ *cobra.Command, _ []string) error {
+		if (vm == "") == (locked == "") {
+			return fmt.Errorf("exactly one of --vm or --locked is required")
+		}
+
 		opm, err := initOPM(fs)
 		if err != nil {
 			return err
 		}
 
-		return opm.Install(vm)
+		if locked != "" {
+			return opm.InstallLocked(locked, force)
+		}
+
+		return opm.Install(vm, force)
 	}
 
 	return command
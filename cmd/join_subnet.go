@@ -10,13 +10,15 @@ import (
 
 func joinSubnet(fs afero.Fs) *cobra.Command {
 	subnet := ""
+	force := false
 
 	command := &cobra.Command{
 		Use:   "join-subnet",
 		Short: "Installs all virtual machines for a subnet.",
 	}
 
-	command.PersistentFlags().StringVar(&subnet, "subnet", "", "subnet alias to join")
+	command.PersistentFlags().StringVar(&subnet, "subnet", "", "reference to the subnet to join")
+	command.PersistentFlags().BoolVar(&force, "force", false, "install even if a virtual machine is incompatible with the node")
 	err := command.MarkPersistentFlagRequired("subnet")
 	if err != nil {
 		panic(err)
@@ -28,7 +30,7 @@ func joinSubnet(fs afero.Fs) *cobra.Command {
 			return err
 		}
 
-		return opm.JoinSubnet(subnet)
+		return opm.JoinSubnet(subnet, force)
 	}
 
 	return command
@@ -0,0 +1,35 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func leaveSubnet(fs afero.Fs) *cobra.Command {
+	subnet := ""
+
+	command := &cobra.Command{
+		Use:   "leave-subnet",
+		Short: "Stops tracking a subnet.",
+	}
+
+	command.PersistentFlags().StringVar(&subnet, "subnet", "", "reference to the subnet to leave")
+	err := command.MarkPersistentFlagRequired("subnet")
+	if err != nil {
+		panic(err)
+	}
+
+	command.RunE = func(_ *cobra.Command, _ []string) error {
+		opm, err := initOPM(fs)
+		if err != nil {
+			return err
+		}
+
+		return opm.LeaveSubnet(subnet)
+	}
+
+	return command
+}
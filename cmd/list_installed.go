@@ -0,0 +1,26 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func listInstalled(fs afero.Fs) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "list-installed",
+		Short: "Lists all virtual machines installed by the opm.",
+	}
+	command.RunE = func(_ *cobra.Command, _ []string) error {
+		opm, err := initOPM(fs)
+		if err != nil {
+			return err
+		}
+
+		return opm.ListInstalled()
+	}
+
+	return command
+}
@@ -0,0 +1,27 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func outdated(fs afero.Fs) *cobra.Command {
+	command := &cobra.Command{
+		Use: "outdated",
+		Short: "Lists installed virtual machines that have an upgrade available " +
+			"or are no longer defined in a tracked repository.",
+	}
+	command.RunE = func(_ *cobra.Command, _ []string) error {
+		opm, err := initOPM(fs)
+		if err != nil {
+			return err
+		}
+
+		return opm.Outdated()
+	}
+
+	return command
+}
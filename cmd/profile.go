@@ -0,0 +1,201 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/DioneProtocol/opm/config"
+)
+
+// profileSettings maps the flags a profile can override to its fields.
+func profileSettings(profile *config.Profile) map[string]*string {
+	return map[string]*string{
+		pluginPathKey:       &profile.PluginPath,
+		adminAPIEndpointKey: &profile.AdminAPIEndpoint,
+		nodeConfigDirKey:    &profile.NodeConfigDir,
+		nodeConfigFileKey:   &profile.NodeConfigFile,
+	}
+}
+
+func profile(fs afero.Fs) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "profile",
+		Short: "Manages node profiles.",
+		// Profiles are managed without selecting one, so a missing profile
+		// doesn't get in the way of fixing it.
+		PersistentPreRunE: func(*cobra.Command, []string) error {
+			return initializeConfig(fs)
+		},
+	}
+
+	command.AddCommand(
+		listProfiles(fs),
+		addProfile(fs),
+		removeProfile(fs),
+	)
+
+	return command
+}
+
+func listProfiles(fs afero.Fs) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "list",
+		Short: "Lists all node profiles.",
+		Args:  cobra.NoArgs,
+	}
+
+	command.RunE = func(_ *cobra.Command, _ []string) error {
+		profiles, err := config.ReadProfiles(fs, configFilePath())
+		if err != nil {
+			return err
+		}
+
+		if len(profiles) == 0 {
+			fmt.Printf("No profiles found in %s.\n", configFilePath())
+			return nil
+		}
+
+		names := make([]string, 0, len(profiles))
+		for name := range profiles {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		active := viper.GetString(profileKey)
+
+		w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+		fmt.Fprintln(w, "\tname\tplugin-path\tadmin-api-endpoint\tnode-config-dir\tnode-config-file")
+		for _, name := range names {
+			marker := ""
+			if name == active {
+				marker = "*"
+			}
+
+			profile := profiles[name]
+			fmt.Fprintf(
+				w,
+				"%s\t%s\t%s\t%s\t%s\t%s\n",
+				marker,
+				name,
+				valueOrDefault(profile.PluginPath),
+				valueOrDefault(profile.AdminAPIEndpoint),
+				valueOrDefault(profile.NodeConfigDir),
+				valueOrDefault(profile.NodeConfigFile),
+			)
+		}
+		return w.Flush()
+	}
+
+	return command
+}
+
+func addProfile(fs afero.Fs) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "add <name>",
+		Short: "Adds a node profile, or updates an existing one.",
+		Long: "Adds a node profile, or updates an existing one. The profile's settings are taken from the " +
+			"--plugin-path, --admin-api-endpoint, --node-config-dir and --node-config-file flags.",
+		Args: cobra.ExactArgs(1),
+	}
+
+	command.RunE = func(cmd *cobra.Command, args []string) error {
+		name := args[0]
+		if err := config.VerifyProfileName(name); err != nil {
+			return err
+		}
+
+		profiles, err := config.ReadProfiles(fs, configFilePath())
+		if err != nil {
+			return err
+		}
+
+		profile, exists := profiles[name]
+		changed := false
+		for key, value := range profileSettings(&profile) {
+			if cmd.Flags().Changed(key) {
+				*value = viper.GetString(key)
+				changed = true
+			}
+		}
+		if !changed {
+			return fmt.Errorf("no settings given for profile %s (use --%s, --%s, --%s or --%s)", name, pluginPathKey, adminAPIEndpointKey, nodeConfigDirKey, nodeConfigFileKey)
+		}
+
+		if err := config.WriteProfile(fs, configFilePath(), name, profile); err != nil {
+			return err
+		}
+
+		if exists {
+			fmt.Printf("Updated profile %s in %s.\n", name, configFilePath())
+		} else {
+			fmt.Printf("Added profile %s to %s.\n", name, configFilePath())
+		}
+		return nil
+	}
+
+	return command
+}
+
+func removeProfile(fs afero.Fs) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "remove <name>",
+		Short: "Removes a node profile.",
+		Args:  cobra.ExactArgs(1),
+	}
+
+	command.RunE = func(_ *cobra.Command, args []string) error {
+		name := args[0]
+
+		removed, err := config.RemoveProfile(fs, configFilePath(), name)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return fmt.Errorf("profile %s doesn't exist in %s", name, configFilePath())
+		}
+
+		fmt.Printf("Removed profile %s from %s.\n", name, configFilePath())
+
+		opm, err := initOPM(fs)
+		if err != nil {
+			return err
+		}
+		vms, subnets, err := opm.ForgetProfile(name)
+		if err != nil {
+			return err
+		}
+
+		if len(vms) > 0 {
+			fmt.Printf("Forgot the virtual machines installed for %s. Their binaries were left in its plugin directory:\n", name)
+			for _, vm := range vms {
+				fmt.Printf("  %s\n", vm)
+			}
+		}
+		if len(subnets) > 0 {
+			fmt.Printf("Forgot the subnets joined for %s. Its node config still tracks them:\n", name)
+			for _, subnet := range subnets {
+				fmt.Printf("  %s\n", subnet)
+			}
+		}
+		return nil
+	}
+
+	return command
+}
+
+func valueOrDefault(value string) string {
+	if value == "" {
+		return "(default)"
+	}
+
+	return value
+}
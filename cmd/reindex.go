@@ -0,0 +1,30 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func reindex(fs afero.Fs) *cobra.Command {
+	check := false
+
+	command := &cobra.Command{
+		Use:   "reindex",
+		Short: "rebuilds the alias registry from the definitions of all tracked repositories",
+	}
+	command.PersistentFlags().BoolVar(&check, "check", false, "only report inconsistencies without rebuilding the registry")
+
+	command.RunE = func(_ *cobra.Command, _ []string) error {
+		opm, err := initOPM(fs)
+		if err != nil {
+			return err
+		}
+
+		return opm.Reindex(check)
+	}
+
+	return command
+}
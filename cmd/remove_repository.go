@@ -10,12 +10,14 @@ import (
 
 func removeRepository(fs afero.Fs) *cobra.Command {
 	alias := ""
+	purge := false
 
 	command := &cobra.Command{
 		Use:   "remove-repository",
 		Short: "removes a repository from the list of tracked repositories",
 	}
 	command.PersistentFlags().StringVar(&alias, "alias", "", "alias for the repository")
+	command.PersistentFlags().BoolVar(&purge, "purge", false, "also uninstall the virtual machines installed from the repository")
 	err := command.MarkPersistentFlagRequired("alias")
 	if err != nil {
 		// TODO cleanup these panics
@@ -28,7 +30,7 @@ func removeRepository(fs afero.Fs) *cobra.Command {
 			return err
 		}
 
-		return opm.RemoveRepository(alias)
+		return opm.RemoveRepository(alias, purge)
 	}
 
 	return command
@@ -17,6 +17,7 @@ import (
 
 	"github.com/DioneProtocol/opm/config"
 	"github.com/DioneProtocol/opm/constant"
+	"github.com/DioneProtocol/opm/git"
 	"github.com/DioneProtocol/opm/opm"
 )
 
@@ -32,6 +33,14 @@ const (
 	pluginPathKey       = "plugin-path"
 	credentialsFileKey  = "credentials-file"
 	adminAPIEndpointKey = "admin-api-endpoint"
+	nodeConfigDirKey    = "node-config-dir"
+	nodeConfigFileKey   = "node-config-file"
+	profileKey          = "profile"
+	gitDepthKey         = "git-depth"
+
+	// defaultConfigFile is the config file read from the opm directory if no
+	// config file is given.
+	defaultConfigFile = "config.yaml"
 )
 
 func New(fs afero.Fs) (*cobra.Command, error) {
@@ -42,7 +51,11 @@ func New(fs afero.Fs) (*cobra.Command, error) {
 			// we need to initialize our config here before each command starts,
 			// since Cobra doesn't actually parse any of the flags until
 			// cobra.Execute() is called.
-			return initializeConfig()
+			if err := initializeConfig(fs); err != nil {
+				return err
+			}
+
+			return applyProfile(fs, cmd)
 		},
 	}
 
@@ -51,6 +64,10 @@ func New(fs afero.Fs) (*cobra.Command, error) {
 	rootCmd.PersistentFlags().String(pluginPathKey, filepath.Join(goPath, "src", "github.com", "DioneProtocol", "odysseygo", "build", "plugins"), "path to odyssey plugin directory")
 	rootCmd.PersistentFlags().String(credentialsFileKey, "", "path to credentials file")
 	rootCmd.PersistentFlags().String(adminAPIEndpointKey, "127.0.0.1:9650/ext/admin", "endpoint for the odyssey admin api")
+	rootCmd.PersistentFlags().String(nodeConfigDirKey, filepath.Join(homeDir, ".odysseygo", "configs"), "path to the odyssey node's config directory")
+	rootCmd.PersistentFlags().String(nodeConfigFileKey, filepath.Join(homeDir, ".odysseygo", "configs", "node.json"), "path to the odyssey node's config file")
+	rootCmd.PersistentFlags().String(profileKey, "", "name of the node profile to use")
+	rootCmd.PersistentFlags().Int(gitDepthKey, 0, "number of commits to fetch when syncing repositories (0 fetches the full history)")
 
 	errs := wrappers.Errs{}
 	errs.Add(
@@ -59,6 +76,10 @@ func New(fs afero.Fs) (*cobra.Command, error) {
 		viper.BindPFlag(pluginPathKey, rootCmd.PersistentFlags().Lookup(pluginPathKey)),
 		viper.BindPFlag(credentialsFileKey, rootCmd.PersistentFlags().Lookup(credentialsFileKey)),
 		viper.BindPFlag(adminAPIEndpointKey, rootCmd.PersistentFlags().Lookup(adminAPIEndpointKey)),
+		viper.BindPFlag(nodeConfigDirKey, rootCmd.PersistentFlags().Lookup(nodeConfigDirKey)),
+		viper.BindPFlag(nodeConfigFileKey, rootCmd.PersistentFlags().Lookup(nodeConfigFileKey)),
+		viper.BindPFlag(profileKey, rootCmd.PersistentFlags().Lookup(profileKey)),
+		viper.BindPFlag(gitDepthKey, rootCmd.PersistentFlags().Lookup(gitDepthKey)),
 	)
 	if errs.Errored() {
 		return nil, errs.Err
@@ -70,47 +91,96 @@ func New(fs afero.Fs) (*cobra.Command, error) {
 		update(fs),
 		upgrade(fs),
 		listRepositories(fs),
+		listInstalled(fs),
+		outdated(fs),
+		search(fs),
+		info(fs),
 		joinSubnet(fs),
+		leaveSubnet(fs),
 		addRepository(fs),
 		removeRepository(fs),
+		reindex(fs),
+		profile(fs),
+		apply(fs),
+		freeze(fs),
+		state(fs),
+		dev(fs),
 	)
 
 	return rootCmd, nil
 }
 
 // initializes config from file, if available.
-func initializeConfig() error {
+func initializeConfig(fs afero.Fs) error {
+	cfgFile := configFilePath()
+
+	if !viper.IsSet(configFileKey) {
+		// The default config file is optional.
+		if ok, err := afero.Exists(fs, cfgFile); err != nil || !ok {
+			return err
+		}
+	}
+
+	viper.SetConfigFile(cfgFile)
+	return viper.ReadInConfig()
+}
+
+// configFilePath returns the path of the config file in use.
+func configFilePath() string {
 	if viper.IsSet(configFileKey) {
-		cfgFile := os.ExpandEnv(viper.GetString(configFileKey))
-		viper.SetConfigFile(cfgFile)
+		return os.ExpandEnv(viper.GetString(configFileKey))
+	}
+
+	return filepath.Join(viper.GetString(opmPathKey), defaultConfigFile)
+}
+
+// applyProfile overrides settings with the ones from the selected profile.
+// Flags given on the command line still take precedence.
+func applyProfile(fs afero.Fs, cmd *cobra.Command) error {
+	name := viper.GetString(profileKey)
+	if name == "" {
+		return nil
+	}
+
+	profiles, err := config.ReadProfiles(fs, configFilePath())
+	if err != nil {
+		return err
+	}
 
-		return viper.ReadInConfig()
+	profile, ok := profiles[name]
+	if !ok {
+		return fmt.Errorf("profile %s doesn't exist in %s", name, configFilePath())
+	}
+
+	for key, value := range profileSettings(&profile) {
+		if *value != "" && !cmd.Flags().Changed(key) {
+			viper.Set(key, *value)
+		}
 	}
 
 	return nil
 }
 
-// If we need to use custom git credentials (say for private repos).
+// If we need to use custom git credentials (say for private repos) or
+// credentials for the node's APIs.
 // the zero value for credentials is safe to use.
-func initCredentials() (http.BasicAuth, error) {
-	result := http.BasicAuth{}
+func initCredentials() (config.Credential, error) {
+	credentials := config.Credential{}
 
 	if viper.IsSet(credentialsFileKey) {
-		credentials := &config.Credential{}
-
 		bytes, err := os.ReadFile(viper.GetString(credentialsFileKey))
 		if err != nil {
-			return result, err
+			return credentials, err
 		}
-		if err := yaml.Unmarshal(bytes, credentials); err != nil {
-			return result, err
+		if err := yaml.Unmarshal(bytes, &credentials); err != nil {
+			return credentials, err
+		}
+		if err := credentials.Verify(); err != nil {
+			return credentials, err
 		}
-
-		result.Username = credentials.Username
-		result.Password = credentials.Password
 	}
 
-	return result, nil
+	return credentials, nil
 }
 
 func initOPM(fs afero.Fs) (*opm.OPM, error) {
@@ -120,10 +190,22 @@ func initOPM(fs afero.Fs) (*opm.OPM, error) {
 	}
 
 	return opm.New(opm.Config{
-		Directory:        viper.GetString(opmPathKey),
-		Auth:             credentials,
+		Directory: viper.GetString(opmPathKey),
+		GitCredentials: git.Credentials{
+			Entries: credentials.Git,
+			Default: &http.BasicAuth{
+				Username: credentials.Username,
+				Password: credentials.Password,
+			},
+			Fs: fs,
+		},
+		NodeAuth:         credentials.Node,
 		AdminAPIEndpoint: viper.GetString(adminAPIEndpointKey),
 		PluginDir:        viper.GetString(pluginPathKey),
+		NodeConfigDir:    viper.GetString(nodeConfigDirKey),
+		NodeConfigFile:   viper.GetString(nodeConfigFileKey),
+		Profile:          viper.GetString(profileKey),
+		GitDepth:         viper.GetInt(gitDepthKey),
 		Fs:               fs,
 	})
 }
@@ -0,0 +1,35 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"strings"
+
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func search(fs afero.Fs) *cobra.Command {
+	typ := ""
+	repository := ""
+
+	command := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Searches the virtual machine and subnet definitions of all tracked repositories.",
+		Args:  cobra.MinimumNArgs(1),
+	}
+	command.PersistentFlags().StringVar(&typ, "type", "", "only show definitions of this type (vm|subnet)")
+	command.PersistentFlags().StringVar(&repository, "repo", "", "only show definitions from this repository (organization/repository)")
+
+	command.RunE = func(_ *cobra.Command, args []string) error {
+		opm, err := initOPM(fs)
+		if err != nil {
+			return err
+		}
+
+		return opm.Search(strings.Join(args, " "), typ, repository)
+	}
+
+	return command
+}
@@ -0,0 +1,71 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package cmd
+
+import (
+	"github.com/spf13/afero"
+	"github.com/spf13/cobra"
+)
+
+func state(fs afero.Fs) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "state",
+		Short: "Backs up and restores the opm's state.",
+	}
+
+	command.AddCommand(
+		exportState(fs),
+		importState(fs),
+	)
+
+	return command
+}
+
+func exportState(fs afero.Fs) *cobra.Command {
+	output := ""
+	command := &cobra.Command{
+		Use:   "export",
+		Short: "Writes the tracked repositories, installed virtual machines and joined subnets to an archive.",
+		Args:  cobra.NoArgs,
+	}
+	command.PersistentFlags().StringVarP(&output, "output", "o", "opm-state.yaml", "path to write the archive to")
+
+	command.RunE = func(_ *cobra.Command, _ []string) error {
+		opm, err := initOPM(fs)
+		if err != nil {
+			return err
+		}
+
+		return opm.ExportState(output)
+	}
+
+	return command
+}
+
+func importState(fs afero.Fs) *cobra.Command {
+	file := ""
+	force := false
+	command := &cobra.Command{
+		Use:   "import",
+		Short: "Restores the tracked repositories, installed virtual machines and joined subnets from an archive.",
+		Args:  cobra.NoArgs,
+	}
+	command.PersistentFlags().StringVarP(&file, "file", "f", "", "path to the archive")
+	command.PersistentFlags().BoolVar(&force, "force", false, "install even if a virtual machine is incompatible with the node")
+	err := command.MarkPersistentFlagRequired("file")
+	if err != nil {
+		panic(err)
+	}
+
+	command.RunE = func(_ *cobra.Command, _ []string) error {
+		opm, err := initOPM(fs)
+		if err != nil {
+			return err
+		}
+
+		return opm.ImportState(file, force)
+	}
+
+	return command
+}
@@ -14,7 +14,7 @@ func uninstall(fs afero.Fs) *cobra.Command {
 		Use:   "uninstall-vm",
 		Short: "Uninstalls a virtual machine by its alias",
 	}
-	command.PersistentFlags().StringVar(&vm, "vm", "", "vm alias to install")
+	command.PersistentFlags().StringVar(&vm, "vm", "", "reference to the vm to uninstall")
 	err := command.MarkPersistentFlagRequired("vm")
 	if err != nil {
 		panic(err)
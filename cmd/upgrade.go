@@ -11,19 +11,21 @@ import (
 func upgrade(fs afero.Fs) *cobra.Command {
 	// this flag is optional
 	vm := ""
+	force := false
 	command := &cobra.Command{
 		Use: "upgrade",
 		Short: "Upgrades a virtual machine. If none is specified, all " +
 			"installed virtual machines are upgraded.",
 	}
-	command.PersistentFlags().StringVar(&vm, "vm", "", "vm alias to install")
+	command.PersistentFlags().StringVar(&vm, "vm", "", "reference to the vm to upgrade (e.g. spacesvm@^v1.2.0)")
+	command.PersistentFlags().BoolVar(&force, "force", false, "install even if a virtual machine is incompatible with the node")
 	command.RunE = func(_ *cobra.Command, _ []string) error {
 		opm, err := initOPM(fs)
 		if err != nil {
 			return err
 		}
 
-		return opm.Upgrade(vm)
+		return opm.Upgrade(vm, force)
 	}
 
 	return command
@@ -3,7 +3,185 @@
 
 package config
 
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/DioneProtocol/opm/util"
+)
+
+// GitAuthType is how a git credential authenticates.
+type GitAuthType string
+
+const (
+	// BasicAuth authenticates over http with a username and a password.
+	BasicAuth GitAuthType = "basic"
+	// TokenAuth authenticates over http with an access token.
+	TokenAuth GitAuthType = "token"
+	// SSHKeyAuth authenticates over ssh with a private key.
+	SSHKeyAuth GitAuthType = "ssh"
+	// SSHAgentAuth authenticates over ssh with the keys in the ssh-agent.
+	SSHAgentAuth GitAuthType = "ssh-agent"
+)
+
+var (
+	errInvalidGitCredential = errors.New("invalid git credential")
+	errMissingSecret        = errors.New("secret isn't set")
+)
+
 type Credential struct {
+	// Username and Password are used for git repositories over http that no
+	// entry in [Git] matches.
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// Git are the credentials for specific hosts or repositories.
+	Git []GitCredential `yaml:"git"`
+	// Node is used to connect to the node's APIs.
+	Node NodeCredential `yaml:"node"`
+}
+
+// Verify returns an error if any of the git credentials is invalid.
+func (c Credential) Verify() error {
+	for _, gitCredential := range c.Git {
+		if err := gitCredential.Verify(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// GitCredential authenticates to the git repositories on a host, or to a
+// single repository.
+type GitCredential struct {
+	// Host matches repositories whose url is on this host (e.g. github.com).
+	Host string `yaml:"host"`
+	// Repository matches the repository with this alias. It takes precedence
+	// over the entries matching its host.
+	Repository string `yaml:"repository"`
+
+	Type GitAuthType `yaml:"type"`
+	// Username is the user to authenticate as. It defaults to the user in the
+	// repository's url for ssh.
+	Username string `yaml:"username"`
+	// Password is used by BasicAuth.
+	Password Secret `yaml:"password"`
+	// Token is used by TokenAuth.
+	Token Secret `yaml:"token"`
+	// Key is the path to the private key used by SSHKeyAuth, and Passphrase
+	// decrypts it if it's encrypted.
+	Key        string `yaml:"key"`
+	Passphrase Secret `yaml:"passphrase"`
+}
+
+// Verify returns an error if the credential is missing what its type needs.
+func (c GitCredential) Verify() error {
+	switch {
+	case c.Host == "" && c.Repository == "":
+		return fmt.Errorf("%w: either a host or a repository is required", errInvalidGitCredential)
+	case c.Host != "" && c.Repository != "":
+		return fmt.Errorf("%w: %s can't set both a host and a repository", errInvalidGitCredential, c)
+	case c.Repository != "" && !util.ValidAlias(c.Repository):
+		return fmt.Errorf("%w: repository %q isn't in the form of organization/repository", errInvalidGitCredential, c.Repository)
+	}
+
+	switch c.Type {
+	case BasicAuth:
+		if c.Username == "" || !c.Password.IsSet() {
+			return fmt.Errorf("%w: %s needs a username and a password", errInvalidGitCredential, c)
+		}
+	case TokenAuth:
+		if !c.Token.IsSet() {
+			return fmt.Errorf("%w: %s needs a token", errInvalidGitCredential, c)
+		}
+	case SSHKeyAuth:
+		if c.Key == "" {
+			return fmt.Errorf("%w: %s needs a key", errInvalidGitCredential, c)
+		}
+	case SSHAgentAuth:
+	default:
+		return fmt.Errorf("%w: %s has unknown type %q", errInvalidGitCredential, c, c.Type)
+	}
+
+	return nil
+}
+
+func (c GitCredential) String() string {
+	if c.Repository != "" {
+		return "repository " + c.Repository
+	}
+
+	return "host " + c.Host
+}
+
+// Secret is a value read from an environment variable or a file, so it doesn't
+// have to be stored in the credentials file. A plain string is used as-is.
+type Secret struct {
+	Value string `yaml:"value"`
+	// Env is the environment variable the secret is read from.
+	Env string `yaml:"env"`
+	// File is the path the secret is read from. Trailing whitespace is
+	// removed.
+	File string `yaml:"file"`
+}
+
+func (s *Secret) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		return node.Decode(&s.Value)
+	}
+
+	// The alias keeps Decode from calling UnmarshalYAML again.
+	type secret Secret
+	return node.Decode((*secret)(s))
+}
+
+// IsSet returns true if the secret is given in any way.
+func (s Secret) IsSet() bool {
+	return s.Value != "" || s.Env != "" || s.File != ""
+}
+
+// Resolve returns the value of the secret. An empty secret resolves to an
+// empty string.
+func (s Secret) Resolve(fs afero.Fs) (string, error) {
+	switch {
+	case s.Env != "":
+		value, ok := os.LookupEnv(s.Env)
+		if !ok {
+			return "", fmt.Errorf("%w: environment variable %s", errMissingSecret, s.Env)
+		}
+		return value, nil
+	case s.File != "":
+		contents, err := afero.ReadFile(fs, s.File)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimRight(string(contents), " \t\r\n"), nil
+	default:
+		return s.Value, nil
+	}
+}
+
+// NodeCredential holds the authentication and TLS settings used to connect to
+// a node's APIs (e.g. through a TLS-terminating proxy).
+type NodeCredential struct {
+	// Token is sent as a bearer token.
+	Token string `yaml:"token"`
+	// Username and Password are sent with basic auth.
 	Username string `yaml:"username"`
 	Password string `yaml:"password"`
+	// Headers are sent with every request.
+	Headers map[string]string `yaml:"headers"`
+
+	// CACert is the path to a PEM-encoded CA bundle used to verify the node's
+	// certificate instead of the system roots.
+	CACert string `yaml:"caCert"`
+	// ClientCert and ClientKey are paths to a PEM-encoded client certificate
+	// and key presented to the node.
+	ClientCert string `yaml:"clientCert"`
+	ClientKey  string `yaml:"clientKey"`
 }
@@ -0,0 +1,139 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestCredentialUnmarshal(t *testing.T) {
+	contents := `
+git:
+  - repository: organization/repository
+    type: token
+    token:
+      env: OPM_TOKEN
+  - host: github.com
+    type: basic
+    username: user
+    password: plaintext
+  - host: gitlab.com
+    type: ssh
+    key: /home/user/.ssh/id_ed25519
+    passphrase:
+      file: /run/secrets/passphrase
+`
+	credential := Credential{}
+	assert.NoError(t, yaml.Unmarshal([]byte(contents), &credential))
+	assert.NoError(t, credential.Verify())
+	assert.Equal(t, []GitCredential{
+		{Repository: "organization/repository", Type: TokenAuth, Token: Secret{Env: "OPM_TOKEN"}},
+		{Host: "github.com", Type: BasicAuth, Username: "user", Password: Secret{Value: "plaintext"}},
+		{Host: "gitlab.com", Type: SSHKeyAuth, Key: "/home/user/.ssh/id_ed25519", Passphrase: Secret{File: "/run/secrets/passphrase"}},
+	}, credential.Git)
+}
+
+func TestGitCredentialVerify(t *testing.T) {
+	tests := []struct {
+		name       string
+		credential GitCredential
+		wantErr    error
+	}{
+		{
+			name:       "ssh-agent",
+			credential: GitCredential{Host: "github.com", Type: SSHAgentAuth},
+		},
+		{
+			name:       "no host or repository",
+			credential: GitCredential{Type: SSHAgentAuth},
+			wantErr:    errInvalidGitCredential,
+		},
+		{
+			name:       "host and repository",
+			credential: GitCredential{Host: "github.com", Repository: "organization/repository", Type: SSHAgentAuth},
+			wantErr:    errInvalidGitCredential,
+		},
+		{
+			name:       "invalid repository",
+			credential: GitCredential{Repository: "repository", Type: SSHAgentAuth},
+			wantErr:    errInvalidGitCredential,
+		},
+		{
+			name:       "basic without password",
+			credential: GitCredential{Host: "github.com", Type: BasicAuth, Username: "user"},
+			wantErr:    errInvalidGitCredential,
+		},
+		{
+			name:       "token without token",
+			credential: GitCredential{Host: "github.com", Type: TokenAuth},
+			wantErr:    errInvalidGitCredential,
+		},
+		{
+			name:       "ssh without key",
+			credential: GitCredential{Host: "github.com", Type: SSHKeyAuth},
+			wantErr:    errInvalidGitCredential,
+		},
+		{
+			name:       "unknown type",
+			credential: GitCredential{Host: "github.com", Type: "kerberos"},
+			wantErr:    errInvalidGitCredential,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.ErrorIs(t, test.credential.Verify(), test.wantErr)
+		})
+	}
+}
+
+func TestSecretResolve(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	assert.NoError(t, afero.WriteFile(fs, "/secret", []byte("from-file\n"), perms.ReadWrite))
+	t.Setenv("OPM_TEST_SECRET", "from-env")
+
+	tests := []struct {
+		name    string
+		secret  Secret
+		want    string
+		wantErr error
+	}{
+		{
+			name:   "value",
+			secret: Secret{Value: "plaintext"},
+			want:   "plaintext",
+		},
+		{
+			name:   "env",
+			secret: Secret{Env: "OPM_TEST_SECRET"},
+			want:   "from-env",
+		},
+		{
+			name:    "missing env",
+			secret:  Secret{Env: "OPM_TEST_MISSING_SECRET"},
+			wantErr: errMissingSecret,
+		},
+		{
+			name:   "file",
+			secret: Secret{File: "/secret"},
+			want:   "from-file",
+		},
+		{
+			name: "empty",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, err := test.secret.Resolve(fs)
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+)
+
+// LockfileVersion is the version of the lockfile format written by the opm.
+const LockfileVersion = 1
+
+var (
+	errUnsupportedLockfile = errors.New("unsupported lockfile version")
+	errInvalidLockedVM     = errors.New("invalid locked vm")
+	errUnlockedRepository  = errors.New("repository isn't locked")
+)
+
+// Lockfile records the exact VMs installed by the opm, so that the same set
+// can be installed elsewhere.
+type Lockfile struct {
+	Version      int                `yaml:"version"`
+	Repositories []LockedRepository `yaml:"repositories"`
+	VMs          []LockedVM         `yaml:"vms"`
+}
+
+// LockedRepository is a repository locked VMs were installed from.
+type LockedRepository = Repository
+
+// LockedVM is an installed VM, along with where its definition and artifact
+// came from.
+type LockedVM struct {
+	// Name is the fully qualified name of the VM.
+	Name    string `yaml:"name"`
+	ID      string `yaml:"id"`
+	Version string `yaml:"version"`
+	// Commit is the repository commit the VM's definition was read from.
+	Commit string `yaml:"commit"`
+	URL    string `yaml:"url"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// ReadLockfile reads and verifies the lockfile at [path].
+func ReadLockfile(fs afero.Fs, path string) (Lockfile, error) {
+	contents, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return Lockfile{}, err
+	}
+
+	lockfile := Lockfile{}
+	if err := yaml.Unmarshal(contents, &lockfile); err != nil {
+		return Lockfile{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if err := lockfile.Verify(); err != nil {
+		return Lockfile{}, fmt.Errorf("invalid lockfile %s: %w", path, err)
+	}
+
+	return lockfile, nil
+}
+
+// WriteLockfile writes [lockfile] to [path].
+func WriteLockfile(fs afero.Fs, path string, lockfile Lockfile) error {
+	contents, err := yaml.Marshal(lockfile)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(path), perms.ReadWriteExecute); err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path, contents, perms.ReadWrite)
+}
+
+func (l Lockfile) Verify() error {
+	if l.Version != LockfileVersion {
+		return fmt.Errorf("%w %d (expected %d)", errUnsupportedLockfile, l.Version, LockfileVersion)
+	}
+
+	repositories := make(map[string]struct{}, len(l.Repositories))
+	for _, repository := range l.Repositories {
+		if err := repository.Verify(); err != nil {
+			return err
+		}
+		if _, ok := repositories[repository.Alias]; ok {
+			return fmt.Errorf("repository %s %w", repository.Alias, errDuplicateEntry)
+		}
+		repositories[repository.Alias] = struct{}{}
+	}
+
+	vms := make(map[string]struct{}, len(l.VMs))
+	for _, vm := range l.VMs {
+		if err := verifyManifestName(vm.Name, repositories); errors.Is(err, errUndeclaredRepository) {
+			return fmt.Errorf("%w: %s", errUnlockedRepository, vm.Name)
+		} else if err != nil {
+			return err
+		}
+		if _, ok := vms[vm.Name]; ok {
+			return fmt.Errorf("vm %s %w", vm.Name, errDuplicateEntry)
+		}
+		vms[vm.Name] = struct{}{}
+
+		if vm.ID == "" || vm.URL == "" || vm.SHA256 == "" {
+			return fmt.Errorf("%w %s: id, url and sha256 are required", errInvalidLockedVM, vm.Name)
+		}
+		if _, err := version.Parse(vm.Version); err != nil {
+			return fmt.Errorf("%w %s: %s", errInvalidLockedVM, vm.Name, err)
+		}
+		if !plumbing.IsHash(vm.Commit) {
+			return fmt.Errorf("%w %s: invalid commit %q", errInvalidLockedVM, vm.Name, vm.Commit)
+		}
+	}
+
+	return nil
+}
+
+// Repository returns the locked repository with [alias].
+func (l Lockfile) Repository(alias string) (LockedRepository, bool) {
+	for _, repository := range l.Repositories {
+		if repository.Alias == alias {
+			return repository, true
+		}
+	}
+
+	return LockedRepository{}, false
+}
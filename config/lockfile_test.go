@@ -0,0 +1,128 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLockfileRoundTrip(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	lockfile := Lockfile{
+		Version: LockfileVersion,
+		Repositories: []LockedRepository{
+			{Alias: "foo/bar", URL: "https://github.com/foo/bar.git", Branch: "main"},
+		},
+		VMs: []LockedVM{
+			{
+				Name:    "foo/bar:foovm",
+				ID:      "id",
+				Version: "v1.2.3",
+				Commit:  "0123456789abcdef0123456789abcdef01234567",
+				URL:     "https://foo.com/foovm.tar.gz",
+				SHA256:  "abcd",
+			},
+		},
+	}
+
+	assert.NoError(t, WriteLockfile(fs, "locks/opm.lock", lockfile))
+
+	got, err := ReadLockfile(fs, "locks/opm.lock")
+	assert.NoError(t, err)
+	assert.Equal(t, lockfile, got)
+
+	repository, ok := got.Repository("foo/bar")
+	assert.True(t, ok)
+	assert.Equal(t, lockfile.Repositories[0], repository)
+
+	_, ok = got.Repository("foo/baz")
+	assert.False(t, ok)
+}
+
+func TestLockfileVerify(t *testing.T) {
+	repository := LockedRepository{Alias: "foo/bar", URL: "https://github.com/foo/bar.git", Branch: "main"}
+	vm := LockedVM{
+		Name:    "foo/bar:foovm",
+		ID:      "id",
+		Version: "v1.2.3",
+		Commit:  "0123456789abcdef0123456789abcdef01234567",
+		URL:     "https://foo.com/foovm.tar.gz",
+		SHA256:  "abcd",
+	}
+
+	tests := []struct {
+		name     string
+		lockfile func() Lockfile
+		wantErr  error
+	}{
+		{
+			name: "valid",
+			lockfile: func() Lockfile {
+				return Lockfile{Version: LockfileVersion, Repositories: []LockedRepository{repository}, VMs: []LockedVM{vm}}
+			},
+		},
+		{
+			name: "unsupported version",
+			lockfile: func() Lockfile {
+				return Lockfile{Version: LockfileVersion + 1}
+			},
+			wantErr: errUnsupportedLockfile,
+		},
+		{
+			name: "unlocked repository",
+			lockfile: func() Lockfile {
+				return Lockfile{Version: LockfileVersion, VMs: []LockedVM{vm}}
+			},
+			wantErr: errUnlockedRepository,
+		},
+		{
+			name: "missing sha256",
+			lockfile: func() Lockfile {
+				invalid := vm
+				invalid.SHA256 = ""
+				return Lockfile{Version: LockfileVersion, Repositories: []LockedRepository{repository}, VMs: []LockedVM{invalid}}
+			},
+			wantErr: errInvalidLockedVM,
+		},
+		{
+			name: "invalid version",
+			lockfile: func() Lockfile {
+				invalid := vm
+				invalid.Version = "1.2.3"
+				return Lockfile{Version: LockfileVersion, Repositories: []LockedRepository{repository}, VMs: []LockedVM{invalid}}
+			},
+			wantErr: errInvalidLockedVM,
+		},
+		{
+			name: "invalid commit",
+			lockfile: func() Lockfile {
+				invalid := vm
+				invalid.Commit = "main"
+				return Lockfile{Version: LockfileVersion, Repositories: []LockedRepository{repository}, VMs: []LockedVM{invalid}}
+			},
+			wantErr: errInvalidLockedVM,
+		},
+		{
+			name: "duplicate vm",
+			lockfile: func() Lockfile {
+				return Lockfile{Version: LockfileVersion, Repositories: []LockedRepository{repository}, VMs: []LockedVM{vm, vm}}
+			},
+			wantErr: errDuplicateEntry,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.lockfile().Verify()
+			if test.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, test.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/DioneProtocol/opm/constant"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+var (
+	errInvalidAlias         = errors.New("repository aliases must be in the form of organization/repository")
+	errInvalidQualifiedName = errors.New("names must be fully qualified (e.g. organization/repository:name)")
+	errMissingURL           = errors.New("repository url is required")
+	errDuplicateEntry       = errors.New("declared more than once")
+	errUndeclaredRepository = errors.New("repository isn't declared in the manifest")
+)
+
+// Manifest is the desired state of the opm: the repositories to track, the
+// VMs to install and the subnets to join. Anything not declared is removed
+// when the manifest is applied.
+type Manifest struct {
+	Repositories []ManifestRepository `yaml:"repositories"`
+	VMs          []ManifestVM         `yaml:"vms"`
+	Subnets      []string             `yaml:"subnets"`
+}
+
+// ManifestRepository is a repository to track.
+type ManifestRepository = Repository
+
+// ManifestVM is a VM to install, by its fully qualified name.
+type ManifestVM struct {
+	Name    string                  `yaml:"name"`
+	Version types.VersionConstraint `yaml:"version,omitempty"`
+}
+
+// ReadManifest reads and verifies the manifest at [path]. Both YAML and JSON
+// manifests are supported.
+func ReadManifest(fs afero.Fs, path string) (Manifest, error) {
+	contents, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return Manifest{}, err
+	}
+
+	manifest := Manifest{}
+	decoder := yaml.NewDecoder(bytes.NewReader(contents))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&manifest); err != nil && !errors.Is(err, io.EOF) {
+		return Manifest{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	for i, repository := range manifest.Repositories {
+		repository = repository.withDefaults()
+		// Local sources are relative to the manifest.
+		if repository.Source == storage.LocalSource && !filepath.IsAbs(repository.URL) {
+			dir, err := filepath.Abs(filepath.Dir(path))
+			if err != nil {
+				return Manifest{}, err
+			}
+			repository.URL = filepath.Join(dir, repository.URL)
+		}
+		manifest.Repositories[i] = repository
+	}
+
+	if err := manifest.Verify(); err != nil {
+		return Manifest{}, fmt.Errorf("invalid manifest %s: %w", path, err)
+	}
+
+	return manifest, nil
+}
+
+func (m Manifest) Verify() error {
+	repositories := map[string]struct{}{constant.CoreAlias: {}}
+	declared := make(map[string]struct{}, len(m.Repositories))
+	for _, repository := range m.Repositories {
+		if err := repository.Verify(); err != nil {
+			return err
+		}
+		if _, ok := declared[repository.Alias]; ok {
+			return fmt.Errorf("repository %s %w", repository.Alias, errDuplicateEntry)
+		}
+
+		declared[repository.Alias] = struct{}{}
+		repositories[repository.Alias] = struct{}{}
+	}
+
+	vms := make(map[string]struct{}, len(m.VMs))
+	for _, vm := range m.VMs {
+		if err := verifyManifestName(vm.Name, repositories); err != nil {
+			return err
+		}
+		if _, ok := vms[vm.Name]; ok {
+			return fmt.Errorf("vm %s %w", vm.Name, errDuplicateEntry)
+		}
+		vms[vm.Name] = struct{}{}
+	}
+
+	subnets := make(map[string]struct{}, len(m.Subnets))
+	for _, subnet := range m.Subnets {
+		if err := verifyManifestName(subnet, repositories); err != nil {
+			return err
+		}
+		if _, ok := subnets[subnet]; ok {
+			return fmt.Errorf("subnet %s %w", subnet, errDuplicateEntry)
+		}
+		subnets[subnet] = struct{}{}
+	}
+
+	return nil
+}
+
+// verifyManifestName checks that [name] is fully qualified and belongs to one
+// of [repositories].
+func verifyManifestName(name string, repositories map[string]struct{}) error {
+	reference, err := types.ParseReference(name)
+	if err != nil || !reference.Qualified() || reference.QualifiedName() != name {
+		return fmt.Errorf("%w: %q", errInvalidQualifiedName, name)
+	}
+
+	if _, ok := repositories[reference.Repository]; !ok {
+		return fmt.Errorf("%w: %s (needed by %s)", errUndeclaredRepository, reference.Repository, name)
+	}
+
+	return nil
+}
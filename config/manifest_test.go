@@ -0,0 +1,169 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/constant"
+	"github.com/DioneProtocol/opm/storage"
+)
+
+func TestReadManifest(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	assert.NoError(t, afero.WriteFile(fs, "opm.yaml", []byte(`
+repositories:
+  - alias: foo/bar
+    url: https://github.com/foo/bar.git
+    branch: main
+  - alias: foo/baz
+    url: https://github.com/foo/baz.git
+  - alias: foo/tag
+    url: https://github.com/foo/tag.git
+    referenceType: tag
+    reference: v1.0.0
+  - alias: foo/index
+    url: https://example.com/index.yaml
+    source: http
+    publicKey: abcd
+  - alias: foo/local
+    url: plugins
+    source: local
+vms:
+  - name: foo/bar:foovm
+    version: ">=v1.0.0, <v2.0.0"
+  - name: DioneProtocol/odyssey-plugins-core:spacesvm
+subnets:
+  - foo/baz:foosubnet
+`), perms.ReadWrite))
+
+	manifest, err := ReadManifest(fs, "opm.yaml")
+	assert.NoError(t, err)
+
+	dir, err := filepath.Abs("plugins")
+	assert.NoError(t, err)
+	assert.Equal(t, []ManifestRepository{
+		{Alias: "foo/bar", URL: "https://github.com/foo/bar.git", Source: storage.GitSource, ReferenceType: storage.BranchReference, Branch: "main"},
+		{Alias: "foo/baz", URL: "https://github.com/foo/baz.git", Source: storage.GitSource, ReferenceType: storage.BranchReference, Branch: constant.CoreBranch},
+		{Alias: "foo/tag", URL: "https://github.com/foo/tag.git", Source: storage.GitSource, ReferenceType: storage.TagReference, Reference: "v1.0.0"},
+		{Alias: "foo/index", URL: "https://example.com/index.yaml", Source: storage.HTTPSource, PublicKey: "abcd"},
+		{Alias: "foo/local", URL: dir, Source: storage.LocalSource},
+	}, manifest.Repositories)
+	assert.Len(t, manifest.VMs, 2)
+	assert.Equal(t, "foo/bar:foovm", manifest.VMs[0].Name)
+	assert.True(t, manifest.VMs[0].Version.Check(version.Semantic{Major: 1, Minor: 5}))
+	assert.False(t, manifest.VMs[0].Version.Check(version.Semantic{Major: 2}))
+	assert.Equal(t, "*", manifest.VMs[1].Version.String())
+	assert.Equal(t, []string{"foo/baz:foosubnet"}, manifest.Subnets)
+}
+
+func TestReadManifestJSON(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	assert.NoError(t, afero.WriteFile(fs, "opm.json", []byte(`{"vms": [{"name": "DioneProtocol/odyssey-plugins-core:spacesvm", "version": "^1.0.0"}]}`), perms.ReadWrite))
+
+	manifest, err := ReadManifest(fs, "opm.json")
+	assert.NoError(t, err)
+	assert.Equal(t, "^1.0.0", manifest.VMs[0].Version.String())
+}
+
+func TestReadManifestInvalid(t *testing.T) {
+	tests := []struct {
+		name     string
+		manifest string
+		wantErr  error
+	}{
+		{
+			name:     "invalid alias",
+			manifest: "repositories:\n  - alias: foo\n    url: https://github.com/foo/bar.git\n",
+			wantErr:  errInvalidAlias,
+		},
+		{
+			name:     "missing url",
+			manifest: "repositories:\n  - alias: foo/bar\n",
+			wantErr:  errMissingURL,
+		},
+		{
+			name:     "unknown source",
+			manifest: "repositories:\n  - alias: foo/bar\n    url: a\n    source: ftp\n",
+			wantErr:  errInvalidSource,
+		},
+		{
+			name:     "missing public key",
+			manifest: "repositories:\n  - alias: foo/bar\n    url: a\n    source: http\n",
+			wantErr:  errInvalidSource,
+		},
+		{
+			name:     "unknown reference type",
+			manifest: "repositories:\n  - alias: foo/bar\n    url: a\n    referenceType: release\n    reference: v1.0.0\n",
+			wantErr:  errInvalidReference,
+		},
+		{
+			name:     "missing reference",
+			manifest: "repositories:\n  - alias: foo/bar\n    url: a\n    referenceType: tag\n",
+			wantErr:  errInvalidReference,
+		},
+		{
+			name:     "branch with a tag",
+			manifest: "repositories:\n  - alias: foo/bar\n    url: a\n    branch: main\n    referenceType: tag\n    reference: v1.0.0\n",
+			wantErr:  errInvalidReference,
+		},
+		{
+			name:     "reference for a local source",
+			manifest: "repositories:\n  - alias: foo/bar\n    url: a\n    source: local\n    branch: main\n",
+			wantErr:  errInvalidReference,
+		},
+		{
+			name:     "duplicate repository",
+			manifest: "repositories:\n  - alias: foo/bar\n    url: a\n  - alias: foo/bar\n    url: b\n",
+			wantErr:  errDuplicateEntry,
+		},
+		{
+			name:     "unqualified vm",
+			manifest: "vms:\n  - name: spacesvm\n",
+			wantErr:  errInvalidQualifiedName,
+		},
+		{
+			name:     "duplicate vm",
+			manifest: "vms:\n  - name: DioneProtocol/odyssey-plugins-core:spacesvm\n  - name: DioneProtocol/odyssey-plugins-core:spacesvm\n",
+			wantErr:  errDuplicateEntry,
+		},
+		{
+			name:     "undeclared repository",
+			manifest: "subnets:\n  - foo/bar:foosubnet\n",
+			wantErr:  errUndeclaredRepository,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			assert.NoError(t, afero.WriteFile(fs, "opm.yaml", []byte(test.manifest), perms.ReadWrite))
+
+			_, err := ReadManifest(fs, "opm.yaml")
+			assert.ErrorIs(t, err, test.wantErr)
+		})
+	}
+
+	t.Run("unknown field", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		assert.NoError(t, afero.WriteFile(fs, "opm.yaml", []byte("vm:\n  - name: foo/bar:foovm\n"), perms.ReadWrite))
+
+		_, err := ReadManifest(fs, "opm.yaml")
+		assert.Error(t, err)
+	})
+
+	t.Run("invalid version constraint", func(t *testing.T) {
+		fs := afero.NewMemMapFs()
+		assert.NoError(t, afero.WriteFile(fs, "opm.yaml", []byte("vms:\n  - name: DioneProtocol/odyssey-plugins-core:spacesvm\n    version: latest\n"), perms.ReadWrite))
+
+		_, err := ReadManifest(fs, "opm.yaml")
+		assert.Error(t, err)
+	})
+}
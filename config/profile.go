@@ -0,0 +1,193 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// ProfilesKey is the config file key profiles are stored under.
+const ProfilesKey = "profiles"
+
+var (
+	profileNameRegex = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+	errNotYAML          = errors.New("profiles can only be edited in YAML config files")
+	errInvalidConfig    = errors.New("expected the config file to be a mapping")
+	errInvalidProfiles  = fmt.Errorf("expected %s to be a mapping", ProfilesKey)
+	errInvalidProfileID = errors.New("profile names may only contain letters, numbers, '-' and '_'")
+)
+
+// Profile holds the settings for a single node managed by the opm. Settings
+// that aren't set fall back to the global settings.
+type Profile struct {
+	PluginPath       string `yaml:"plugin-path,omitempty"`
+	AdminAPIEndpoint string `yaml:"admin-api-endpoint,omitempty"`
+	NodeConfigDir    string `yaml:"node-config-dir,omitempty"`
+	NodeConfigFile   string `yaml:"node-config-file,omitempty"`
+}
+
+// VerifyProfileName returns an error if [name] can't be used as a profile name.
+func VerifyProfileName(name string) error {
+	if !profileNameRegex.MatchString(name) {
+		return fmt.Errorf("%w: %q", errInvalidProfileID, name)
+	}
+
+	return nil
+}
+
+// ReadProfiles returns the profiles in the config file at [path]. A missing
+// config file has no profiles.
+func ReadProfiles(fs afero.Fs, path string) (map[string]Profile, error) {
+	contents, err := afero.ReadFile(fs, path)
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]Profile{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	config := struct {
+		Profiles map[string]Profile `yaml:"profiles"`
+	}{}
+	if err := yaml.Unmarshal(contents, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if config.Profiles == nil {
+		return map[string]Profile{}, nil
+	}
+
+	return config.Profiles, nil
+}
+
+// WriteProfile adds or replaces the profile [name] in the config file at
+// [path]. The rest of the config file is left as-is.
+func WriteProfile(fs afero.Fs, path string, name string, profile Profile) error {
+	if err := VerifyProfileName(name); err != nil {
+		return err
+	}
+
+	return editProfiles(fs, path, func(profiles *yaml.Node) (bool, error) {
+		value := &yaml.Node{}
+		if err := value.Encode(profile); err != nil {
+			return false, err
+		}
+
+		for i := 0; i+1 < len(profiles.Content); i += 2 {
+			if profiles.Content[i].Value == name {
+				profiles.Content[i+1] = value
+				return true, nil
+			}
+		}
+
+		key := &yaml.Node{}
+		key.SetString(name)
+		profiles.Content = append(profiles.Content, key, value)
+		return true, nil
+	})
+}
+
+// RemoveProfile removes the profile [name] from the config file at [path].
+// Returns false if there was no such profile.
+func RemoveProfile(fs afero.Fs, path string, name string) (bool, error) {
+	removed := false
+
+	err := editProfiles(fs, path, func(profiles *yaml.Node) (bool, error) {
+		for i := 0; i+1 < len(profiles.Content); i += 2 {
+			if profiles.Content[i].Value == name {
+				profiles.Content = append(profiles.Content[:i], profiles.Content[i+2:]...)
+				removed = true
+				return true, nil
+			}
+		}
+
+		return false, nil
+	})
+
+	return removed, err
+}
+
+// editProfiles applies [edit] to the profiles mapping of the config file at
+// [path], creating it if it doesn't exist. [edit] returns whether it changed
+// anything.
+func editProfiles(fs afero.Fs, path string, edit func(profiles *yaml.Node) (bool, error)) error {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+	default:
+		return fmt.Errorf("%w: %s", errNotYAML, path)
+	}
+
+	contents, err := afero.ReadFile(fs, path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	document := &yaml.Node{}
+	if err := yaml.Unmarshal(contents, document); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if document.Kind == 0 {
+		document = &yaml.Node{
+			Kind:    yaml.DocumentNode,
+			Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}},
+		}
+	}
+	if len(document.Content) != 1 || document.Content[0].Kind != yaml.MappingNode {
+		return errInvalidConfig
+	}
+	root := document.Content[0]
+
+	var profiles *yaml.Node
+	for i := 0; i+1 < len(root.Content); i += 2 {
+		if root.Content[i].Value == ProfilesKey {
+			profiles = root.Content[i+1]
+			break
+		}
+	}
+
+	if profiles == nil {
+		profiles = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		key := &yaml.Node{}
+		key.SetString(ProfilesKey)
+		root.Content = append(root.Content, key, profiles)
+	}
+	if profiles.Kind == yaml.ScalarNode && profiles.Tag == "!!null" {
+		*profiles = yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+	if profiles.Kind != yaml.MappingNode {
+		return errInvalidProfiles
+	}
+
+	changed, err := edit(profiles)
+	if err != nil || !changed {
+		return err
+	}
+
+	result := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(result)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return err
+	}
+	if err := encoder.Close(); err != nil {
+		return err
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(path), perms.ReadWriteExecute); err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path, result.Bytes(), perms.ReadWrite)
+}
@@ -0,0 +1,117 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		path     string
+		existing string
+		profile  string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:    "new config file",
+			path:    "config.yaml",
+			profile: "mainnet",
+			want:    "profiles:\n  mainnet:\n    plugin-path: /plugins\n",
+		},
+		{
+			name:     "keeps other settings and comments",
+			path:     "config.yaml",
+			existing: "# shared settings\nopm-path: /opm\nprofiles:\n  testnet:\n    plugin-path: /testnet\n",
+			profile:  "mainnet",
+			want:     "# shared settings\nopm-path: /opm\nprofiles:\n  testnet:\n    plugin-path: /testnet\n  mainnet:\n    plugin-path: /plugins\n",
+		},
+		{
+			name:     "replaces existing profile",
+			path:     "config.yaml",
+			existing: "profiles:\n  mainnet:\n    admin-api-endpoint: 127.0.0.1:9650\n",
+			profile:  "mainnet",
+			want:     "profiles:\n  mainnet:\n    plugin-path: /plugins\n",
+		},
+		{
+			name:     "empty profiles",
+			path:     "config.yml",
+			existing: "profiles:\n",
+			profile:  "mainnet",
+			want:     "profiles:\n  mainnet:\n    plugin-path: /plugins\n",
+		},
+		{
+			name:    "invalid name",
+			path:    "config.yaml",
+			profile: "main/net",
+			wantErr: errInvalidProfileID,
+		},
+		{
+			name:    "not yaml",
+			path:    "config.json",
+			profile: "mainnet",
+			wantErr: errNotYAML,
+		},
+		{
+			name:     "invalid profiles",
+			path:     "config.yaml",
+			existing: "profiles: foo\n",
+			profile:  "mainnet",
+			wantErr:  errInvalidProfiles,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if test.existing != "" {
+				assert.NoError(t, afero.WriteFile(fs, test.path, []byte(test.existing), perms.ReadWrite))
+			}
+
+			err := WriteProfile(fs, test.path, test.profile, Profile{PluginPath: "/plugins"})
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+
+			contents, err := afero.ReadFile(fs, test.path)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, string(contents))
+
+			profiles, err := ReadProfiles(fs, test.path)
+			assert.NoError(t, err)
+			assert.Equal(t, Profile{PluginPath: "/plugins"}, profiles[test.profile])
+		})
+	}
+}
+
+func TestRemoveProfile(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	assert.NoError(t, afero.WriteFile(fs, "config.yaml", []byte("profiles:\n  mainnet:\n    plugin-path: /mainnet\n  testnet:\n    plugin-path: /testnet\n"), perms.ReadWrite))
+
+	removed, err := RemoveProfile(fs, "config.yaml", "mainnet")
+	assert.NoError(t, err)
+	assert.True(t, removed)
+
+	removed, err = RemoveProfile(fs, "config.yaml", "mainnet")
+	assert.NoError(t, err)
+	assert.False(t, removed)
+
+	profiles, err := ReadProfiles(fs, "config.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]Profile{"testnet": {PluginPath: "/testnet"}}, profiles)
+}
+
+func TestReadProfilesMissingFile(t *testing.T) {
+	profiles, err := ReadProfiles(afero.NewMemMapFs(), "config.yaml")
+	assert.NoError(t, err)
+	assert.Empty(t, profiles)
+}
@@ -0,0 +1,166 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/DioneProtocol/opm/constant"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/util"
+)
+
+var (
+	errInvalidSource    = errors.New("invalid source")
+	errInvalidReference = errors.New("invalid reference")
+)
+
+// Repository is a repository, where its definitions come from and what it's
+// tracked by.
+type Repository struct {
+	Alias string `yaml:"alias"`
+	// URL is the path of the directory for local sources.
+	URL string `yaml:"url"`
+	// Source is git, http or local. Defaults to git.
+	Source storage.SourceType `yaml:"source,omitempty"`
+	// Branch is the branch a git repository tracks. Defaults to the core
+	// repository's branch if the repository is tracked by its branch.
+	Branch string `yaml:"branch,omitempty"`
+	// ReferenceType is what a git repository is tracked by: its branch, a tag,
+	// a commit or a tag pattern. Defaults to its branch.
+	ReferenceType storage.ReferenceType `yaml:"referenceType,omitempty"`
+	// Reference is the tag, commit or tag pattern a git repository is tracked
+	// by.
+	Reference string `yaml:"reference,omitempty"`
+	// PublicKey is the hex-encoded ed25519 key the index of an http source is
+	// signed with.
+	PublicKey string `yaml:"publicKey,omitempty"`
+}
+
+// NewRepository returns the repository tracked by [sourceInfo].
+func NewRepository(sourceInfo storage.SourceInfo) Repository {
+	repository := Repository{
+		Alias:         sourceInfo.Alias,
+		URL:           sourceInfo.URL,
+		Source:        sourceInfo.Source,
+		ReferenceType: sourceInfo.ReferenceType,
+		Reference:     sourceInfo.Reference,
+		PublicKey:     sourceInfo.PublicKey,
+	}
+	if sourceInfo.Branch != "" {
+		repository.Branch = sourceInfo.Branch.Short()
+	}
+
+	return repository
+}
+
+// SourceInfo returns how [r] is tracked, before it's synced.
+func (r Repository) SourceInfo() storage.SourceInfo {
+	r = r.withDefaults()
+
+	sourceInfo := storage.SourceInfo{
+		Alias:         r.Alias,
+		URL:           r.URL,
+		Commit:        plumbing.ZeroHash,
+		Source:        r.Source,
+		ReferenceType: r.ReferenceType,
+		Reference:     r.Reference,
+		PublicKey:     r.PublicKey,
+	}
+	if r.Branch != "" {
+		sourceInfo.Branch = plumbing.NewBranchReferenceName(r.Branch)
+	}
+
+	return sourceInfo
+}
+
+// Matches returns true if [sourceInfo] is [r], tracked the same way.
+func (r Repository) Matches(sourceInfo storage.SourceInfo) bool {
+	want := r.SourceInfo()
+
+	return sourceInfo.URL == want.URL &&
+		sourceInfo.Source == want.Source &&
+		sourceInfo.ReferenceType == want.ReferenceType &&
+		sourceInfo.Tracked() == want.Tracked() &&
+		sourceInfo.PublicKey == want.PublicKey
+}
+
+// String returns the url of [r], along with what it's tracked by for git
+// repositories.
+func (r Repository) String() string {
+	sourceInfo := r.SourceInfo()
+	if sourceInfo.Source != storage.GitSource {
+		return fmt.Sprintf("%s (%s)", sourceInfo.URL, sourceInfo.Source)
+	}
+
+	return fmt.Sprintf("%s@%s", sourceInfo.URL, sourceInfo.Tracked())
+}
+
+// withDefaults returns [r] tracked by the core repository's branch if it
+// doesn't say otherwise.
+func (r Repository) withDefaults() Repository {
+	if r.Source == "" {
+		r.Source = storage.GitSource
+	}
+	if r.Source != storage.GitSource {
+		return r
+	}
+
+	if r.ReferenceType == "" {
+		r.ReferenceType = storage.BranchReference
+	}
+	if r.ReferenceType == storage.BranchReference && r.Branch == "" {
+		r.Branch = constant.CoreBranch
+	}
+
+	return r
+}
+
+// Verify checks that [r] is a valid alias, url and combination of source and
+// reference. The reference itself is checked when the repository is added.
+func (r Repository) Verify() error {
+	if !util.ValidAlias(r.Alias) {
+		return fmt.Errorf("%w: %q", errInvalidAlias, r.Alias)
+	}
+	if r.URL == "" {
+		return fmt.Errorf("%w: %s", errMissingURL, r.Alias)
+	}
+
+	r = r.withDefaults()
+	switch r.Source {
+	case storage.GitSource, storage.HTTPSource, storage.LocalSource:
+	default:
+		return fmt.Errorf("%w: unknown source %q (%s)", errInvalidSource, r.Source, r.Alias)
+	}
+	if (r.Source == storage.HTTPSource) != (r.PublicKey != "") {
+		return fmt.Errorf("%w: only %s sources have a public key, and they need one (%s)", errInvalidSource, storage.HTTPSource, r.Alias)
+	}
+	if r.Source != storage.GitSource {
+		if r.Branch != "" || r.ReferenceType != "" || r.Reference != "" {
+			return fmt.Errorf("%w: %s sources aren't tracked by a reference (%s)", errInvalidReference, r.Source, r.Alias)
+		}
+		return nil
+	}
+
+	switch r.ReferenceType {
+	case storage.BranchReference:
+		if r.Reference != "" {
+			return fmt.Errorf("%w: branches are set with branch instead of reference (%s)", errInvalidReference, r.Alias)
+		}
+	case storage.TagReference, storage.CommitReference, storage.TagPatternReference:
+		if r.Branch != "" {
+			return fmt.Errorf("%w: a branch can't be set when tracking a %s (%s)", errInvalidReference, r.ReferenceType, r.Alias)
+		}
+		if r.Reference == "" {
+			return fmt.Errorf("%w: missing %s to track for %s", errInvalidReference, r.ReferenceType, r.Alias)
+		}
+	default:
+		return fmt.Errorf("%w: unknown reference type %q (%s)", errInvalidReference, r.ReferenceType, r.Alias)
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/storage"
+)
+
+func TestRepositorySourceInfo(t *testing.T) {
+	tests := []struct {
+		name       string
+		sourceInfo storage.SourceInfo
+	}{
+		{
+			name: "branch",
+			sourceInfo: storage.SourceInfo{
+				Alias:         "foo/bar",
+				URL:           "https://github.com/foo/bar.git",
+				Branch:        plumbing.NewBranchReferenceName("main"),
+				ReferenceType: storage.BranchReference,
+				Source:        storage.GitSource,
+			},
+		},
+		{
+			name: "tag",
+			sourceInfo: storage.SourceInfo{
+				Alias:         "foo/bar",
+				URL:           "https://github.com/foo/bar.git",
+				ReferenceType: storage.TagReference,
+				Reference:     "v1.0.0",
+				Source:        storage.GitSource,
+			},
+		},
+		{
+			name: "tag pattern",
+			sourceInfo: storage.SourceInfo{
+				Alias:         "foo/bar",
+				URL:           "https://github.com/foo/bar.git",
+				ReferenceType: storage.TagPatternReference,
+				Reference:     "v1.*",
+				Source:        storage.GitSource,
+			},
+		},
+		{
+			name: "http",
+			sourceInfo: storage.SourceInfo{
+				Alias:     "foo/bar",
+				URL:       "https://example.com/index.yaml",
+				Source:    storage.HTTPSource,
+				PublicKey: "abcd",
+			},
+		},
+		{
+			name: "local",
+			sourceInfo: storage.SourceInfo{
+				Alias:  "foo/bar",
+				URL:    "/plugins",
+				Source: storage.LocalSource,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			synced := test.sourceInfo
+			synced.Commit = plumbing.NewHash("d4e5f6")
+
+			repository := NewRepository(synced)
+			assert.NoError(t, repository.Verify())
+			assert.True(t, repository.Matches(synced))
+			assert.Equal(t, test.sourceInfo, repository.SourceInfo())
+		})
+	}
+}
+
+func TestRepositoryMatches(t *testing.T) {
+	repository := Repository{Alias: "foo/bar", URL: "https://github.com/foo/bar.git"}
+	sourceInfo := repository.SourceInfo()
+	assert.True(t, repository.Matches(sourceInfo))
+
+	tagged := sourceInfo
+	tagged.Branch = ""
+	tagged.ReferenceType = storage.TagReference
+	tagged.Reference = "develop"
+	assert.False(t, repository.Matches(tagged))
+
+	moved := sourceInfo
+	moved.URL = "https://github.com/foo/baz.git"
+	assert.False(t, repository.Matches(moved))
+}
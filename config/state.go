@@ -0,0 +1,135 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// StateVersion is the version of the state archive format written by the opm.
+const StateVersion = 1
+
+var (
+	errUnsupportedState = errors.New("unsupported state archive version")
+	errInvalidSubnet    = errors.New("invalid subnet")
+	errInvalidCommit    = errors.New("invalid commit")
+)
+
+// State is a portable archive of the opm's state: the tracked repositories,
+// the installed VMs and the joined subnets.
+type State struct {
+	Version      int               `yaml:"version"`
+	Repositories []StateRepository `yaml:"repositories"`
+	// VMs are installed exactly as they were on the exporting host.
+	VMs     []LockedVM    `yaml:"vms"`
+	Subnets []StateSubnet `yaml:"subnets"`
+}
+
+// StateRepository is a tracked repository.
+type StateRepository struct {
+	Repository `yaml:",inline"`
+	// Commit is the last commit synced from the repository, if it was synced.
+	Commit string `yaml:"commit,omitempty"`
+}
+
+// StateSubnet is a joined subnet.
+type StateSubnet struct {
+	// Name is the fully qualified name of the subnet.
+	Name string `yaml:"name"`
+	ID   string `yaml:"id"`
+}
+
+// ReadState reads and verifies the state archive at [path].
+func ReadState(fs afero.Fs, path string) (State, error) {
+	contents, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return State{}, err
+	}
+
+	state := State{}
+	if err := yaml.Unmarshal(contents, &state); err != nil {
+		return State{}, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+
+	if err := state.Verify(); err != nil {
+		return State{}, fmt.Errorf("invalid state archive %s: %w", path, err)
+	}
+
+	return state, nil
+}
+
+// WriteState writes [state] to [path].
+func WriteState(fs afero.Fs, path string, state State) error {
+	contents, err := yaml.Marshal(state)
+	if err != nil {
+		return err
+	}
+
+	if err := fs.MkdirAll(filepath.Dir(path), perms.ReadWriteExecute); err != nil {
+		return err
+	}
+
+	return afero.WriteFile(fs, path, contents, perms.ReadWrite)
+}
+
+func (s State) Verify() error {
+	if s.Version != StateVersion {
+		return fmt.Errorf("%w %d (expected %d)", errUnsupportedState, s.Version, StateVersion)
+	}
+
+	for _, repository := range s.Repositories {
+		if repository.Commit != "" && !plumbing.IsHash(repository.Commit) {
+			return fmt.Errorf("%w %q for %s", errInvalidCommit, repository.Commit, repository.Alias)
+		}
+	}
+
+	// The installed VMs are verified the same way as a lockfile.
+	if err := s.Lockfile().Verify(); err != nil {
+		return err
+	}
+
+	repositories := make(map[string]struct{}, len(s.Repositories))
+	for _, repository := range s.Repositories {
+		repositories[repository.Alias] = struct{}{}
+	}
+
+	subnets := make(map[string]struct{}, len(s.Subnets))
+	for _, subnet := range s.Subnets {
+		if err := verifyManifestName(subnet.Name, repositories); err != nil {
+			return err
+		}
+		if _, ok := subnets[subnet.Name]; ok {
+			return fmt.Errorf("subnet %s %w", subnet.Name, errDuplicateEntry)
+		}
+		subnets[subnet.Name] = struct{}{}
+
+		if _, err := ids.FromString(subnet.ID); err != nil {
+			return fmt.Errorf("%w %s: %s", errInvalidSubnet, subnet.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// Lockfile returns the installed VMs in [s] as a lockfile.
+func (s State) Lockfile() Lockfile {
+	lockfile := Lockfile{
+		Version:      LockfileVersion,
+		Repositories: make([]LockedRepository, 0, len(s.Repositories)),
+		VMs:          s.VMs,
+	}
+	for _, repository := range s.Repositories {
+		lockfile.Repositories = append(lockfile.Repositories, repository.Repository)
+	}
+
+	return lockfile
+}
@@ -0,0 +1,166 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package config
+
+import (
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/storage"
+)
+
+func TestStateRoundTrip(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	state := State{
+		Version: StateVersion,
+		Repositories: []StateRepository{
+			{
+				Repository: Repository{Alias: "foo/bar", URL: "https://github.com/foo/bar.git", Branch: "main"},
+				Commit:     "0123456789abcdef0123456789abcdef01234567",
+			},
+			{Repository: Repository{Alias: "foo/baz", URL: "https://github.com/foo/baz.git", ReferenceType: storage.TagReference, Reference: "v1.0.0"}},
+			{Repository: Repository{Alias: "foo/index", URL: "https://example.com/index.yaml", Source: storage.HTTPSource, PublicKey: "abcd"}},
+		},
+		VMs: []LockedVM{
+			{
+				Name:    "foo/bar:foovm",
+				ID:      "id",
+				Version: "v1.2.3",
+				Commit:  "0123456789abcdef0123456789abcdef01234567",
+				URL:     "https://foo.com/foovm.tar.gz",
+				SHA256:  "abcd",
+			},
+		},
+		Subnets: []StateSubnet{
+			{Name: "foo/bar:foo", ID: ids.GenerateTestID().String()},
+		},
+	}
+
+	assert.NoError(t, WriteState(fs, "backups/opm-state.yaml", state))
+
+	got, err := ReadState(fs, "backups/opm-state.yaml")
+	assert.NoError(t, err)
+	assert.Equal(t, state, got)
+
+	assert.Equal(t, Lockfile{
+		Version: LockfileVersion,
+		Repositories: []LockedRepository{
+			{Alias: "foo/bar", URL: "https://github.com/foo/bar.git", Branch: "main"},
+			{Alias: "foo/baz", URL: "https://github.com/foo/baz.git", ReferenceType: storage.TagReference, Reference: "v1.0.0"},
+			{Alias: "foo/index", URL: "https://example.com/index.yaml", Source: storage.HTTPSource, PublicKey: "abcd"},
+		},
+		VMs: state.VMs,
+	}, got.Lockfile())
+}
+
+func TestStateVerify(t *testing.T) {
+	repository := StateRepository{Repository: Repository{Alias: "foo/bar", URL: "https://github.com/foo/bar.git", Branch: "main"}}
+	vm := LockedVM{
+		Name:    "foo/bar:foovm",
+		ID:      "id",
+		Version: "v1.2.3",
+		Commit:  "0123456789abcdef0123456789abcdef01234567",
+		URL:     "https://foo.com/foovm.tar.gz",
+		SHA256:  "abcd",
+	}
+	subnet := StateSubnet{Name: "foo/bar:foo", ID: ids.GenerateTestID().String()}
+
+	tests := []struct {
+		name    string
+		state   func() State
+		wantErr error
+	}{
+		{
+			name: "valid",
+			state: func() State {
+				return State{Version: StateVersion, Repositories: []StateRepository{repository}, VMs: []LockedVM{vm}, Subnets: []StateSubnet{subnet}}
+			},
+		},
+		{
+			name: "empty",
+			state: func() State {
+				return State{Version: StateVersion}
+			},
+		},
+		{
+			name: "unsupported version",
+			state: func() State {
+				return State{Version: StateVersion + 1}
+			},
+			wantErr: errUnsupportedState,
+		},
+		{
+			name: "invalid repository commit",
+			state: func() State {
+				invalid := repository
+				invalid.Commit = "main"
+				return State{Version: StateVersion, Repositories: []StateRepository{invalid}}
+			},
+			wantErr: errInvalidCommit,
+		},
+		{
+			name: "missing reference",
+			state: func() State {
+				invalid := repository
+				invalid.Branch = ""
+				invalid.ReferenceType = storage.TagReference
+				return State{Version: StateVersion, Repositories: []StateRepository{invalid}}
+			},
+			wantErr: errInvalidReference,
+		},
+		{
+			name: "duplicate repository",
+			state: func() State {
+				return State{Version: StateVersion, Repositories: []StateRepository{repository, repository}}
+			},
+			wantErr: errDuplicateEntry,
+		},
+		{
+			name: "invalid vm",
+			state: func() State {
+				invalid := vm
+				invalid.SHA256 = ""
+				return State{Version: StateVersion, Repositories: []StateRepository{repository}, VMs: []LockedVM{invalid}}
+			},
+			wantErr: errInvalidLockedVM,
+		},
+		{
+			name: "subnet from unknown repository",
+			state: func() State {
+				return State{Version: StateVersion, Subnets: []StateSubnet{subnet}}
+			},
+			wantErr: errUndeclaredRepository,
+		},
+		{
+			name: "duplicate subnet",
+			state: func() State {
+				return State{Version: StateVersion, Repositories: []StateRepository{repository}, Subnets: []StateSubnet{subnet, subnet}}
+			},
+			wantErr: errDuplicateEntry,
+		},
+		{
+			name: "invalid subnet id",
+			state: func() State {
+				invalid := subnet
+				invalid.ID = "foo"
+				return State{Version: StateVersion, Repositories: []StateRepository{repository}, Subnets: []StateSubnet{invalid}}
+			},
+			wantErr: errInvalidSubnet,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.state().Verify()
+			if test.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, test.wantErr)
+			}
+		})
+	}
+}
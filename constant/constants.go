@@ -10,4 +10,9 @@ const (
 	CoreBranch             = "develop"
 	QualifiedNameDelimiter = ":"
 	AliasDelimiter         = "/"
+
+	// VMType and SubnetType prefix references to a VM or a subnet (e.g
+	// vm:spacesvm), since they have separate namespaces.
+	VMType     = "vm"
+	SubnetType = "subnet"
 )
@@ -0,0 +1,139 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package git
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/spf13/afero"
+
+	"github.com/DioneProtocol/opm/config"
+)
+
+// tokenUsername is sent along with tokens, since git hosts only check the
+// token.
+const tokenUsername = "opm"
+
+// Credentials picks the auth method for each repository from the configured
+// git credentials.
+type Credentials struct {
+	// Entries are the credentials for specific hosts or repositories.
+	Entries []config.GitCredential
+	// Default is used for repositories over http that no entry matches.
+	Default *http.BasicAuth
+	// Fs is used to read secrets and keys from files.
+	Fs afero.Fs
+}
+
+// Auth returns the auth method for the repository [alias] at [url]. An entry
+// for the repository takes precedence over an entry for its host, and entries
+// that can't be used over the protocol of [url] are skipped. A nil auth method
+// is returned if there's nothing to authenticate with, in which case ssh falls
+// back to the ssh-agent.
+func (c Credentials) Auth(alias string, url string) (transport.AuthMethod, error) {
+	endpoint, err := transport.NewEndpoint(url)
+	if err != nil {
+		return nil, err
+	}
+
+	usesSSH := endpoint.Protocol == "ssh"
+	usable := func(entry config.GitCredential) bool {
+		isSSH := entry.Type == config.SSHKeyAuth || entry.Type == config.SSHAgentAuth
+		return isSSH == usesSSH
+	}
+
+	for _, matches := range []func(config.GitCredential) bool{
+		func(entry config.GitCredential) bool { return entry.Repository == alias },
+		func(entry config.GitCredential) bool {
+			return entry.Host != "" && strings.EqualFold(entry.Host, endpoint.Host)
+		},
+	} {
+		for _, entry := range c.Entries {
+			if matches(entry) && usable(entry) {
+				return c.authMethod(entry, endpoint)
+			}
+		}
+	}
+
+	if usesSSH || c.Default == nil || (c.Default.Username == "" && c.Default.Password == "") {
+		return nil, nil
+	}
+
+	return c.Default, nil
+}
+
+// authMethod builds the auth method for [entry] to authenticate to
+// [endpoint].
+func (c Credentials) authMethod(entry config.GitCredential, endpoint *transport.Endpoint) (transport.AuthMethod, error) {
+	username := entry.Username
+	if username == "" {
+		username = endpoint.User
+	}
+
+	switch entry.Type {
+	case config.BasicAuth:
+		password, err := entry.Password.Resolve(c.Fs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the password for %s: %w", entry, err)
+		}
+		return &http.BasicAuth{Username: entry.Username, Password: password}, nil
+	case config.TokenAuth:
+		token, err := entry.Token.Resolve(c.Fs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the token for %s: %w", entry, err)
+		}
+		if username == "" {
+			username = tokenUsername
+		}
+		return &http.BasicAuth{Username: username, Password: token}, nil
+	case config.SSHKeyAuth:
+		passphrase, err := entry.Passphrase.Resolve(c.Fs)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the passphrase for %s: %w", entry, err)
+		}
+		keyPath, err := expandHome(entry.Key)
+		if err != nil {
+			return nil, err
+		}
+		key, err := afero.ReadFile(c.Fs, keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read the key for %s: %w", entry, err)
+		}
+		if username == "" {
+			username = ssh.DefaultUsername
+		}
+		auth, err := ssh.NewPublicKeys(username, key, passphrase)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse the key for %s: %w", entry, err)
+		}
+		return auth, nil
+	case config.SSHAgentAuth:
+		if username == "" {
+			username = ssh.DefaultUsername
+		}
+		return ssh.NewSSHAgentAuth(username)
+	default:
+		return nil, fmt.Errorf("unknown auth type %q for %s", entry.Type, entry)
+	}
+}
+
+// expandHome replaces a leading ~ in [path] with the home directory.
+func expandHome(path string) (string, error) {
+	if path != "~" && !strings.HasPrefix(path, "~/") {
+		return path, nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, strings.TrimPrefix(path, "~")), nil
+}
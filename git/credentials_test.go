@@ -0,0 +1,105 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package git
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport/ssh"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/config"
+)
+
+func TestCredentialsAuth(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	assert.NoError(t, err)
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	assert.NoError(t, afero.WriteFile(fs, "/id_rsa", keyPEM, perms.ReadWrite))
+	t.Setenv("OPM_TEST_TOKEN", "repository-token")
+
+	credentials := Credentials{
+		Entries: []config.GitCredential{
+			{Repository: "organization/private", Type: config.TokenAuth, Token: config.Secret{Env: "OPM_TEST_TOKEN"}},
+			{Host: "github.com", Type: config.BasicAuth, Username: "user", Password: config.Secret{Value: "host-password"}},
+			{Host: "github.com", Type: config.SSHKeyAuth, Key: "/id_rsa"},
+			{Host: "gitlab.com", Type: config.TokenAuth, Token: config.Secret{Env: "OPM_TEST_MISSING_TOKEN"}},
+		},
+		Default: &http.BasicAuth{Username: "default", Password: "default-password"},
+		Fs:      fs,
+	}
+
+	tests := []struct {
+		name      string
+		alias     string
+		url       string
+		want      transport.AuthMethod
+		wantSSH   bool
+		wantError bool
+	}{
+		{
+			name:  "repository takes precedence over host",
+			alias: "organization/private",
+			url:   "https://github.com/organization/private",
+			want:  &http.BasicAuth{Username: tokenUsername, Password: "repository-token"},
+		},
+		{
+			name:  "host",
+			alias: "organization/public",
+			url:   "https://github.com/organization/public",
+			want:  &http.BasicAuth{Username: "user", Password: "host-password"},
+		},
+		{
+			name:    "entries are picked by protocol",
+			alias:   "organization/public",
+			url:     "git@github.com:organization/public.git",
+			wantSSH: true,
+		},
+		{
+			name:  "default",
+			alias: "organization/other",
+			url:   "https://example.com/organization/other",
+			want:  &http.BasicAuth{Username: "default", Password: "default-password"},
+		},
+		{
+			name:  "no default over ssh",
+			alias: "organization/other",
+			url:   "ssh://git@example.com/organization/other",
+		},
+		{
+			name:      "missing secret",
+			alias:     "organization/other",
+			url:       "https://gitlab.com/organization/other",
+			wantError: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			auth, err := credentials.Auth(test.alias, test.url)
+			if test.wantError {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			if test.wantSSH {
+				publicKeys, ok := auth.(*ssh.PublicKeys)
+				assert.True(t, ok)
+				assert.Equal(t, "git", publicKeys.User)
+				return
+			}
+			assert.Equal(t, test.want, auth)
+		})
+	}
+}
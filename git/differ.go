@@ -0,0 +1,126 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package git
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+// Change is a file that was added, modified, renamed or deleted between two
+// commits.
+type Change struct {
+	// From is the path of the file before the change, or empty if it was
+	// added.
+	From string
+	// To is the path of the file after the change, or empty if it was
+	// deleted.
+	To string
+
+	Before []byte
+	After  []byte
+}
+
+type Differ interface {
+	// Diff returns the changes between [from] and [to] to the files directly
+	// in [dirs] of the repository at [path]. ErrCommitNotFound is returned if
+	// [from] isn't in the history of [to].
+	Diff(path string, from plumbing.Hash, to plumbing.Hash, dirs []string) ([]Change, error)
+}
+
+var _ Differ = CommitDiffer{}
+
+type CommitDiffer struct{}
+
+func (CommitDiffer) Diff(repoPath string, from plumbing.Hash, to plumbing.Hash, dirs []string) ([]Change, error) {
+	repo, err := git.PlainOpen(repoPath)
+	if err != nil {
+		return nil, err
+	}
+
+	toCommit, err := repo.CommitObject(to)
+	if err != nil {
+		return nil, err
+	}
+
+	// The previous commit may still be in the object store after a force
+	// push, so it's checked against the history too.
+	fromCommit, err := repo.CommitObject(from)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, fmt.Errorf("%w: %s in %s", ErrCommitNotFound, from, repoPath)
+	} else if err != nil {
+		return nil, err
+	}
+	if !inHistory(repo, from, to) {
+		return nil, fmt.Errorf("%w: %s in the history of %s", ErrCommitNotFound, from, to)
+	}
+
+	fromTree, err := fromCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+	toTree, err := toCommit.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	treeChanges, err := object.DiffTreeWithOptions(context.Background(), fromTree, toTree, object.DefaultDiffTreeOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	inDirs := func(name string) bool {
+		for _, dir := range dirs {
+			if name != "" && path.Dir(name) == dir {
+				return true
+			}
+		}
+		return false
+	}
+
+	changes := []Change{}
+	for _, treeChange := range treeChanges {
+		fromName, toName := treeChange.From.Name, treeChange.To.Name
+		if !inDirs(fromName) && !inDirs(toName) {
+			continue
+		}
+
+		fromFile, toFile, err := treeChange.Files()
+		if err != nil {
+			return nil, err
+		}
+
+		change := Change{}
+		if fromFile != nil && inDirs(fromName) {
+			change.From = fromName
+			if change.Before, err = contents(fromFile); err != nil {
+				return nil, err
+			}
+		}
+		if toFile != nil && inDirs(toName) {
+			change.To = toName
+			if change.After, err = contents(toFile); err != nil {
+				return nil, err
+			}
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, nil
+}
+
+func contents(file *object.File) ([]byte, error) {
+	contents, err := file.Contents()
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(contents), nil
+}
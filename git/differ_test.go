@@ -0,0 +1,56 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package git
+
+import (
+	"testing"
+	"time"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCommitDiffer(t *testing.T) {
+	path := t.TempDir()
+	repo, err := git.PlainInit(path, false)
+	assert.NoError(t, err)
+
+	first := commitFile(t, repo, path, "vms/foovm.yaml", "foo v1")
+	commitFile(t, repo, path, "vms/barvm.yaml", "bar v1")
+	commitFile(t, repo, path, "README.md", "readme")
+	commitFile(t, repo, path, "vms/nested/bazvm.yaml", "baz v1")
+	last := commitFile(t, repo, path, "vms/foovm.yaml", "foo v2")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	_, err = worktree.Remove("vms/barvm.yaml")
+	assert.NoError(t, err)
+	deleted, err := worktree.Commit("delete barvm", &git.CommitOptions{
+		Author: &object.Signature{Name: "opm", Email: "opm@example.com", When: time.Now()},
+	})
+	assert.NoError(t, err)
+
+	differ := CommitDiffer{}
+
+	changes, err := differ.Diff(path, first, last, []string{"vms", "subnets"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []Change{
+		{To: "vms/barvm.yaml", After: []byte("bar v1")},
+		{From: "vms/foovm.yaml", To: "vms/foovm.yaml", Before: []byte("foo v1"), After: []byte("foo v2")},
+	}, changes)
+
+	changes, err = differ.Diff(path, last, deleted, []string{"vms"})
+	assert.NoError(t, err)
+	assert.Equal(t, []Change{{From: "vms/barvm.yaml", Before: []byte("bar v1")}}, changes)
+
+	// Commits that aren't ancestors aren't in the history, even if they're in
+	// the object store.
+	_, err = differ.Diff(path, deleted, last, []string{"vms"})
+	assert.ErrorIs(t, err, ErrCommitNotFound)
+
+	_, err = differ.Diff(path, plumbing.NewHash("0123456789abcdef0123456789abcdef01234567"), last, []string{"vms"})
+	assert.ErrorIs(t, err, ErrCommitNotFound)
+}
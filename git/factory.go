@@ -4,68 +4,327 @@
 package git
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"path"
+	"strings"
 
+	"github.com/DioneProtocol/odysseygo/version"
 	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/memory"
 )
 
-type Factory interface {
-	GetRepository(url string, path string, reference plumbing.ReferenceName, auth *http.BasicAuth) (plumbing.Hash, error)
+const remoteName = "origin"
+
+var ErrTagNotFound = errors.New("tag not found")
+
+// Reference is what a clone tracks: a branch, a tag, the newest tag matching a
+// pattern, or a commit.
+type Reference struct {
+	// Name is the branch or tag to check out.
+	Name plumbing.ReferenceName
+	// TagPattern checks out the newest tag matching it (e.g. v1.*) by
+	// semantic version instead of [Name].
+	TagPattern string
+	// Commit checks out the commit instead of [Name].
+	Commit plumbing.Hash
+}
+
+func (r Reference) String() string {
+	switch {
+	case r.Commit != plumbing.ZeroHash:
+		return r.Commit.String()
+	case r.TagPattern != "":
+		return r.TagPattern
+	default:
+		return r.Name.Short()
+	}
 }
 
-type RepositoryFactory struct{}
+type Factory interface {
+	// GetRepository syncs the clone at [path] to [reference] in the repository
+	// at [url], and returns the commit that's checked out.
+	GetRepository(url string, path string, reference Reference, auth transport.AuthMethod) (plumbing.Hash, error)
+}
 
-func (f RepositoryFactory) GetRepository(url string, path string, reference plumbing.ReferenceName, auth *http.BasicAuth) (plumbing.Hash, error) {
-	var repo *git.Repository
+type RepositoryFactory struct {
+	// Depth limits fetches to this many commits. The full history is fetched
+	// if it's 0.
+	Depth int
+}
 
-	switch _, err := os.Stat(path); err {
-	case nil:
-		// already exists, so we need to check out the latest changes
-		repo, err = git.PlainOpen(path)
+// GetRepository clones the repository if it doesn't exist yet. The tracked
+// reference is then fetched and checked out, discarding any local changes, so
+// the clone follows the remote even if its history was rewritten.
+func (f RepositoryFactory) GetRepository(url string, path string, reference Reference, auth transport.AuthMethod) (plumbing.Hash, error) {
+	if reference.TagPattern != "" {
+		tag, err := latestTag(url, reference.TagPattern, auth)
 		if err != nil {
 			return plumbing.ZeroHash, err
 		}
-		worktree, err := repo.Worktree()
-		if err != nil {
+
+		fmt.Printf("Using %s, the newest tag matching %s.\n", tag.Short(), reference.TagPattern)
+		reference = Reference{Name: tag}
+	}
+
+	repo, err := git.PlainOpen(path)
+	if errors.Is(err, git.ErrRepositoryNotExists) {
+		// New clones are synced the same way as existing ones.
+		repo, err = git.PlainInit(path, false)
+	}
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	urlChanged, err := setRemoteURL(repo, url)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	latest, err := f.fetch(repo, reference, auth)
+	if errors.Is(err, plumbing.ErrObjectNotFound) && isShallow(repo) {
+		// go-git can't fetch into a shallow clone once the remote moved past
+		// the commits it has, so the clone is started over instead.
+		fmt.Printf("Fetching a new shallow clone of %s into %s.\n", url, path)
+		if repo, err = reinit(path, url); err != nil {
 			return plumbing.ZeroHash, err
 		}
-		if err := worktree.Pull(
-			// TODO use fetch + checkout instead of pull
-			&git.PullOptions{
-				RemoteName:    "origin",
-				ReferenceName: reference,
-				SingleBranch:  true,
-				Auth:          auth,
-				Progress:      io.Discard,
-			},
-		); err != nil && err != git.NoErrAlreadyUpToDate {
+		latest, err = f.fetch(repo, reference, auth)
+	}
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	// A new clone doesn't have a head yet.
+	head, err := repo.Head()
+	switch {
+	case errors.Is(err, plumbing.ErrReferenceNotFound):
+	case err != nil:
+		return plumbing.ZeroHash, err
+	case !reference.Name.IsBranch():
+		if head.Hash() != latest {
+			fmt.Printf("Checking out %s at %s in %s.\n", reference, latest, path)
+		}
+	case head.Name() != reference.Name:
+		fmt.Printf("Switching %s from %s to %s.\n", path, head.Name().Short(), reference)
+	case !urlChanged && head.Hash() != latest && rewritten(repo, head.Hash(), latest):
+		fmt.Printf("Warning - the history of %s in %s was rewritten. Local commit %s is no longer in it, and is discarded.\n", reference, url, head.Hash())
+	}
+
+	worktree, err := repo.Worktree()
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	// Tags and commits are checked out detached.
+	checkout := &git.CheckoutOptions{
+		Hash:  latest,
+		Force: true,
+	}
+	if reference.Name.IsBranch() {
+		// The local branch is moved to the fetched commit before it's checked
+		// out, so the checkout doesn't need to merge anything.
+		if err := repo.Storer.SetReference(plumbing.NewHashReference(reference.Name, latest)); err != nil {
 			return plumbing.ZeroHash, err
 		}
+		checkout = &git.CheckoutOptions{
+			Branch: reference.Name,
+			Force:  true,
+		}
+	}
+	if err := worktree.Checkout(checkout); err != nil {
+		return plumbing.ZeroHash, err
+	}
+	if err := worktree.Clean(&git.CleanOptions{Dir: true}); err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	return latest, nil
+}
+
+// fetch fetches [reference] into [repo], and returns the commit it points to.
+func (f RepositoryFactory) fetch(repo *git.Repository, reference Reference, auth transport.AuthMethod) (plumbing.Hash, error) {
+	options := &git.FetchOptions{
+		RemoteName: remoteName,
+		Depth:      f.Depth,
+		Auth:       auth,
+		Progress:   io.Discard,
+		Force:      true,
+	}
+
+	fetched := reference.Name
+	switch {
+	case reference.Commit != plumbing.ZeroHash:
+		// Servers don't have to serve a commit by its hash, so the full
+		// history of every branch is fetched instead.
+		options.RefSpecs = []config.RefSpec{config.RefSpec(fmt.Sprintf("+refs/heads/*:refs/remotes/%s/*", remoteName))}
+		options.Depth = 0
+	case reference.Name.IsBranch():
+		fetched = plumbing.NewRemoteReferenceName(remoteName, reference.Name.Short())
+		options.RefSpecs = []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", reference.Name, fetched))}
 	default:
-		if os.IsNotExist(err) {
-			// if we don't have the repo, we need to clone it
-			repo, err = git.PlainClone(path, false, &git.CloneOptions{
-				URL:           url,
-				ReferenceName: reference,
-				SingleBranch:  true,
-				Auth:          auth,
-				Progress:      io.Discard,
-			})
-			if err != nil {
-				return plumbing.ZeroHash, err
-			}
-		} else {
-			return plumbing.ZeroHash, err
+		options.RefSpecs = []config.RefSpec{config.RefSpec(fmt.Sprintf("+%s:%s", reference.Name, reference.Name))}
+	}
+
+	if err := repo.Fetch(options); err != nil && err != git.NoErrAlreadyUpToDate {
+		return plumbing.ZeroHash, err
+	}
+
+	if reference.Commit != plumbing.ZeroHash {
+		if _, err := repo.CommitObject(reference.Commit); err != nil {
+			return plumbing.ZeroHash, fmt.Errorf("%w: %s isn't on any branch of %s", ErrCommitNotFound, reference.Commit, remoteName)
 		}
+		return reference.Commit, nil
 	}
 
-	head, err := repo.Head()
+	ref, err := repo.Reference(fetched, true)
+	if err != nil {
+		return plumbing.ZeroHash, err
+	}
+
+	// Annotated tags point to a tag object rather than to a commit.
+	tag, err := repo.TagObject(ref.Hash())
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return ref.Hash(), nil
+	} else if err != nil {
+		return plumbing.ZeroHash, err
+	}
+	commit, err := tag.Commit()
 	if err != nil {
 		return plumbing.ZeroHash, err
 	}
 
-	return head.Hash(), nil
+	return commit.Hash, nil
+}
+
+// latestTag returns the tag at [url] matching [pattern] with the highest
+// semantic version. Tags that aren't semantic versions are ignored.
+func latestTag(url string, pattern string, auth transport.AuthMethod) (plumbing.ReferenceName, error) {
+	remote := git.NewRemote(memory.NewStorage(), &config.RemoteConfig{
+		Name: remoteName,
+		URLs: []string{url},
+	})
+	refs, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", err
+	}
+
+	var (
+		latest        plumbing.ReferenceName
+		latestVersion *version.Semantic
+	)
+	for _, ref := range refs {
+		if !ref.Name().IsTag() {
+			continue
+		}
+
+		tag := ref.Name().Short()
+		if ok, err := path.Match(pattern, tag); err != nil {
+			return "", fmt.Errorf("invalid tag pattern %q: %w", pattern, err)
+		} else if !ok {
+			continue
+		}
+
+		v, err := version.Parse("v" + strings.TrimPrefix(tag, "v"))
+		if err != nil {
+			continue
+		}
+		if latestVersion == nil || v.Compare(latestVersion) > 0 {
+			latest, latestVersion = ref.Name(), v
+		}
+	}
+
+	if latest == "" {
+		return "", fmt.Errorf("%w: no semantic version tag in %s matches %s", ErrTagNotFound, url, pattern)
+	}
+
+	return latest, nil
+}
+
+// setRemoteURL points the remote of [repo] at [url], creating it if it doesn't
+// exist. Returns true if it pointed somewhere else.
+func setRemoteURL(repo *git.Repository, url string) (bool, error) {
+	remote, err := repo.Remote(remoteName)
+	if errors.Is(err, git.ErrRemoteNotFound) {
+		_, err := repo.CreateRemote(&config.RemoteConfig{
+			Name: remoteName,
+			URLs: []string{url},
+		})
+		return false, err
+	}
+	if err != nil {
+		return false, err
+	}
+
+	remoteConfig := remote.Config()
+	if len(remoteConfig.URLs) > 0 && remoteConfig.URLs[0] == url {
+		return false, nil
+	}
+
+	fmt.Printf("Changing the url of %s from %v to %s.\n", remoteName, remoteConfig.URLs, url)
+	if err := repo.DeleteRemote(remoteName); err != nil {
+		return false, err
+	}
+	_, err = repo.CreateRemote(&config.RemoteConfig{
+		Name:  remoteName,
+		URLs:  []string{url},
+		Fetch: remoteConfig.Fetch,
+	})
+	return true, err
+}
+
+// inHistory returns true if [commit] is an ancestor of [head]. Commits that
+// can't be found, for example because the clone is shallow, aren't in the
+// history.
+func inHistory(repo *git.Repository, commit plumbing.Hash, head plumbing.Hash) bool {
+	ok, err := isAncestor(repo, commit, head)
+	return err == nil && ok
+}
+
+// rewritten returns true if [commit] was dropped from the history of [head].
+// If the history can't be walked, for example because it's cut off in a
+// shallow clone, it isn't known to be rewritten.
+func rewritten(repo *git.Repository, commit plumbing.Hash, head plumbing.Hash) bool {
+	ok, err := isAncestor(repo, commit, head)
+	return err == nil && !ok
+}
+
+func isAncestor(repo *git.Repository, commit plumbing.Hash, head plumbing.Hash) (bool, error) {
+	commitObject, err := repo.CommitObject(commit)
+	if err != nil {
+		return false, err
+	}
+	headObject, err := repo.CommitObject(head)
+	if err != nil {
+		return false, err
+	}
+
+	return commitObject.IsAncestor(headObject)
+}
+
+// isShallow returns true if [repo] doesn't have its full history.
+func isShallow(repo *git.Repository) bool {
+	shallow, err := repo.Storer.Shallow()
+	return err == nil && len(shallow) > 0
+}
+
+// reinit replaces the repository at [path] with an empty one whose remote
+// points at [url].
+func reinit(path string, url string) (*git.Repository, error) {
+	if err := os.RemoveAll(path); err != nil {
+		return nil, err
+	}
+
+	repo, err := git.PlainInit(path, false)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = setRemoteURL(repo, url)
+	return repo, err
 }
@@ -0,0 +1,229 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepositoryFactory(t *testing.T) {
+	var (
+		master = Reference{Name: plumbing.NewBranchReferenceName("master")}
+		other  = Reference{Name: plumbing.NewBranchReferenceName("other")}
+	)
+
+	upstreamPath := t.TempDir()
+	upstream, err := git.PlainInit(upstreamPath, false)
+	assert.NoError(t, err)
+	first := commitFile(t, upstream, upstreamPath, "vms/foovm.yaml", "v1")
+
+	path := filepath.Join(t.TempDir(), "clone")
+	factory := RepositoryFactory{}
+
+	// The repository is cloned the first time.
+	commit, err := factory.GetRepository(upstreamPath, path, master, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, first, commit)
+
+	// New commits are checked out, discarding local changes.
+	second := commitFile(t, upstream, upstreamPath, "vms/foovm.yaml", "v2")
+	assert.NoError(t, os.WriteFile(filepath.Join(path, "vms", "foovm.yaml"), []byte("modified"), perms.ReadWrite))
+	assert.NoError(t, os.WriteFile(filepath.Join(path, "vms", "untracked.yaml"), []byte("untracked"), perms.ReadWrite))
+
+	commit, err = factory.GetRepository(upstreamPath, path, master, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, second, commit)
+	assertFile(t, path, "vms/foovm.yaml", "v2")
+	assert.NoFileExists(t, filepath.Join(path, "vms", "untracked.yaml"))
+
+	// Rewritten history is followed.
+	worktree, err := upstream.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, worktree.Reset(&git.ResetOptions{Commit: first, Mode: git.HardReset}))
+	rewritten := commitFile(t, upstream, upstreamPath, "vms/foovm.yaml", "v3")
+
+	commit, err = factory.GetRepository(upstreamPath, path, master, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, rewritten, commit)
+	assertFile(t, path, "vms/foovm.yaml", "v3")
+
+	// A different branch is checked out when the tracked branch changes.
+	assert.NoError(t, worktree.Checkout(&git.CheckoutOptions{Branch: other.Name, Create: true}))
+	otherCommit := commitFile(t, upstream, upstreamPath, "vms/foovm.yaml", "other")
+
+	commit, err = factory.GetRepository(upstreamPath, path, other, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, otherCommit, commit)
+	assertFile(t, path, "vms/foovm.yaml", "other")
+
+	clone, err := git.PlainOpen(path)
+	assert.NoError(t, err)
+	head, err := clone.Head()
+	assert.NoError(t, err)
+	assert.Equal(t, other.Name, head.Name())
+
+	// The remote is updated when the url changes.
+	movedPath := t.TempDir()
+	moved, err := git.PlainInit(movedPath, false)
+	assert.NoError(t, err)
+	movedCommit := commitFile(t, moved, movedPath, "vms/foovm.yaml", "moved")
+
+	commit, err = factory.GetRepository(movedPath, path, master, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, movedCommit, commit)
+	assertFile(t, path, "vms/foovm.yaml", "moved")
+}
+
+func TestRepositoryFactoryPinned(t *testing.T) {
+	upstreamPath := t.TempDir()
+	upstream, err := git.PlainInit(upstreamPath, false)
+	assert.NoError(t, err)
+
+	first := commitFile(t, upstream, upstreamPath, "vms/foovm.yaml", "v1.0.0")
+	_, err = upstream.CreateTag("v1.0.0", first, nil)
+	assert.NoError(t, err)
+	second := commitFile(t, upstream, upstreamPath, "vms/foovm.yaml", "v1.10.0")
+	_, err = upstream.CreateTag("v1.10.0", second, &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "opm", Email: "opm@example.com", When: time.Now()},
+		Message: "v1.10.0",
+	})
+	assert.NoError(t, err)
+	third := commitFile(t, upstream, upstreamPath, "vms/foovm.yaml", "v1.9.0")
+	_, err = upstream.CreateTag("v1.9.0", third, nil)
+	assert.NoError(t, err)
+	fourth := commitFile(t, upstream, upstreamPath, "vms/foovm.yaml", "v2.0.0")
+	_, err = upstream.CreateTag("v2.0.0", fourth, nil)
+	assert.NoError(t, err)
+
+	tests := []struct {
+		name      string
+		reference Reference
+		want      plumbing.Hash
+		contents  string
+		wantErr   error
+	}{
+		{
+			name:      "tag",
+			reference: Reference{Name: plumbing.NewTagReferenceName("v1.0.0")},
+			want:      first,
+			contents:  "v1.0.0",
+		},
+		{
+			name:      "annotated tag",
+			reference: Reference{Name: plumbing.NewTagReferenceName("v1.10.0")},
+			want:      second,
+			contents:  "v1.10.0",
+		},
+		{
+			name:      "tag pattern picks the highest version",
+			reference: Reference{TagPattern: "v1.*"},
+			want:      second,
+			contents:  "v1.10.0",
+		},
+		{
+			name:      "no tag matches the pattern",
+			reference: Reference{TagPattern: "v3.*"},
+			wantErr:   ErrTagNotFound,
+		},
+		{
+			name:      "commit",
+			reference: Reference{Commit: third},
+			want:      third,
+			contents:  "v1.9.0",
+		},
+		{
+			name:      "commit isn't in the repository",
+			reference: Reference{Commit: plumbing.NewHash("0123456789abcdef0123456789abcdef01234567")},
+			wantErr:   ErrCommitNotFound,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "clone")
+			factory := RepositoryFactory{}
+
+			// Pinned references stay put when synced again.
+			for i := 0; i < 2; i++ {
+				commit, err := factory.GetRepository(upstreamPath, path, test.reference, nil)
+				if test.wantErr != nil {
+					assert.ErrorIs(t, err, test.wantErr)
+					return
+				}
+				assert.NoError(t, err)
+				assert.Equal(t, test.want, commit)
+				assertFile(t, path, "vms/foovm.yaml", test.contents)
+			}
+		})
+	}
+}
+
+func assertFile(t *testing.T, path string, name string, want string) {
+	contents, err := os.ReadFile(filepath.Join(path, name))
+	assert.NoError(t, err)
+	assert.Equal(t, want, string(contents))
+}
+
+func TestRepositoryFactoryShallow(t *testing.T) {
+	master := Reference{Name: plumbing.NewBranchReferenceName("master")}
+
+	upstreamPath := t.TempDir()
+	upstream, err := git.PlainInit(upstreamPath, false)
+	assert.NoError(t, err)
+	commitFile(t, upstream, upstreamPath, "vms/foovm.yaml", "v1")
+	commitFile(t, upstream, upstreamPath, "vms/foovm.yaml", "v2")
+
+	path := filepath.Join(t.TempDir(), "clone")
+	factory := RepositoryFactory{Depth: 1}
+
+	_, err = factory.GetRepository(upstreamPath, path, master, nil)
+	assert.NoError(t, err)
+
+	// The remote moving past the commits in the clone doesn't break it.
+	for _, contents := range []string{"v3", "v4", "v5"} {
+		want := commitFile(t, upstream, upstreamPath, "vms/foovm.yaml", contents)
+		commit, err := factory.GetRepository(upstreamPath, path, master, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, want, commit)
+		assertFile(t, path, "vms/foovm.yaml", contents)
+	}
+	commitFile(t, upstream, upstreamPath, "vms/foovm.yaml", "v6")
+	latest := commitFile(t, upstream, upstreamPath, "vms/foovm.yaml", "v7")
+	commit, err := factory.GetRepository(upstreamPath, path, master, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, latest, commit)
+	assertFile(t, path, "vms/foovm.yaml", "v7")
+}
+
+func TestRewritten(t *testing.T) {
+	path := t.TempDir()
+	repo, err := git.PlainInit(path, false)
+	assert.NoError(t, err)
+	base := commitFile(t, repo, path, "vms/foovm.yaml", "base")
+	first := commitFile(t, repo, path, "vms/foovm.yaml", "first")
+	second := commitFile(t, repo, path, "vms/foovm.yaml", "second")
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	assert.NoError(t, worktree.Reset(&git.ResetOptions{Commit: base, Mode: git.HardReset}))
+	dropped := commitFile(t, repo, path, "vms/foovm.yaml", "dropped")
+
+	assert.False(t, rewritten(repo, first, second))
+	assert.True(t, rewritten(repo, dropped, second))
+
+	// Once the history is cut off, a commit can't be known to be dropped.
+	assert.NoError(t, repo.Storer.SetShallow([]plumbing.Hash{first}))
+	assert.NoError(t, repo.Storer.(interface {
+		DeleteLooseObject(plumbing.Hash) error
+	}).DeleteLooseObject(base))
+	assert.False(t, rewritten(repo, dropped, second))
+}
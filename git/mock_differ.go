@@ -0,0 +1,53 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: git/differ.go
+
+// Package git is a generated GoMock package.
+package git
+
+import (
+	reflect "reflect"
+
+	plumbing "github.com/go-git/go-git/v5/plumbing"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockDiffer is a mock of Differ interface.
+type MockDiffer struct {
+	ctrl     *gomock.Controller
+	recorder *MockDifferMockRecorder
+}
+
+// MockDifferMockRecorder is the mock recorder for MockDiffer.
+type MockDifferMockRecorder struct {
+	mock *MockDiffer
+}
+
+// NewMockDiffer creates a new mock instance.
+func NewMockDiffer(ctrl *gomock.Controller) *MockDiffer {
+	mock := &MockDiffer{ctrl: ctrl}
+	mock.recorder = &MockDifferMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockDiffer) EXPECT() *MockDifferMockRecorder {
+	return m.recorder
+}
+
+// Diff mocks base method.
+func (m *MockDiffer) Diff(path string, from, to plumbing.Hash, dirs []string) ([]Change, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Diff", path, from, to, dirs)
+	ret0, _ := ret[0].([]Change)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Diff indicates an expected call of Diff.
+func (mr *MockDifferMockRecorder) Diff(path, from, to, dirs interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Diff", reflect.TypeOf((*MockDiffer)(nil).Diff), path, from, to, dirs)
+}
@@ -11,7 +11,7 @@ import (
 	reflect "reflect"
 
 	plumbing "github.com/go-git/go-git/v5/plumbing"
-	http "github.com/go-git/go-git/v5/plumbing/transport/http"
+	transport "github.com/go-git/go-git/v5/plumbing/transport"
 	gomock "go.uber.org/mock/gomock"
 )
 
@@ -39,7 +39,7 @@ func (m *MockFactory) EXPECT() *MockFactoryMockRecorder {
 }
 
 // GetRepository mocks base method.
-func (m *MockFactory) GetRepository(url, path string, reference plumbing.ReferenceName, auth *http.BasicAuth) (plumbing.Hash, error) {
+func (m *MockFactory) GetRepository(url, path string, reference Reference, auth transport.AuthMethod) (plumbing.Hash, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetRepository", url, path, reference, auth)
 	ret0, _ := ret[0].(plumbing.Hash)
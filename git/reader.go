@@ -0,0 +1,73 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package git
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+)
+
+var ErrCommitNotFound = errors.New("commit not found")
+
+// FileReader reads files from a repository as of a commit, without changing
+// what's checked out.
+type FileReader interface {
+	// ReadDir returns the contents of the files directly in [dir] of the
+	// repository at [path] as of [commit], keyed by file name. A directory
+	// that doesn't exist at [commit] has no files.
+	ReadDir(path string, commit plumbing.Hash, dir string) (map[string][]byte, error)
+}
+
+var _ FileReader = CommitFileReader{}
+
+type CommitFileReader struct{}
+
+func (CommitFileReader) ReadDir(path string, commit plumbing.Hash, dir string) (map[string][]byte, error) {
+	repo, err := git.PlainOpen(path)
+	if err != nil {
+		return nil, err
+	}
+
+	commitObject, err := repo.CommitObject(commit)
+	if errors.Is(err, plumbing.ErrObjectNotFound) {
+		return nil, fmt.Errorf("%w: %s in %s", ErrCommitNotFound, commit, path)
+	} else if err != nil {
+		return nil, err
+	}
+
+	tree, err := commitObject.Tree()
+	if err != nil {
+		return nil, err
+	}
+
+	subtree, err := tree.Tree(dir)
+	if errors.Is(err, object.ErrDirectoryNotFound) {
+		return map[string][]byte{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	files := map[string][]byte{}
+	for _, entry := range subtree.Entries {
+		if !entry.Mode.IsFile() {
+			continue
+		}
+
+		file, err := subtree.TreeEntryFile(&entry)
+		if err != nil {
+			return nil, err
+		}
+		contents, err := file.Contents()
+		if err != nil {
+			return nil, err
+		}
+		files[entry.Name] = []byte(contents)
+	}
+
+	return files, nil
+}
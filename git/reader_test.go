@@ -0,0 +1,62 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package git
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/go-git/go-git/v5"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/stretchr/testify/assert"
+)
+
+// commitFile writes [contents] to [name] in the repository at [path] and
+// commits it.
+func commitFile(t *testing.T, repo *git.Repository, path string, name string, contents string) plumbing.Hash {
+	assert.NoError(t, os.MkdirAll(filepath.Dir(filepath.Join(path, name)), perms.ReadWriteExecute))
+	assert.NoError(t, os.WriteFile(filepath.Join(path, name), []byte(contents), perms.ReadWrite))
+
+	worktree, err := repo.Worktree()
+	assert.NoError(t, err)
+	_, err = worktree.Add(name)
+	assert.NoError(t, err)
+
+	commit, err := worktree.Commit(name, &git.CommitOptions{
+		Author: &object.Signature{Name: "opm", Email: "opm@example.com", When: time.Now()},
+	})
+	assert.NoError(t, err)
+	return commit
+}
+
+func TestCommitFileReader(t *testing.T) {
+	path := t.TempDir()
+	repo, err := git.PlainInit(path, false)
+	assert.NoError(t, err)
+
+	first := commitFile(t, repo, path, "vms/foovm.yaml", "v1")
+	second := commitFile(t, repo, path, "vms/foovm.yaml", "v2")
+	commitFile(t, repo, path, "vms/nested/barvm.yaml", "v1")
+
+	reader := CommitFileReader{}
+
+	files, err := reader.ReadDir(path, first, "vms")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"foovm.yaml": []byte("v1")}, files)
+
+	files, err = reader.ReadDir(path, second, "vms")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string][]byte{"foovm.yaml": []byte("v2")}, files)
+
+	files, err = reader.ReadDir(path, first, "subnets")
+	assert.NoError(t, err)
+	assert.Empty(t, files)
+
+	_, err = reader.ReadDir(path, plumbing.NewHash("0123456789abcdef0123456789abcdef01234567"), "vms")
+	assert.ErrorIs(t, err, ErrCommitNotFound)
+}
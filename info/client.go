@@ -0,0 +1,67 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package info
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	infoapi "github.com/DioneProtocol/odysseygo/api/info"
+	"github.com/DioneProtocol/odysseygo/utils/rpc"
+	"github.com/DioneProtocol/odysseygo/version"
+
+	"github.com/DioneProtocol/opm/api"
+)
+
+// infoAPIPath is the path of the info API relative to the node's URI.
+const infoAPIPath = "/ext/info"
+
+var _ Client = &client{}
+
+// NodeVersion is the version information a node reports about itself.
+type NodeVersion struct {
+	// Version is the odysseygo version the node is running.
+	Version version.Semantic
+	// RPCProtocolVersion is the rpcchainvm protocol version the node speaks to
+	// VM plugins with.
+	RPCProtocolVersion uint32
+}
+
+type Client interface {
+	GetNodeVersion() (NodeVersion, error)
+}
+
+type client struct {
+	requester rpc.EndpointRequester
+}
+
+// NewClient returns a client for the info API of the node at [uri]
+// (e.g. http://127.0.0.1:9650) that sends requests through [apiClient].
+func NewClient(uri string, apiClient *api.Client) Client {
+	return &client{
+		requester: apiClient.Requester(strings.TrimSuffix(uri, "/") + infoAPIPath),
+	}
+}
+
+func (c *client) GetNodeVersion() (NodeVersion, error) {
+	reply := &infoapi.GetNodeVersionReply{}
+	if err := c.requester.SendRequest(context.Background(), "info.getNodeVersion", struct{}{}, reply); err != nil {
+		return NodeVersion{}, err
+	}
+
+	application, err := version.ParseApplication(reply.Version)
+	if err != nil {
+		return NodeVersion{}, fmt.Errorf("node reported an invalid version: %w", err)
+	}
+
+	return NodeVersion{
+		Version: version.Semantic{
+			Major: application.Major,
+			Minor: application.Minor,
+			Patch: application.Patch,
+		},
+		RPCProtocolVersion: uint32(reply.RPCProtocolVersion),
+	}, nil
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package info
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeInfoAPI serves the info API's getNodeVersion method with a canned reply.
+func fakeInfoAPI(t *testing.T, reply string) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, infoAPIPath, r.URL.Path)
+
+		request := struct {
+			Method string          `json:"method"`
+			ID     json.RawMessage `json:"id"`
+		}{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		assert.Equal(t, "info.getNodeVersion", request.Method)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"jsonrpc": "2.0", "id": ` + string(request.ID) + `, ` + reply + `}`))
+	}))
+}
+
+func TestClientGetNodeVersion(t *testing.T) {
+	tests := []struct {
+		name    string
+		reply   string
+		want    NodeVersion
+		wantErr bool
+	}{
+		{
+			name:  "success",
+			reply: `"result": {"version": "odyssey/1.10.10", "rpcProtocolVersion": "28"}`,
+			want: NodeVersion{
+				Version:            version.Semantic{Major: 1, Minor: 10, Patch: 10},
+				RPCProtocolVersion: 28,
+			},
+		},
+		{
+			name:    "invalid version",
+			reply:   `"result": {"version": "1.10.10", "rpcProtocolVersion": "28"}`,
+			wantErr: true,
+		},
+		{
+			name:    "rpc error",
+			reply:   `"error": {"code": -32000, "message": "oops"}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := fakeInfoAPI(t, test.reply)
+			defer server.Close()
+
+			got, err := NewClient(server.URL, nil).GetNodeVersion()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,63 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/afero"
+)
+
+const (
+	// ChainConfigFile is the name of a chain's config file.
+	ChainConfigFile = "config.json"
+	// ChainUpgradeFile is the name of a chain's network upgrade file.
+	ChainUpgradeFile = "upgrade.json"
+)
+
+var (
+	subnetConfigDir = "subnets"
+	chainConfigDir  = "chains"
+)
+
+// ConfigDir is the directory an odysseygo node reads its subnet and chain
+// configs from (e.g. ~/.odysseygo/configs).
+type ConfigDir struct {
+	fs   afero.Fs
+	path string
+}
+
+func NewConfigDir(fs afero.Fs, path string) *ConfigDir {
+	return &ConfigDir{
+		fs:   fs,
+		path: path,
+	}
+}
+
+// SubnetConfigPath returns the path the node reads the config for [subnetID]
+// from.
+func (c *ConfigDir) SubnetConfigPath(subnetID string) string {
+	return filepath.Join(c.path, subnetConfigDir, fmt.Sprintf("%s.json", subnetID))
+}
+
+// WriteSubnetConfig writes the config for [subnetID]. Only the keys in [config]
+// are written, so the node's defaults are used for the rest. If the node
+// already has a different config for the subnet, it's backed up before being
+// replaced.
+func (c *ConfigDir) WriteSubnetConfig(subnetID string, config map[string]interface{}) error {
+	configBytes, err := json.MarshalIndent(config, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return WriteFile(c.fs, c.SubnetConfigPath(subnetID), append(configBytes, '\n'))
+}
+
+// ChainConfigPath returns the path the node reads [file] for [chain] from.
+// [chain] may be either a chain ID or a chain alias.
+func (c *ConfigDir) ChainConfigPath(chain string, file string) string {
+	return filepath.Join(c.path, chainConfigDir, chain, file)
+}
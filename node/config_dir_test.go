@@ -0,0 +1,37 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"testing"
+
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteSubnetConfig(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	configDir := NewConfigDir(fs, "configs")
+
+	assert.NoError(t, configDir.WriteSubnetConfig("subnetID", map[string]interface{}{
+		"validatorOnly":                       true,
+		"gossipAcceptedFrontierValidatorSize": 5,
+		"consensusParameters": map[string]interface{}{
+			"maxItemProcessingTime": 120000000000,
+		},
+	}))
+
+	// Keys that aren't set, like proposerMinBlockDelay, aren't written so the
+	// node's defaults apply.
+	contents, err := afero.ReadFile(fs, configDir.SubnetConfigPath("subnetID"))
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "consensusParameters": {
+    "maxItemProcessingTime": 120000000000
+  },
+  "gossipAcceptedFrontierValidatorSize": 5,
+  "validatorOnly": true
+}
+`, string(contents))
+}
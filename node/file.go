@@ -0,0 +1,68 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/spf13/afero"
+
+	"github.com/DioneProtocol/opm/util"
+)
+
+const backupTimeFormat = "20060102T150405Z"
+
+// WriteFile writes [contents] to [path], creating any missing parent
+// directories. If a file with different contents already exists at [path], a
+// diff of the change is printed and the existing file is backed up alongside
+// it before it's overwritten.
+func WriteFile(fs afero.Fs, path string, contents []byte) error {
+	existing, err := afero.ReadFile(fs, path)
+	switch {
+	case err == nil:
+		if bytes.Equal(existing, contents) {
+			fmt.Printf("%s is already up-to-date.\n", path)
+			return nil
+		}
+
+		fmt.Printf("Updating %s:\n%s", path, util.Diff(string(existing), string(contents)))
+
+		backupPath, err := Backup(fs, path)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Backed up the previous version of %s to %s.\n", path, backupPath)
+	case errors.Is(err, os.ErrNotExist):
+		if err := fs.MkdirAll(filepath.Dir(path), perms.ReadWriteExecute); err != nil {
+			return err
+		}
+		fmt.Printf("Creating %s...\n", path)
+	default:
+		return err
+	}
+
+	return afero.WriteFile(fs, path, contents, perms.ReadWrite)
+}
+
+// Backup copies the file at [path] to a timestamped backup file in the same
+// directory and returns the path of the backup.
+func Backup(fs afero.Fs, path string) (string, error) {
+	contents, err := afero.ReadFile(fs, path)
+	if err != nil {
+		return "", err
+	}
+
+	backupPath := fmt.Sprintf("%s.%s.bak", path, time.Now().UTC().Format(backupTimeFormat))
+	if err := afero.WriteFile(fs, backupPath, contents, perms.ReadWrite); err != nil {
+		return "", err
+	}
+
+	return backupPath, nil
+}
@@ -0,0 +1,66 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteFile(t *testing.T) {
+	path := filepath.Join("configs", "subnets", "subnetID.json")
+
+	tests := []struct {
+		name        string
+		existing    []byte
+		contents    []byte
+		wantBackups int
+	}{
+		{
+			name:        "new file",
+			contents:    []byte("{}\n"),
+			wantBackups: 0,
+		},
+		{
+			name:        "unchanged file",
+			existing:    []byte("{}\n"),
+			contents:    []byte("{}\n"),
+			wantBackups: 0,
+		},
+		{
+			name:        "changed file",
+			existing:    []byte("{\"validatorOnly\": false}\n"),
+			contents:    []byte("{\"validatorOnly\": true}\n"),
+			wantBackups: 1,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if test.existing != nil {
+				assert.NoError(t, afero.WriteFile(fs, path, test.existing, perms.ReadWrite))
+			}
+
+			assert.NoError(t, WriteFile(fs, path, test.contents))
+
+			contents, err := afero.ReadFile(fs, path)
+			assert.NoError(t, err)
+			assert.Equal(t, test.contents, contents)
+
+			backups, err := afero.Glob(fs, path+".*.bak")
+			assert.NoError(t, err)
+			assert.Len(t, backups, test.wantBackups)
+			for _, backup := range backups {
+				contents, err := afero.ReadFile(fs, backup)
+				assert.NoError(t, err)
+				assert.Equal(t, test.existing, contents)
+			}
+		})
+	}
+}
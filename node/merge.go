@@ -0,0 +1,154 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// missing marks a key that isn't present in one side of a merge.
+type missing struct{}
+
+// MergeJSON performs a three-way merge of JSON documents. [base] is the
+// document [local] and [remote] were both derived from, and may be empty if
+// there's no common ancestor.
+//
+// Changes made on only one side are kept. If both sides changed the same value
+// differently, the local value is kept and the conflicting path is returned.
+// If the merge doesn't change anything, [local] is returned as-is so its
+// formatting is preserved.
+func MergeJSON(base, local, remote []byte) ([]byte, []string, error) {
+	baseValue, err := decodeJSON(base)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse base: %w", err)
+	}
+	localValue, err := decodeJSON(local)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse local: %w", err)
+	}
+	remoteValue, err := decodeJSON(remote)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse remote: %w", err)
+	}
+
+	conflicts := []string{}
+	merged := merge("", baseValue, localValue, remoteValue, &conflicts)
+	if reflect.DeepEqual(merged, localValue) {
+		return local, conflicts, nil
+	}
+	if reflect.DeepEqual(merged, remoteValue) {
+		return remote, conflicts, nil
+	}
+
+	mergedBytes, err := json.MarshalIndent(merged, "", "  ")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return append(mergedBytes, '\n'), conflicts, nil
+}
+
+// EqualJSON returns true if [a] and [b] are equivalent JSON documents,
+// ignoring formatting and key order.
+func EqualJSON(a, b []byte) bool {
+	aValue, err := decodeJSON(a)
+	if err != nil {
+		return false
+	}
+	bValue, err := decodeJSON(b)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(aValue, bValue)
+}
+
+func decodeJSON(document []byte) (interface{}, error) {
+	if len(bytes.TrimSpace(document)) == 0 {
+		return missing{}, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(document))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+func merge(path string, base, local, remote interface{}, conflicts *[]string) interface{} {
+	switch {
+	case reflect.DeepEqual(local, remote), reflect.DeepEqual(remote, base):
+		return local
+	case reflect.DeepEqual(local, base):
+		return remote
+	}
+
+	localObject, localOk := local.(map[string]interface{})
+	remoteObject, remoteOk := remote.(map[string]interface{})
+	if !localOk || !remoteOk {
+		*conflicts = append(*conflicts, pathOrRoot(path))
+		return local
+	}
+
+	// If both sides are objects, merge them key by key. A base that isn't an
+	// object is treated as if none of the keys existed.
+	baseObject, ok := base.(map[string]interface{})
+	if !ok {
+		baseObject = map[string]interface{}{}
+	}
+
+	keys := map[string]struct{}{}
+	for key := range localObject {
+		keys[key] = struct{}{}
+	}
+	for key := range remoteObject {
+		keys[key] = struct{}{}
+	}
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	merged := make(map[string]interface{}, len(sortedKeys))
+	for _, key := range sortedKeys {
+		value := merge(
+			fmt.Sprintf("%s.%s", path, key),
+			lookup(baseObject, key),
+			lookup(localObject, key),
+			lookup(remoteObject, key),
+			conflicts,
+		)
+		if _, ok := value.(missing); !ok {
+			merged[key] = value
+		}
+	}
+
+	return merged
+}
+
+func lookup(object map[string]interface{}, key string) interface{} {
+	value, ok := object[key]
+	if !ok {
+		return missing{}
+	}
+
+	return value
+}
+
+func pathOrRoot(path string) string {
+	if path == "" {
+		return "."
+	}
+
+	return path
+}
@@ -0,0 +1,103 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeJSON(t *testing.T) {
+	tests := []struct {
+		name          string
+		base          string
+		local         string
+		remote        string
+		want          string
+		wantConflicts []string
+		wantErr       bool
+	}{
+		{
+			name:   "unmodified locally",
+			base:   `{"a": 1}`,
+			local:  `{"a": 1}`,
+			remote: `{"a": 2}`,
+			want:   `{"a": 2}`,
+		},
+		{
+			name:   "unchanged remotely keeps local formatting",
+			base:   `{"a": 1}`,
+			local:  "{\n    \"a\": 3\n}",
+			remote: `{"a": 1}`,
+			want:   "{\n    \"a\": 3\n}",
+		},
+		{
+			name:   "independent changes",
+			base:   `{"a": 1, "b": 1}`,
+			local:  `{"a": 2, "b": 1}`,
+			remote: `{"a": 1, "b": 1, "c": 3}`,
+			want:   "{\n  \"a\": 2,\n  \"b\": 1,\n  \"c\": 3\n}\n",
+		},
+		{
+			name:   "key removed remotely",
+			base:   `{"a": 1, "b": 1}`,
+			local:  `{"a": 2, "b": 1}`,
+			remote: `{"a": 1}`,
+			want:   "{\n  \"a\": 2\n}\n",
+		},
+		{
+			name:   "nested changes",
+			base:   `{"a": {"b": 1, "c": 1}}`,
+			local:  `{"a": {"b": 2, "c": 1}}`,
+			remote: `{"a": {"b": 1, "c": 2}}`,
+			want:   "{\n  \"a\": {\n    \"b\": 2,\n    \"c\": 2\n  }\n}\n",
+		},
+		{
+			name:          "conflict keeps local",
+			base:          `{"a": 1, "b": 1}`,
+			local:         `{"a": 2, "b": 1}`,
+			remote:        `{"a": 3, "b": 2}`,
+			want:          "{\n  \"a\": 2,\n  \"b\": 2\n}\n",
+			wantConflicts: []string{".a"},
+		},
+		{
+			name:   "no base",
+			local:  `{"a": 1}`,
+			remote: `{"b": 2}`,
+			want:   "{\n  \"a\": 1,\n  \"b\": 2\n}\n",
+		},
+		{
+			name:    "invalid local",
+			base:    `{}`,
+			local:   `{`,
+			remote:  `{}`,
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			merged, conflicts, err := MergeJSON([]byte(test.base), []byte(test.local), []byte(test.remote))
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, string(merged))
+			if test.wantConflicts == nil {
+				assert.Empty(t, conflicts)
+			} else {
+				assert.Equal(t, test.wantConflicts, conflicts)
+			}
+		})
+	}
+}
+
+func TestEqualJSON(t *testing.T) {
+	assert.True(t, EqualJSON([]byte(`{"a": 1, "b": 2}`), []byte("{\n  \"b\": 2,\n  \"a\": 1\n}")))
+	assert.False(t, EqualJSON([]byte(`{"a": 1}`), []byte(`{"a": 2}`)))
+	assert.False(t, EqualJSON([]byte(`{`), []byte(`{`)))
+}
@@ -0,0 +1,371 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+)
+
+// TrackSubnetsKey is the node config key listing the subnets a node validates.
+const TrackSubnetsKey = "track-subnets"
+
+var (
+	errNotObject = errors.New("expected the node config to be an object")
+	errNotString = fmt.Errorf("expected %s to be a comma-separated string", TrackSubnetsKey)
+)
+
+// NodeConfig is an odysseygo node config file (e.g.
+// ~/.odysseygo/configs/node.json). Files ending in .yaml or .yml are treated
+// as YAML, everything else as JSON.
+//
+// Edits only touch the values they change, so the rest of the file keeps its
+// formatting. YAML comments are preserved.
+type NodeConfig struct {
+	fs   afero.Fs
+	path string
+}
+
+func NewNodeConfig(fs afero.Fs, path string) *NodeConfig {
+	return &NodeConfig{
+		fs:   fs,
+		path: path,
+	}
+}
+
+// Path returns the path of the node config file.
+func (n *NodeConfig) Path() string {
+	return n.path
+}
+
+// TrackedSubnets returns the subnet IDs in the node's track-subnets list.
+func (n *NodeConfig) TrackedSubnets() ([]string, error) {
+	contents, err := n.read()
+	if err != nil {
+		return nil, err
+	}
+
+	value, _, err := n.editor().get(contents)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", n.path, err)
+	}
+
+	return splitSubnets(value), nil
+}
+
+// TrackSubnet adds [subnetID] to the node's track-subnets list. Returns false
+// if the subnet was already tracked.
+func (n *NodeConfig) TrackSubnet(subnetID string) (bool, error) {
+	if _, err := ids.FromString(subnetID); err != nil {
+		return false, fmt.Errorf("invalid subnet ID %s: %w", subnetID, err)
+	}
+
+	return n.update(func(subnets []string) []string {
+		for _, subnet := range subnets {
+			if subnet == subnetID {
+				return subnets
+			}
+		}
+
+		return append(subnets, subnetID)
+	})
+}
+
+// UntrackSubnet removes [subnetID] from the node's track-subnets list. Returns
+// false if the subnet wasn't tracked.
+func (n *NodeConfig) UntrackSubnet(subnetID string) (bool, error) {
+	return n.update(func(subnets []string) []string {
+		result := make([]string, 0, len(subnets))
+		for _, subnet := range subnets {
+			if subnet != subnetID {
+				result = append(result, subnet)
+			}
+		}
+
+		return result
+	})
+}
+
+func (n *NodeConfig) update(f func([]string) []string) (bool, error) {
+	contents, err := n.read()
+	if err != nil {
+		return false, err
+	}
+
+	editor := n.editor()
+	value, _, err := editor.get(contents)
+	if err != nil {
+		return false, fmt.Errorf("failed to parse %s: %w", n.path, err)
+	}
+
+	before := splitSubnets(value)
+	after := f(before)
+	if strings.Join(before, ",") == strings.Join(after, ",") {
+		return false, nil
+	}
+
+	updated, err := editor.set(contents, strings.Join(after, ","))
+	if err != nil {
+		return false, fmt.Errorf("failed to update %s: %w", n.path, err)
+	}
+
+	return true, WriteFile(n.fs, n.path, updated)
+}
+
+func (n *NodeConfig) read() ([]byte, error) {
+	contents, err := afero.ReadFile(n.fs, n.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+
+	return contents, err
+}
+
+func (n *NodeConfig) editor() configEditor {
+	switch strings.ToLower(filepath.Ext(n.path)) {
+	case ".yaml", ".yml":
+		return yamlEditor{}
+	default:
+		return jsonEditor{}
+	}
+}
+
+// splitSubnets parses a comma-separated list of subnet IDs.
+func splitSubnets(value string) []string {
+	subnets := []string{}
+	for _, subnet := range strings.Split(value, ",") {
+		if subnet = strings.TrimSpace(subnet); subnet != "" {
+			subnets = append(subnets, subnet)
+		}
+	}
+
+	return subnets
+}
+
+// configEditor reads and writes the track-subnets value of a node config file
+// in a specific format.
+type configEditor interface {
+	// get returns the track-subnets value and whether it was set.
+	get(contents []byte) (string, bool, error)
+	// set returns [contents] with the track-subnets value replaced by [value].
+	set(contents []byte, value string) ([]byte, error)
+}
+
+type jsonEditor struct{}
+
+// jsonMember is the location of a top-level member of a JSON object.
+type jsonMember struct {
+	key        string
+	value      json.RawMessage
+	valueStart int
+	valueEnd   int
+}
+
+func (jsonEditor) get(contents []byte) (string, bool, error) {
+	members, err := parseJSONMembers(contents)
+	if err != nil {
+		return "", false, err
+	}
+
+	for _, member := range members {
+		if member.key != TrackSubnetsKey {
+			continue
+		}
+
+		var value string
+		if err := json.Unmarshal(member.value, &value); err != nil {
+			return "", false, errNotString
+		}
+		return value, true, nil
+	}
+
+	return "", false, nil
+}
+
+func (jsonEditor) set(contents []byte, value string) ([]byte, error) {
+	members, err := parseJSONMembers(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(members) == 0 {
+		// Nothing worth preserving, so write out a fresh config.
+		config, err := json.MarshalIndent(map[string]string{TrackSubnetsKey: value}, "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(config, '\n'), nil
+	}
+
+	result := &bytes.Buffer{}
+	for _, member := range members {
+		if member.key != TrackSubnetsKey {
+			continue
+		}
+
+		result.Write(contents[:member.valueStart])
+		result.Write(valueBytes)
+		result.Write(contents[member.valueEnd:])
+		return result.Bytes(), nil
+	}
+
+	// The key isn't set yet, so add it after the last member using the same
+	// indentation as the first one.
+	first, last := members[0], members[len(members)-1]
+	separator := " "
+	keyStart := bytes.LastIndexByte(contents[:first.valueStart], '"')
+	keyStart = bytes.LastIndexByte(contents[:keyStart], '"')
+	if lineStart := bytes.LastIndexByte(contents[:keyStart], '\n'); lineStart >= 0 {
+		separator = string(contents[lineStart:keyStart])
+	}
+
+	result.Write(contents[:last.valueEnd])
+	fmt.Fprintf(result, ",%s%q: %s", separator, TrackSubnetsKey, valueBytes)
+	result.Write(contents[last.valueEnd:])
+	return result.Bytes(), nil
+}
+
+// parseJSONMembers returns the top-level members of the JSON object in
+// [contents] along with where their values are located.
+func parseJSONMembers(contents []byte) ([]jsonMember, error) {
+	if len(bytes.TrimSpace(contents)) == 0 {
+		return nil, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(contents))
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+	if token != json.Delim('{') {
+		return nil, errNotObject
+	}
+
+	members := []jsonMember{}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return nil, errNotObject
+		}
+
+		var value json.RawMessage
+		if err := decoder.Decode(&value); err != nil {
+			return nil, err
+		}
+
+		valueEnd := int(decoder.InputOffset())
+		members = append(members, jsonMember{
+			key:        key,
+			value:      value,
+			valueStart: valueEnd - len(value),
+			valueEnd:   valueEnd,
+		})
+	}
+
+	if _, err := decoder.Token(); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+type yamlEditor struct{}
+
+func (yamlEditor) get(contents []byte) (string, bool, error) {
+	_, mapping, err := parseYAML(contents)
+	if err != nil {
+		return "", false, err
+	}
+
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != TrackSubnetsKey {
+			continue
+		}
+
+		value := mapping.Content[i+1]
+		if value.Kind != yaml.ScalarNode {
+			return "", false, errNotString
+		}
+		return value.Value, true, nil
+	}
+
+	return "", false, nil
+}
+
+func (yamlEditor) set(contents []byte, value string) ([]byte, error) {
+	document, mapping, err := parseYAML(contents)
+	if err != nil {
+		return nil, err
+	}
+
+	found := false
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == TrackSubnetsKey {
+			mapping.Content[i+1].SetString(value)
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		valueNode := &yaml.Node{}
+		valueNode.SetString(value)
+		mapping.Content = append(
+			mapping.Content,
+			&yaml.Node{Kind: yaml.ScalarNode, Value: TrackSubnetsKey},
+			valueNode,
+		)
+	}
+
+	result := &bytes.Buffer{}
+	encoder := yaml.NewEncoder(result)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(document); err != nil {
+		return nil, err
+	}
+	if err := encoder.Close(); err != nil {
+		return nil, err
+	}
+
+	return result.Bytes(), nil
+}
+
+// parseYAML returns the document node of [contents] and its top-level mapping.
+func parseYAML(contents []byte) (*yaml.Node, *yaml.Node, error) {
+	document := &yaml.Node{}
+	if err := yaml.Unmarshal(contents, document); err != nil {
+		return nil, nil, err
+	}
+
+	if document.Kind == 0 {
+		// Empty file.
+		mapping := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		document = &yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{mapping}}
+		return document, mapping, nil
+	}
+
+	if len(document.Content) != 1 || document.Content[0].Kind != yaml.MappingNode {
+		return nil, nil, errNotObject
+	}
+
+	return document, document.Content[0], nil
+}
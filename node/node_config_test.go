@@ -0,0 +1,157 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package node
+
+import (
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	subnetA = "2bRCr6B4MiEfSjidDwxDpdCyviwnfUVqB2HGwhm947w9YYqb7r"
+	subnetB = "Vn3aX6hNRstj5VHHm63TCgPNaeGnRSqCYXQqemSqDd2TQH4qJ"
+)
+
+func TestNodeConfigTrackSubnet(t *testing.T) {
+	tests := []struct {
+		name      string
+		path      string
+		existing  *string
+		subnetID  string
+		want      string
+		wantAdded bool
+		wantErr   bool
+	}{
+		{
+			name:      "missing file",
+			path:      "node.json",
+			subnetID:  subnetA,
+			want:      "{\n  \"track-subnets\": \"" + subnetA + "\"\n}\n",
+			wantAdded: true,
+		},
+		{
+			name:      "key not set",
+			path:      "node.json",
+			existing:  ptr("{\n    \"log-level\": \"info\",\n    \"http-port\": 9650\n}\n"),
+			subnetID:  subnetA,
+			want:      "{\n    \"log-level\": \"info\",\n    \"http-port\": 9650,\n    \"track-subnets\": \"" + subnetA + "\"\n}\n",
+			wantAdded: true,
+		},
+		{
+			name:      "single line",
+			path:      "node.json",
+			existing:  ptr(`{"log-level": "info"}`),
+			subnetID:  subnetA,
+			want:      `{"log-level": "info", "track-subnets": "` + subnetA + `"}`,
+			wantAdded: true,
+		},
+		{
+			name:      "appended to existing subnets",
+			path:      "node.json",
+			existing:  ptr("{\n  \"track-subnets\" : \"" + subnetB + "\",\n  \"http-port\": 9650\n}\n"),
+			subnetID:  subnetA,
+			want:      "{\n  \"track-subnets\" : \"" + subnetB + "," + subnetA + "\",\n  \"http-port\": 9650\n}\n",
+			wantAdded: true,
+		},
+		{
+			name:     "already tracked",
+			path:     "node.json",
+			existing: ptr(`{"track-subnets": "` + subnetB + `, ` + subnetA + `"}`),
+			subnetID: subnetA,
+			want:     `{"track-subnets": "` + subnetB + `, ` + subnetA + `"}`,
+		},
+		{
+			name:      "yaml keeps comments",
+			path:      "node.yaml",
+			existing:  ptr("# logging\nlog-level: info\ntrack-subnets: " + subnetB + " # validated subnets\n"),
+			subnetID:  subnetA,
+			want:      "# logging\nlog-level: info\ntrack-subnets: " + subnetB + "," + subnetA + " # validated subnets\n",
+			wantAdded: true,
+		},
+		{
+			name:      "yaml key not set",
+			path:      "node.yml",
+			existing:  ptr("log-level: info\n"),
+			subnetID:  subnetA,
+			want:      "log-level: info\ntrack-subnets: " + subnetA + "\n",
+			wantAdded: true,
+		},
+		{
+			name:     "invalid subnet ID",
+			path:     "node.json",
+			subnetID: "foobar",
+			wantErr:  true,
+		},
+		{
+			name:     "not a string",
+			path:     "node.json",
+			existing: ptr(`{"track-subnets": ["` + subnetB + `"]}`),
+			subnetID: subnetA,
+			wantErr:  true,
+		},
+		{
+			name:     "not an object",
+			path:     "node.json",
+			existing: ptr(`[]`),
+			subnetID: subnetA,
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			if test.existing != nil {
+				assert.NoError(t, afero.WriteFile(fs, test.path, []byte(*test.existing), perms.ReadWrite))
+			}
+
+			added, err := NewNodeConfig(fs, test.path).TrackSubnet(test.subnetID)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantAdded, added)
+
+			contents, err := afero.ReadFile(fs, test.path)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, string(contents))
+		})
+	}
+}
+
+func TestNodeConfigUntrackSubnet(t *testing.T) {
+	fs := afero.NewMemMapFs()
+	assert.NoError(t, afero.WriteFile(fs, "node.json", []byte(`{"track-subnets": "`+subnetB+`,`+subnetA+`"}`), perms.ReadWrite))
+
+	nodeConfig := NewNodeConfig(fs, "node.json")
+
+	removed, err := nodeConfig.UntrackSubnet(subnetA)
+	assert.NoError(t, err)
+	assert.True(t, removed)
+
+	subnets, err := nodeConfig.TrackedSubnets()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{subnetB}, subnets)
+
+	removed, err = nodeConfig.UntrackSubnet(subnetA)
+	assert.NoError(t, err)
+	assert.False(t, removed)
+
+	removed, err = nodeConfig.UntrackSubnet(subnetB)
+	assert.NoError(t, err)
+	assert.True(t, removed)
+
+	contents, err := afero.ReadFile(fs, "node.json")
+	assert.NoError(t, err)
+	assert.Equal(t, `{"track-subnets": ""}`, string(contents))
+}
+
+func ptr(s string) *string {
+	return &s
+}
@@ -0,0 +1,172 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package opm
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/DioneProtocol/opm/config"
+	"github.com/DioneProtocol/opm/workflow"
+)
+
+// Apply converges the tracked repositories, installed VMs and joined subnets
+// on the manifest at [path]. If [lockPath] is set, VMs are installed exactly as
+// recorded in that lockfile. The plan is printed before any changes are made,
+// and nothing is changed if [dryRun] is set. If [force] is set, VMs are
+// installed even if they're incompatible with the node.
+func (a *OPM) Apply(path string, lockPath string, dryRun bool, force bool) error {
+	manifest, err := config.ReadManifest(a.fs, path)
+	if err != nil {
+		return err
+	}
+
+	var lockfile *config.Lockfile
+	if lockPath != "" {
+		l, err := config.ReadLockfile(a.fs, lockPath)
+		if err != nil {
+			return err
+		}
+		lockfile = &l
+	}
+
+	p, err := a.plan(manifest, lockfile)
+	if err != nil {
+		return err
+	}
+
+	if len(p.steps) == 0 && !p.sync {
+		fmt.Printf("Nothing to do. Already matches %s.\n", path)
+		return nil
+	}
+
+	fmt.Printf("Plan to converge on %s:\n", path)
+	if err := p.print(os.Stdout); err != nil {
+		return err
+	}
+	if p.sync {
+		fmt.Println("Repositories will be synced before pending steps are resolved.")
+	}
+
+	if dryRun {
+		return nil
+	}
+
+	checker := a.compatibilityChecker(force)
+	loaded := []string{}
+
+	if err := a.applySteps(p.steps, lockfile, checker, &loaded); err != nil {
+		return err
+	}
+
+	if p.sync {
+		fmt.Println("Syncing repositories...")
+		if err := a.Update(); err != nil {
+			return err
+		}
+
+		// Now that the definitions are synced, resolve the rest of the plan.
+		p, err = a.plan(manifest, lockfile)
+		if err != nil {
+			return err
+		}
+
+		if len(p.steps) > 0 {
+			fmt.Println("Remaining plan after syncing repositories:")
+			if err := p.print(os.Stdout); err != nil {
+				return err
+			}
+			if err := a.applySteps(p.steps, lockfile, checker, &loaded); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(loaded) > 0 {
+		vms := make(map[string]string, len(loaded))
+		for _, name := range loaded {
+			installInfo, err := a.installedVMs.Get([]byte(name))
+			if err != nil {
+				return err
+			}
+			vms[name] = installInfo.ID
+		}
+
+		if err := a.loadVMIDs(vms); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Finished applying %s.\n", path)
+	return nil
+}
+
+// applySteps applies every step in [steps] that isn't pending. VMs that were
+// installed or upgraded are appended to [loaded] so the node can be asked to
+// load them once everything is applied.
+func (a *OPM) applySteps(steps []planStep, lockfile *config.Lockfile, checker workflow.CompatibilityChecker, loaded *[]string) error {
+	for _, step := range steps {
+		if step.pending {
+			continue
+		}
+
+		var err error
+		switch step.action {
+		case actionUninstall:
+			err = a.uninstall(step.name)
+		case actionLeave:
+			err = a.leaveSubnet(step.name)
+		case actionRemoveRepository:
+			err = a.RemoveRepository(step.name, false)
+		case actionReplaceRepository:
+			err = a.replaceRepository(step.repository)
+		case actionAddRepository:
+			err = a.addSource(step.repository.SourceInfo())
+		case actionInstall:
+			var installed bool
+			installed, err = a.installsBinary(step.name, func() error {
+				if step.locked != nil {
+					return a.installLocked(*lockfile, *step.locked, checker)
+				}
+				return a.install(step.name, checker)
+			})
+			if installed {
+				*loaded = append(*loaded, step.name)
+			}
+		case actionUpgrade:
+			var installed bool
+			installed, err = a.installsBinary(step.name, func() error {
+				if err := a.upgradeVM(step.name, checker); !errors.Is(err, workflow.ErrAlreadyUpdated) {
+					return err
+				}
+				return nil
+			})
+			if installed {
+				*loaded = append(*loaded, step.name)
+			}
+		case actionJoin:
+			err = a.joinSubnet(step.name, checker)
+		default:
+			err = fmt.Errorf("unknown plan action %s", step.action)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to %s %s: %w", step.action, step.name, err)
+		}
+	}
+
+	return nil
+}
+
+// replaceRepository re-registers a repository whose url, source or reference
+// changed.
+// Removing the repository deletes its local clone, so that it's cloned again
+// from the new source.
+func (a *OPM) replaceRepository(repository config.ManifestRepository) error {
+	if err := a.RemoveRepository(repository.Alias, false); err != nil {
+		return err
+	}
+
+	return a.addSource(repository.SourceInfo())
+}
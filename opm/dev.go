@@ -0,0 +1,176 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package opm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/DioneProtocol/opm/source"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/util"
+	"github.com/DioneProtocol/opm/workflow"
+)
+
+// link is a linked repository, along with the version of the definitions in
+// its working tree.
+type link struct {
+	alias   string
+	version plumbing.Hash
+}
+
+// Link tracks the working tree at [path] as the local repository [alias], and
+// syncs its definitions.
+func (a *OPM) Link(alias string, path string) error {
+	if err := a.AddLocalRepository(alias, path); err != nil {
+		return err
+	}
+
+	return a.update(alias)
+}
+
+// Watch syncs local repositories whenever the definitions in their working
+// tree change, until [ctx] is done. If [install] is set, installed VMs whose
+// artifact changed are reinstalled and loaded into the node. If [force] is
+// set, they're reinstalled even if they're incompatible with the node.
+func (a *OPM) Watch(ctx context.Context, interval time.Duration, install bool, force bool) error {
+	if interval <= 0 {
+		return fmt.Errorf("the interval must be positive, got %s", interval)
+	}
+
+	checker := a.compatibilityChecker(force)
+
+	// failed is the version of each repository that failed to sync, so a
+	// broken definition is only reported once.
+	failed := map[string]plumbing.Hash{}
+
+	fmt.Printf("Watching local repositories for changes. Press Ctrl+C to stop.\n")
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		changed, err := a.changedLinks()
+		if err != nil {
+			return err
+		}
+
+		for _, link := range changed {
+			if version, ok := failed[link.alias]; ok && version == link.version {
+				continue
+			}
+
+			fmt.Printf("Detected changes in %s. Syncing...\n", link.alias)
+			if err := a.update(link.alias); err != nil {
+				fmt.Printf("Failed to sync %s: %s. Waiting for the next change.\n", link.alias, err)
+				failed[link.alias] = link.version
+				continue
+			}
+			delete(failed, link.alias)
+
+			if !install {
+				continue
+			}
+			if err := a.reinstall(link.alias, checker); err != nil {
+				fmt.Printf("Failed to reinstall the VMs of %s: %s.\n", link.alias, err)
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			fmt.Printf("Stopped watching.\n")
+			return nil
+		case <-ticker.C:
+		}
+	}
+}
+
+// changedLinks returns the local repositories whose definitions changed since
+// they were last synced.
+func (a *OPM) changedLinks() ([]link, error) {
+	itr := a.sourcesList.Iterator()
+	defer itr.Release()
+
+	changed := []link{}
+	for itr.Next() {
+		sourceInfo, err := itr.Value()
+		if err != nil {
+			return nil, err
+		}
+		if sourceInfo.Source != storage.LocalSource {
+			continue
+		}
+
+		version, err := source.Checksum(a.fs, sourceInfo.URL)
+		if err != nil {
+			return nil, err
+		}
+		if version != sourceInfo.Commit {
+			changed = append(changed, link{alias: string(itr.Key()), version: version})
+		}
+	}
+
+	return changed, itr.Error()
+}
+
+// reinstall reinstalls the VMs installed from the repository [alias] whose
+// artifact changed, and loads them into the node.
+func (a *OPM) reinstall(alias string, checker workflow.CompatibilityChecker) error {
+	affected, err := a.affectedVMs(alias)
+	if err != nil {
+		return err
+	}
+	if len(affected) == 0 {
+		return nil
+	}
+
+	for _, name := range affected {
+		if err := a.upgradeOrReinstallVM(name, checker, true); err != nil && !errors.Is(err, workflow.ErrAlreadyUpdated) {
+			return err
+		}
+	}
+
+	return a.loadVMs(affected...)
+}
+
+// affectedVMs returns the VMs installed from the repository [alias] whose
+// definition no longer matches the installed artifact. VMs that are no longer
+// defined are left as they are.
+func (a *OPM) affectedVMs(alias string) ([]string, error) {
+	repository := a.repoFactory.GetRepository([]byte(alias))
+
+	itr := a.installedVMs.Iterator()
+	defer itr.Release()
+
+	affected := []string{}
+	for itr.Next() {
+		name := string(itr.Key())
+		repoAlias, plugin := util.ParseQualifiedName(name)
+		if repoAlias != alias {
+			continue
+		}
+
+		installInfo, err := itr.Value()
+		if err != nil {
+			return nil, err
+		}
+
+		definition, err := repository.VMs.Get([]byte(plugin))
+		if err == database.ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		if !installInfo.Installs(definition.Definition) {
+			affected = append(affected, name)
+		}
+	}
+
+	return affected, itr.Error()
+}
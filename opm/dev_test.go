@@ -0,0 +1,88 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package opm
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/source"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestChangedLinks(t *testing.T) {
+	const (
+		syncedDir  = "/synced"
+		changedDir = "/changed"
+	)
+
+	fs := afero.NewMemMapFs()
+	a := &OPM{
+		sourcesList: storage.NewSourceInfo(memdb.New()),
+		fs:          fs,
+	}
+
+	assert.NoError(t, afero.WriteFile(fs, filepath.Join(syncedDir, "vms", "foovm.yaml"), []byte("foovm"), perms.ReadWrite))
+	assert.NoError(t, afero.WriteFile(fs, filepath.Join(changedDir, "vms", "barvm.yaml"), []byte("barvm"), perms.ReadWrite))
+	synced, err := source.Checksum(fs, syncedDir)
+	assert.NoError(t, err)
+	changed, err := source.Checksum(fs, changedDir)
+	assert.NoError(t, err)
+
+	for _, sourceInfo := range []storage.SourceInfo{
+		{Alias: "organization/synced", URL: syncedDir, Commit: synced, Source: storage.LocalSource},
+		{Alias: "organization/changed", URL: changedDir, Commit: syncedCommit, Source: storage.LocalSource},
+		{Alias: repoAlias, URL: repoURL, Commit: syncedCommit, Source: storage.GitSource},
+	} {
+		assert.NoError(t, a.sourcesList.Put([]byte(sourceInfo.Alias), sourceInfo))
+	}
+
+	links, err := a.changedLinks()
+	assert.NoError(t, err)
+	assert.Equal(t, []link{{alias: "organization/changed", version: changed}}, links)
+}
+
+func TestAffectedVMs(t *testing.T) {
+	db := memdb.New()
+	a := &OPM{
+		installedVMs: storage.NewInstalledVMs(db),
+		repoFactory:  storage.NewRepositoryFactory(db),
+	}
+
+	v1 := version.Semantic{Major: 1}
+	repository := a.repoFactory.GetRepository([]byte(repoAlias))
+	for _, vm := range []types.VM{
+		{Alias: "unchanged", Version: v1, URL: "url", SHA256: "sha256"},
+		{Alias: "rebuilt", Version: v1, URL: "url", SHA256: "rebuilt"},
+		{Alias: "downgraded", Version: version.Semantic{Minor: 9}, URL: "url", SHA256: "sha256"},
+	} {
+		assert.NoError(t, repository.VMs.Put([]byte(vm.Alias), storage.Definition[types.VM]{Definition: vm}))
+	}
+
+	installed := storage.InstallInfo{Version: v1, URL: "url", SHA256: "sha256"}
+	for _, name := range []string{"unchanged", "rebuilt", "downgraded", "removed"} {
+		assert.NoError(t, a.installedVMs.Put([]byte(repoAlias+":"+name), installed))
+	}
+	assert.NoError(t, a.installedVMs.Put([]byte("organization/other:rebuilt"), installed))
+
+	affected, err := a.affectedVMs(repoAlias)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{repoAlias + ":downgraded", repoAlias + ":rebuilt"}, affected)
+}
+
+func TestWatchInvalidInterval(t *testing.T) {
+	a := &OPM{}
+	for _, interval := range []time.Duration{0, -time.Second} {
+		assert.Error(t, a.Watch(context.Background(), interval, false, false))
+	}
+}
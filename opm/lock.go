@@ -0,0 +1,91 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package opm
+
+import (
+	"fmt"
+
+	"github.com/DioneProtocol/opm/config"
+	"github.com/DioneProtocol/opm/util"
+	"github.com/DioneProtocol/opm/workflow"
+)
+
+// Freeze writes a lockfile to [path] recording every installed VM.
+func (a *OPM) Freeze(path string) error {
+	return a.executor.Execute(workflow.NewFreeze(workflow.FreezeConfig{
+		Path:             path,
+		InstalledVMs:     a.installedVMs,
+		SourcesList:      a.sourcesList,
+		RepositoriesPath: a.repositoriesPath,
+		Reader:           a.reader,
+		Fs:               a.fs,
+	}))
+}
+
+// InstallLocked installs exactly the VMs recorded in the lockfile at [path].
+// If [force] is set, VMs are installed even if they're incompatible with the
+// node.
+func (a *OPM) InstallLocked(path string, force bool) error {
+	lockfile, err := config.ReadLockfile(a.fs, path)
+	if err != nil {
+		return err
+	}
+
+	checker := a.compatibilityChecker(force)
+	locked := make(map[string]struct{}, len(lockfile.VMs))
+	vms := make(map[string]string, len(lockfile.VMs))
+	for _, vm := range lockfile.VMs {
+		locked[vm.Name] = struct{}{}
+		installed, err := a.installsBinary(vm.Name, func() error {
+			return a.installLocked(lockfile, vm, checker)
+		})
+		if err != nil {
+			return err
+		}
+		if installed {
+			vms[vm.Name] = vm.ID
+		}
+	}
+
+	installed, err := readAll(a.installedVMs)
+	if err != nil {
+		return err
+	}
+	for _, name := range sortedKeys(installed) {
+		if _, ok := locked[name]; !ok {
+			fmt.Printf("Warning - %s is installed but isn't in %s. Uninstall it to match the lockfile.\n", name, path)
+		}
+	}
+
+	if len(locked) == 0 {
+		fmt.Printf("No virtual machines are locked in %s.\n", path)
+		return nil
+	}
+	if len(vms) == 0 {
+		return nil
+	}
+
+	return a.loadVMIDs(vms)
+}
+
+func (a *OPM) installLocked(lockfile config.Lockfile, vm config.LockedVM, checker workflow.CompatibilityChecker) error {
+	repoAlias, _ := util.ParseQualifiedName(vm.Name)
+	repository, _ := lockfile.Repository(repoAlias)
+
+	return a.executor.Execute(workflow.NewInstallLocked(workflow.InstallLockedConfig{
+		Executor:         a.executor,
+		Locked:           vm,
+		Repository:       repository,
+		Definitions:      a.repoFactory.GetRepository([]byte(repoAlias)),
+		SourcesList:      a.sourcesList,
+		InstalledVMs:     a.installedVMs,
+		RepositoriesPath: a.repositoriesPath,
+		TmpPath:          a.tmpPath,
+		PluginPath:       a.pluginPath,
+		Reader:           a.reader,
+		Installer:        a.installer,
+		Checker:          checker,
+		Fs:               a.fs,
+	}))
+}
@@ -0,0 +1,61 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package opm
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/database/leveldb"
+	"github.com/DioneProtocol/odysseygo/utils/logging"
+	"github.com/prometheus/client_golang/prometheus"
+
+	"github.com/DioneProtocol/opm/storage"
+)
+
+// backupBatchSize is the size at which writes to a backup are flushed.
+const backupBatchSize = 4 * 1024 * 1024
+
+// migrate upgrades [db] to the latest schema. The database is copied to a new
+// database under [backupsPath] before any migration runs, so that it can be
+// restored by moving the copy into place.
+func migrate(db database.Database, backupsPath string) error {
+	return storage.Migrate(db, storage.Migrations, func(version uint64) error {
+		path := filepath.Join(backupsPath, fmt.Sprintf("%s-v%d-%d", dbDir, version, time.Now().Unix()))
+		fmt.Printf("Backing up the database to %s before migrating...\n", path)
+		return backupDB(db, path)
+	})
+}
+
+// backupDB copies every record in [db] to a new database at [path].
+func backupDB(db database.Database, path string) error {
+	backup, err := leveldb.New(path, []byte{}, logging.NoLog{}, metricsNamespace+"_backup", prometheus.NewRegistry())
+	if err != nil {
+		return err
+	}
+	defer backup.Close()
+
+	itr := db.NewIterator()
+	defer itr.Release()
+
+	batch := backup.NewBatch()
+	for itr.Next() {
+		if err := batch.Put(itr.Key(), itr.Value()); err != nil {
+			return err
+		}
+		if batch.Size() > backupBatchSize {
+			if err := batch.Write(); err != nil {
+				return err
+			}
+			batch.Reset()
+		}
+	}
+	if err := itr.Error(); err != nil {
+		return err
+	}
+
+	return batch.Write()
+}
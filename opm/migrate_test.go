@@ -0,0 +1,57 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package opm
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/database/leveldb"
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/utils/logging"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/storage"
+)
+
+func TestMigrateBacksUpDatabase(t *testing.T) {
+	db := memdb.New()
+	assert.NoError(t, db.Put([]byte("foo"), []byte("bar")))
+
+	backupsPath := t.TempDir()
+	assert.NoError(t, migrate(db, backupsPath))
+
+	version, err := storage.GetSchemaVersion(db)
+	assert.NoError(t, err)
+	assert.Equal(t, storage.SchemaVersion(), version)
+
+	backups, err := filepath.Glob(filepath.Join(backupsPath, "db-v0-*"))
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1)
+
+	backup, err := leveldb.New(backups[0], []byte{}, logging.NoLog{}, "test", prometheus.NewRegistry())
+	assert.NoError(t, err)
+	defer backup.Close()
+
+	value, err := backup.Get([]byte("foo"))
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("bar"), value)
+
+	// The backup is taken before migrating, so it's still unversioned.
+	has, err := backup.Has([]byte("schema_version"))
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	count, err := database.Count(backup)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// An up to date database isn't backed up again.
+	assert.NoError(t, migrate(db, backupsPath))
+	backups, err = filepath.Glob(filepath.Join(backupsPath, "db-v0-*"))
+	assert.NoError(t, err)
+	assert.Len(t, backups, 1)
+}
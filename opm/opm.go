@@ -9,7 +9,6 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
-	"syscall"
 	"text/tabwriter"
 
 	"github.com/DioneProtocol/odysseygo/database"
@@ -17,14 +16,18 @@ import (
 	"github.com/DioneProtocol/odysseygo/utils/logging"
 	"github.com/DioneProtocol/odysseygo/utils/perms"
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/afero"
 
 	"github.com/DioneProtocol/opm/admin"
+	"github.com/DioneProtocol/opm/api"
+	"github.com/DioneProtocol/opm/config"
 	"github.com/DioneProtocol/opm/constant"
 	"github.com/DioneProtocol/opm/engine"
 	"github.com/DioneProtocol/opm/git"
+	"github.com/DioneProtocol/opm/info"
+	"github.com/DioneProtocol/opm/node"
+	"github.com/DioneProtocol/opm/source"
 	"github.com/DioneProtocol/opm/storage"
 	"github.com/DioneProtocol/opm/types"
 	"github.com/DioneProtocol/opm/url"
@@ -34,38 +37,59 @@ import (
 
 var (
 	dbDir            = "db"
+	backupsDir       = "backups"
 	repositoryDir    = "repositories"
 	tmpDir           = "tmp"
 	metricsNamespace = "opm_db"
+	profilePrefix    = "profile"
 )
 
 type Config struct {
 	Directory        string
-	Auth             http.BasicAuth
+	GitCredentials   git.Credentials
+	NodeAuth         config.NodeCredential
 	AdminAPIEndpoint string
 	PluginDir        string
+	NodeConfigDir    string
+	NodeConfigFile   string
 	Fs               afero.Fs
+
+	// Profile is the name of the node profile in use. Each profile has its own
+	// installation registry, while repositories and their definitions are
+	// shared. An empty profile uses the default registry.
+	Profile string
+
+	// GitDepth limits the commits fetched when syncing repositories. The full
+	// history is fetched if it's 0.
+	GitDepth int
 }
 
 type OPM struct {
 	db database.Database
 
-	sourcesList  storage.Storage[storage.SourceInfo]
-	installedVMs storage.Storage[storage.InstallInfo]
-	registry     storage.Storage[storage.RepoList]
-	repoFactory  storage.RepositoryFactory
+	sourcesList   storage.Storage[storage.SourceInfo]
+	installedVMs  storage.Storage[storage.InstallInfo]
+	registry      storage.Registry
+	configFiles   storage.Storage[storage.ConfigFile]
+	joinedSubnets storage.Storage[storage.JoinInfo]
+	repoFactory   storage.RepositoryFactory
 
 	executor workflow.Executor
 
-	auth http.BasicAuth
+	gitCredentials git.Credentials
 
-	adminClient admin.Client
-	installer   workflow.Installer
+	adminClient   admin.Client
+	infoClient    info.Client
+	installer     workflow.Installer
+	reader        git.FileReader
+	nodeConfigDir *node.ConfigDir
+	nodeConfig    *node.NodeConfig
 
 	repositoriesPath string
 	tmpPath          string
 	pluginPath       string
 	adminAPIEndpoint string
+	gitDepth         int
 	fs               afero.Fs
 }
 
@@ -76,6 +100,21 @@ func New(config Config) (*OPM, error) {
 		return nil, err
 	}
 
+	if err := migrate(db, filepath.Join(config.Directory, backupsDir)); err != nil {
+		return nil, err
+	}
+
+	// State that belongs to a specific node is kept separate for each profile.
+	nodeDB := database.Database(db)
+	if config.Profile != "" {
+		nodeDB = profileDB(db, config.Profile)
+	}
+
+	apiClient, err := api.NewClient(config.Fs, config.NodeAuth)
+	if err != nil {
+		return nil, err
+	}
+
 	a := &OPM{
 		repositoriesPath: filepath.Join(config.Directory, repositoryDir),
 		tmpPath:          filepath.Join(config.Directory, tmpDir),
@@ -83,19 +122,26 @@ func New(config Config) (*OPM, error) {
 		db:               db,
 		registry:         storage.NewRegistry(db),
 		sourcesList:      storage.NewSourceInfo(db),
-		installedVMs:     storage.NewInstalledVMs(db),
-		auth:             config.Auth,
+		installedVMs:     storage.NewInstalledVMs(nodeDB),
+		configFiles:      storage.NewConfigFiles(nodeDB),
+		joinedSubnets:    storage.NewJoinedSubnets(nodeDB),
+		gitCredentials:   config.GitCredentials,
 		adminAPIEndpoint: config.AdminAPIEndpoint,
-		adminClient:      admin.NewClient(fmt.Sprintf("http://%s", config.AdminAPIEndpoint)),
+		gitDepth:         config.GitDepth,
+		adminClient:      admin.NewClient(api.NodeURI(config.AdminAPIEndpoint), apiClient),
+		infoClient:       info.NewClient(api.NodeURI(config.AdminAPIEndpoint), apiClient),
 		installer: workflow.NewVMInstaller(
 			workflow.VMInstallerConfig{
 				Fs:        config.Fs,
 				URLClient: url.NewClient(),
 			},
 		),
-		executor:    engine.NewWorkflowEngine(),
-		fs:          config.Fs,
-		repoFactory: storage.NewRepositoryFactory(db),
+		executor:      engine.NewWorkflowEngine(),
+		reader:        git.CommitFileReader{},
+		fs:            config.Fs,
+		repoFactory:   storage.NewRepositoryFactory(db),
+		nodeConfigDir: node.NewConfigDir(config.Fs, config.NodeConfigDir),
+		nodeConfig:    node.NewNodeConfig(config.Fs, config.NodeConfigFile),
 	}
 	if err := os.MkdirAll(a.repositoriesPath, perms.ReadWriteExecute); err != nil {
 		return nil, err
@@ -106,7 +152,7 @@ func New(config Config) (*OPM, error) {
 	if ok, err := a.sourcesList.Has(coreKey); err != nil {
 		return nil, err
 	} else if !ok {
-		err := a.AddRepository(constant.CoreAlias, constant.CoreURL, constant.CoreBranch)
+		err := a.AddRepository(constant.CoreAlias, constant.CoreURL, storage.BranchReference, constant.CoreBranch)
 		if err != nil {
 			return nil, err
 		}
@@ -129,24 +175,99 @@ func New(config Config) (*OPM, error) {
 	return a, nil
 }
 
-func parseAndRun(alias string, registry storage.Storage[storage.RepoList], command func(string) error) error {
-	if qualifiedName(alias) {
-		return command(alias)
+// resolve parses [alias] into a reference to a [kind], and looks up its
+// repository in [registry] if it isn't qualified.
+func resolve(alias string, kind string, registry storage.Storage[storage.RepoList]) (types.Reference, error) {
+	reference, err := types.ParseReference(alias)
+	if err != nil {
+		return types.Reference{}, err
+	}
+
+	if reference.Type != "" && reference.Type != kind {
+		return types.Reference{}, fmt.Errorf("%s refers to a %s, but a %s is expected", alias, reference.Type, kind)
 	}
 
-	fullName, err := getFullNameForAlias(registry, alias)
+	if reference.Qualified() {
+		return reference, nil
+	}
+
+	reference.Repository, err = getRepositoryForAlias(registry, kind, reference.Name)
+	if err != nil {
+		return types.Reference{}, err
+	}
+
+	return reference, nil
+}
+
+// parseAndRun resolves [alias] to the fully qualified name of a [kind] in
+// [registry], and runs [command] on it.
+func parseAndRun(alias string, kind string, registry storage.Storage[storage.RepoList], command func(string) error) error {
+	reference, err := resolve(alias, kind, registry)
 	if err != nil {
 		return err
 	}
 
-	return command(fullName)
+	if err := verifyUnpinned(reference); err != nil {
+		return err
+	}
+
+	return command(reference.QualifiedName())
+}
+
+// verifyUnpinned returns an error if [reference] is pinned, for commands that
+// don't install a VM.
+func verifyUnpinned(reference types.Reference) error {
+	if reference.Pinned() {
+		return fmt.Errorf("%s is pinned to a version or sha256, which is only supported when installing or upgrading a virtual machine", reference)
+	}
+
+	return nil
+}
+
+// verifyPins returns an error if the synced definition of the VM [reference]
+// refers to doesn't satisfy its pins.
+func (a *OPM) verifyPins(reference types.Reference) error {
+	if !reference.Pinned() {
+		return nil
+	}
+
+	repository := a.repoFactory.GetRepository([]byte(reference.Repository))
+	definition, err := repository.VMs.Get([]byte(reference.Name))
+	if err == database.ErrNotFound {
+		return fmt.Errorf("no virtual machine definition found for %s", reference.QualifiedName())
+	} else if err != nil {
+		return err
+	}
+
+	return reference.Check(definition.Definition)
 }
 
-func (a *OPM) Install(alias string) error {
-	return parseAndRun(alias, a.registry, a.install)
+// Install installs a VM. If [force] is set, the VM is installed even if it's
+// incompatible with the node.
+func (a *OPM) Install(alias string, force bool) error {
+	checker := a.compatibilityChecker(force)
+
+	reference, err := resolve(alias, constant.VMType, a.registry.VMs)
+	if err != nil {
+		return err
+	}
+
+	if err := a.verifyPins(reference); err != nil {
+		return err
+	}
+
+	name := reference.QualifiedName()
+	installed, err := a.installsBinary(name, func() error {
+		return a.install(name, checker)
+	})
+	if err != nil || !installed {
+		return err
+	}
+
+	return a.loadVMs(name)
 }
 
-func (a *OPM) install(name string) error {
+func (a *OPM) install(name string, checker workflow.CompatibilityChecker) error {
 	nameBytes := []byte(name)
 
 	ok, err := a.installedVMs.Has(nameBytes)
@@ -175,13 +296,57 @@ func (a *OPM) install(name string) error {
 		VMStorage:    repository.VMs,
 		Fs:           a.fs,
 		Installer:    a.installer,
+		Checker:      checker,
 	})
 
 	return a.executor.Execute(workflow)
 }
 
+// installsBinary runs [install] and returns true if it installed a new binary
+// for the VM [name]. Only those VMs need to be loaded by the node.
+func (a *OPM) installsBinary(name string, install func() error) (bool, error) {
+	before, err := a.installedVMs.Get([]byte(name))
+	wasInstalled := err == nil
+	if err != nil && err != database.ErrNotFound {
+		return false, err
+	}
+
+	if err := install(); err != nil {
+		return false, err
+	}
+
+	after, err := a.installedVMs.Get([]byte(name))
+	if err == database.ErrNotFound {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return !wasInstalled || !sameInstall(before, after), nil
+}
+
+// sameInstall returns true if [a] and [b] are the same binary.
+func sameInstall(a storage.InstallInfo, b storage.InstallInfo) bool {
+	return a.ID == b.ID &&
+		a.Version.Compare(&b.Version) == 0 &&
+		a.Commit == b.Commit &&
+		a.BinaryPath == b.BinaryPath &&
+		a.URL == b.URL &&
+		a.SHA256 == b.SHA256
+}
+
+// compatibilityChecker returns a checker that verifies VMs are compatible with
+// the node before they're installed.
+func (a *OPM) compatibilityChecker(force bool) workflow.CompatibilityChecker {
+	return workflow.NewNodeCompatibilityChecker(workflow.NodeCompatibilityCheckerConfig{
+		InfoClient:      a.infoClient,
+		NodeAPIEndpoint: a.adminAPIEndpoint,
+		Force:           force,
+	})
+}
+
 func (a *OPM) Uninstall(alias string) error {
-	return parseAndRun(alias, a.registry, a.uninstall)
+	return parseAndRun(alias, constant.VMType, a.registry.VMs, a.uninstall)
 }
 
 func (a *OPM) uninstall(name string) error {
@@ -196,6 +361,7 @@ func (a *OPM) uninstall(name string) error {
 			RepoAlias:    alias,
 			VMStorage:    repository.VMs,
 			InstalledVMs: a.installedVMs,
+			ConfigFiles:  a.configFiles,
 			Fs:           a.fs,
 			PluginPath:   a.pluginPath,
 		},
@@ -204,11 +370,17 @@ func (a *OPM) uninstall(name string) error {
 	return wf.Execute()
 }
 
-func (a *OPM) JoinSubnet(alias string) error {
-	return parseAndRun(alias, a.registry, a.joinSubnet)
+// JoinSubnet installs a subnet's VMs and configures the node to track it. If
+// [force] is set, VMs are installed even if they're incompatible with the node.
+func (a *OPM) JoinSubnet(alias string, force bool) error {
+	checker := a.compatibilityChecker(force)
+
+	return parseAndRun(alias, constant.SubnetType, a.registry.Subnets, func(fullName string) error {
+		return a.joinSubnet(fullName, checker)
+	})
 }
 
-func (a *OPM) joinSubnet(fullName string) error {
+func (a *OPM) joinSubnet(fullName string, checker workflow.CompatibilityChecker) error {
 	alias, plugin := util.ParseQualifiedName(fullName)
 	repoRegistry := a.repoFactory.GetRepository([]byte(alias))
 
@@ -223,51 +395,234 @@ func (a *OPM) joinSubnet(fullName string) error {
 	}
 
 	subnet := definition.Definition
+	if err := subnet.Verify(); err != nil {
+		return err
+	}
 
 	// TODO prompt user, add force flag
 	fmt.Printf("Installing virtual machines for subnet %s.\n", subnet.GetID())
+	vmNames := make([]string, 0, len(subnet.VMs))
 	for _, vm := range subnet.VMs {
-		if err := a.Install(strings.Join([]string{alias, vm}, constant.QualifiedNameDelimiter)); err != nil {
+		vmName := strings.Join([]string{alias, vm}, constant.QualifiedNameDelimiter)
+		installed, err := a.installsBinary(vmName, func() error {
+			return a.install(vmName, checker)
+		})
+		if err != nil {
 			return err
 		}
+		if installed {
+			vmNames = append(vmNames, vmName)
+		}
 	}
 
-	fmt.Printf("Updating virtual machines...\n")
-	if err := a.adminClient.LoadVMs(); errors.Is(err, syscall.ECONNREFUSED) {
-		fmt.Printf("Node at %s was offline. Virtual machines will be available upon node startup.\n", a.adminAPIEndpoint)
-	} else if err != nil {
+	if subnet.Config != nil {
+		fmt.Printf("Writing subnet config for %s...\n", subnet.GetID())
+		if err := a.nodeConfigDir.WriteSubnetConfig(subnet.GetID(), subnet.Config); err != nil {
+			return err
+		}
+	}
+
+	if err := a.installChainConfigs(alias, fullName, subnet); err != nil {
 		return err
 	}
 
-	fmt.Printf("Whitelisting subnet %s...\n", subnet.GetID())
-	if err := a.adminClient.WhitelistSubnet(subnet.GetID()); errors.Is(err, syscall.ECONNREFUSED) {
-		fmt.Printf("Node at %s was offline. You'll need to whitelist the subnet upon node restart.\n", a.adminAPIEndpoint)
-	} else if err != nil {
+	if len(vmNames) > 0 {
+		if err := a.loadVMs(vmNames...); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf("Adding subnet %s to %s in %s...\n", subnet.GetID(), node.TrackSubnetsKey, a.nodeConfig.Path())
+	added, err := a.nodeConfig.TrackSubnet(subnet.GetID())
+	if err != nil {
 		return err
 	}
+	if err := a.joinedSubnets.Put([]byte(fullName), storage.JoinInfo{ID: subnet.GetID()}); err != nil {
+		return err
+	}
+	if added {
+		fmt.Printf("Restart your node for it to start tracking subnet %s.\n", subnet.GetID())
+	} else {
+		fmt.Printf("Subnet %s is already tracked in %s.\n", subnet.GetID(), a.nodeConfig.Path())
+	}
 
 	fmt.Printf("Finished installing virtual machines for subnet %s.\n", subnet.ID)
 	return nil
 }
 
-func (a *OPM) Info(alias string) error {
-	if qualifiedName(alias) {
-		return a.install(alias)
+// loadVMs asks the node to load the VMs with the fully qualified [names] and
+// reports the result.
+func (a *OPM) loadVMs(names ...string) error {
+	vms := make(map[string]string, len(names))
+	for _, name := range names {
+		alias, plugin := util.ParseQualifiedName(name)
+		definition, err := a.repoFactory.GetRepository([]byte(alias)).VMs.Get([]byte(plugin))
+		if err != nil {
+			return err
+		}
+		vms[name] = definition.Definition.GetID()
 	}
 
-	fullName, err := getFullNameForAlias(a.registry, alias)
+	return a.loadVMIDs(vms)
+}
+
+// loadVMIDs asks the node to load [vms], which maps the fully qualified name of
+// each VM to its ID, and reports the result.
+func (a *OPM) loadVMIDs(vms map[string]string) error {
+	return a.executor.Execute(workflow.NewLoadVMs(workflow.LoadVMsConfig{
+		VMs:              vms,
+		AdminClient:      a.adminClient,
+		AdminAPIEndpoint: a.adminAPIEndpoint,
+	}))
+}
+
+// installChainConfigs installs the chain configs provided by a subnet and its
+// VMs. The subnet's chain configs take precedence over a VM's chain config for
+// the same chain.
+func (a *OPM) installChainConfigs(repoAlias string, fullName string, subnet types.Subnet) error {
+	repository := a.repoFactory.GetRepository([]byte(repoAlias))
+	for _, vm := range subnet.VMs {
+		definition, err := repository.VMs.Get([]byte(vm))
+		if err != nil {
+			return err
+		}
+
+		vmName := strings.Join([]string{repoAlias, vm}, constant.QualifiedNameDelimiter)
+		if err := a.executor.Execute(workflow.NewInstallChainConfigs(workflow.InstallChainConfigsConfig{
+			Owner:        vmName,
+			ChainConfigs: workflow.VMChainConfigs(definition.Definition, subnet),
+			ConfigDir:    a.nodeConfigDir,
+			ConfigFiles:  a.configFiles,
+			Fs:           a.fs,
+		})); err != nil {
+			return err
+		}
+	}
+
+	return a.executor.Execute(workflow.NewInstallChainConfigs(workflow.InstallChainConfigsConfig{
+		Owner:        fullName,
+		ChainConfigs: subnet.ChainConfigs,
+		ConfigDir:    a.nodeConfigDir,
+		ConfigFiles:  a.configFiles,
+		Fs:           a.fs,
+	}))
+}
+
+func (a *OPM) LeaveSubnet(alias string) error {
+	return parseAndRun(alias, constant.SubnetType, a.registry.Subnets, a.leaveSubnet)
+}
+
+func (a *OPM) leaveSubnet(fullName string) error {
+	subnetID, err := a.joinedSubnetID(fullName)
 	if err != nil {
 		return err
 	}
 
-	return a.info(fullName)
+	return a.executor.Execute(workflow.NewLeaveSubnet(workflow.LeaveSubnetConfig{
+		Name:          fullName,
+		SubnetID:      subnetID,
+		NodeConfig:    a.nodeConfig,
+		JoinedSubnets: a.joinedSubnets,
+		ConfigFiles:   a.configFiles,
+		Fs:            a.fs,
+	}))
 }
 
-func (a *OPM) info(fullName string) error {
-	return nil
+// joinedSubnetID returns the id the subnet [fullName] was joined with, which
+// outlives its definition. Subnets that weren't joined using the opm are found
+// by their definition instead.
+func (a *OPM) joinedSubnetID(fullName string) (string, error) {
+	joinInfo, err := a.joinedSubnets.Get([]byte(fullName))
+	if err == nil {
+		return joinInfo.ID, nil
+	} else if err != database.ErrNotFound {
+		return "", err
+	}
+
+	alias, plugin := util.ParseQualifiedName(fullName)
+	definition, err := a.repoFactory.GetRepository([]byte(alias)).Subnets.Get([]byte(plugin))
+	if err == database.ErrNotFound {
+		return "", fmt.Errorf("subnet %s isn't joined and doesn't exist in %s", fullName, alias)
+	} else if err != nil {
+		return "", err
+	}
+
+	return definition.Definition.GetID(), nil
+}
+
+// Info shows the VM or subnet [alias] refers to. Without a type prefix, both a
+// VM and a subnet by that alias are shown.
+func (a *OPM) Info(alias string) error {
+	reference, err := types.ParseReference(alias)
+	if err != nil {
+		return err
+	}
+
+	if err := verifyUnpinned(reference); err != nil {
+		return err
+	}
+
+	switch {
+	case reference.Type == constant.VMType:
+		return parseAndRun(alias, reference.Type, a.registry.VMs, func(fullName string) error {
+			return a.info(fullName, reference.Type)
+		})
+	case reference.Type == constant.SubnetType:
+		return parseAndRun(alias, reference.Type, a.registry.Subnets, func(fullName string) error {
+			return a.info(fullName, reference.Type)
+		})
+	case reference.Qualified():
+		return a.info(reference.QualifiedName(), "")
+	}
+
+	vmRepository, err := getRepositoryForAlias(a.registry.VMs, constant.VMType, reference.Name)
+	if err != nil && !errors.Is(err, database.ErrNotFound) {
+		return err
+	}
+	subnetRepository, subnetErr := getRepositoryForAlias(a.registry.Subnets, constant.SubnetType, reference.Name)
+	if subnetErr != nil && !errors.Is(subnetErr, database.ErrNotFound) {
+		return subnetErr
+	}
+
+	switch {
+	case err == nil && subnetErr == nil && vmRepository != subnetRepository:
+		vm := types.Reference{Type: constant.VMType, Repository: vmRepository, Name: reference.Name}
+		subnet := types.Reference{Type: constant.SubnetType, Repository: subnetRepository, Name: reference.Name}
+		return fmt.Errorf("more than one match found for %s. Please specify the type or the fully qualified name. Matches: %s, %s", alias, vm, subnet)
+	case err == nil:
+		reference.Repository = vmRepository
+	case subnetErr == nil:
+		reference.Repository = subnetRepository
+	default:
+		return fmt.Errorf("%w: no virtual machine or subnet named %s in the tracked repositories", database.ErrNotFound, reference.Name)
+	}
+
+	return a.info(reference.QualifiedName(), "")
+}
+
+func (a *OPM) info(fullName string, kind string) error {
+	alias, plugin := util.ParseQualifiedName(fullName)
+
+	wf := workflow.NewInfo(workflow.InfoConfig{
+		Name:         fullName,
+		Type:         kind,
+		Plugin:       plugin,
+		RepoAlias:    alias,
+		Repository:   a.repoFactory.GetRepository([]byte(alias)),
+		SourcesList:  a.sourcesList,
+		InstalledVMs: a.installedVMs,
+	})
+
+	return a.executor.Execute(wf)
 }
 
 func (a *OPM) Update() error {
+	return a.update()
+}
+
+// update syncs the repositories [aliases], or every repository if none are
+// given.
+func (a *OPM) update(aliases ...string) error {
 	workflow := workflow.NewUpdate(workflow.UpdateConfig{
 		Executor:         a.executor,
 		Registry:         a.registry,
@@ -278,10 +633,11 @@ func (a *OPM) Update() error {
 		PluginPath:       a.pluginPath,
 		Installer:        a.installer,
 		RepositoriesPath: a.repositoriesPath,
-		Auth:             a.auth,
-		GitFactory:       git.RepositoryFactory{},
+		Sources:          a.sources(),
+		Differ:           git.CommitDiffer{},
 		RepoFactory:      storage.NewRepositoryFactory(a.db),
 		Fs:               a.fs,
+		Aliases:          aliases,
 	})
 
 	if err := a.executor.Execute(workflow); err != nil {
@@ -291,117 +647,236 @@ func (a *OPM) Update() error {
 	return nil
 }
 
-func (a *OPM) Upgrade(alias string) error {
+// sources returns how repositories are synced, by where they come from.
+func (a *OPM) sources() map[storage.SourceType]source.Source {
+	return map[storage.SourceType]source.Source{
+		storage.GitSource: source.Git{
+			Factory:     git.RepositoryFactory{Depth: a.gitDepth},
+			Credentials: a.gitCredentials,
+		},
+		storage.HTTPSource:  source.Index{Fs: a.fs},
+		storage.LocalSource: source.Local{Fs: a.fs},
+	}
+}
+
+// Upgrade upgrades a VM, or all installed VMs if [alias] is empty. If [force]
+// is set, VMs are upgraded even if the new version is incompatible with the
+// node.
+func (a *OPM) Upgrade(alias string, force bool) error {
+	checker := a.compatibilityChecker(force)
+
 	// If we have an alias specified, upgrade the specified VM.
 	if alias != "" {
-		return parseAndRun(alias, a.registry, a.upgradeVM)
+		reference, err := resolve(alias, constant.VMType, a.registry.VMs)
+		if err != nil {
+			return err
+		}
+
+		if err := a.verifyPins(reference); err != nil {
+			return err
+		}
+
+		return a.upgradeVM(reference.QualifiedName(), checker)
 	}
 
 	// Otherwise, just upgrade everything.
 	wf := workflow.NewUpgrade(workflow.UpgradeConfig{
-		Executor:     a.executor,
-		RepoFactory:  a.repoFactory,
-		Registry:     a.registry,
-		SourcesList:  a.sourcesList,
-		InstalledVMs: a.installedVMs,
-		TmpPath:      a.tmpPath,
-		PluginPath:   a.pluginPath,
-		Installer:    a.installer,
-		Fs:           a.fs,
+		Executor:      a.executor,
+		RepoFactory:   a.repoFactory,
+		Registry:      a.registry.VMs,
+		SourcesList:   a.sourcesList,
+		InstalledVMs:  a.installedVMs,
+		ConfigFiles:   a.configFiles,
+		JoinedSubnets: a.joinedSubnets,
+		TmpPath:       a.tmpPath,
+		PluginPath:    a.pluginPath,
+		ConfigDir:     a.nodeConfigDir,
+		Installer:     a.installer,
+		Checker:       checker,
+		Fs:            a.fs,
 	})
 
 	return a.executor.Execute(wf)
 }
 
-func (a *OPM) upgradeVM(name string) error {
+func (a *OPM) upgradeVM(name string, checker workflow.CompatibilityChecker) error {
+	return a.upgradeOrReinstallVM(name, checker, false)
+}
+
+// upgradeOrReinstallVM upgrades the VM [name]. If [reinstall] is set, it's
+// also reinstalled if its artifact changed without a new version.
+func (a *OPM) upgradeOrReinstallVM(name string, checker workflow.CompatibilityChecker, reinstall bool) error {
 	return a.executor.Execute(workflow.NewUpgradeVM(
 		workflow.UpgradeVMConfig{
-			Executor:     a.executor,
-			FullVMName:   name,
-			RepoFactory:  a.repoFactory,
-			InstalledVMs: a.installedVMs,
-			TmpPath:      a.tmpPath,
-			PluginPath:   a.pluginPath,
-			Installer:    a.installer,
-			Fs:           a.fs,
+			Executor:      a.executor,
+			FullVMName:    name,
+			RepoFactory:   a.repoFactory,
+			InstalledVMs:  a.installedVMs,
+			ConfigFiles:   a.configFiles,
+			JoinedSubnets: a.joinedSubnets,
+			TmpPath:       a.tmpPath,
+			PluginPath:    a.pluginPath,
+			ConfigDir:     a.nodeConfigDir,
+			Installer:     a.installer,
+			Checker:       checker,
+			Fs:            a.fs,
+			Reinstall:     reinstall,
 		},
 	))
 }
 
-func (a *OPM) AddRepository(alias string, url string, branch string) error {
+// AddRepository tracks the repository at [url] by the branch, tag, commit or
+// tag pattern [reference], according to [referenceType].
+func (a *OPM) AddRepository(alias string, url string, referenceType storage.ReferenceType, reference string) error {
 	if !util.ValidAlias(alias) {
 		return fmt.Errorf("%s is not a valid alias (must be in the form of organization/repository)", alias)
 	}
 
 	wf := workflow.NewAddRepository(
 		workflow.AddRepositoryConfig{
-			SourcesList: a.sourcesList,
-			Alias:       alias,
-			URL:         url,
-			Branch:      plumbing.NewBranchReferenceName(branch),
+			SourcesList:   a.sourcesList,
+			Alias:         alias,
+			URL:           url,
+			ReferenceType: referenceType,
+			Reference:     reference,
+			Source:        storage.GitSource,
 		},
 	)
 
 	return a.executor.Execute(wf)
 }
 
-func (a *OPM) RemoveRepository(alias string) error {
-	if alias == constant.CoreAlias {
-		fmt.Printf("Can't remove %s (required repository).\n", constant.CoreAlias)
-		return nil
+// AddIndexRepository tracks the signed index at [url], which is verified with
+// the hex-encoded ed25519 [publicKey].
+func (a *OPM) AddIndexRepository(alias string, url string, publicKey string) error {
+	if !util.ValidAlias(alias) {
+		return fmt.Errorf("%s is not a valid alias (must be in the form of organization/repository)", alias)
 	}
 
-	aliasBytes := []byte(alias)
-	repoRegistry := a.repoFactory.GetRepository(aliasBytes)
+	return a.executor.Execute(workflow.NewAddRepository(workflow.AddRepositoryConfig{
+		SourcesList: a.sourcesList,
+		Alias:       alias,
+		URL:         url,
+		Source:      storage.HTTPSource,
+		PublicKey:   publicKey,
+	}))
+}
 
-	// delete all the plugin definitions in the repository
-	vmItr := repoRegistry.VMs.Iterator()
-	defer vmItr.Release()
+// AddLocalRepository tracks the definitions in the directory at [path].
+func (a *OPM) AddLocalRepository(alias string, path string) error {
+	if !util.ValidAlias(alias) {
+		return fmt.Errorf("%s is not a valid alias (must be in the form of organization/repository)", alias)
+	}
 
-	for vmItr.Next() {
-		if err := repoRegistry.VMs.Delete(vmItr.Key()); err != nil {
-			return err
-		}
+	dir, err := filepath.Abs(path)
+	if err != nil {
+		return err
 	}
 
-	subnetItr := repoRegistry.Subnets.Iterator()
-	defer subnetItr.Release()
+	return a.executor.Execute(workflow.NewAddRepository(workflow.AddRepositoryConfig{
+		SourcesList: a.sourcesList,
+		Alias:       alias,
+		URL:         dir,
+		Source:      storage.LocalSource,
+	}))
+}
 
-	for subnetItr.Next() {
-		if err := repoRegistry.Subnets.Delete(subnetItr.Key()); err != nil {
-			return err
-		}
+// addSource tracks the repository in [sourceInfo] the way it was added.
+func (a *OPM) addSource(sourceInfo storage.SourceInfo) error {
+	switch sourceInfo.Source {
+	case storage.HTTPSource:
+		return a.AddIndexRepository(sourceInfo.Alias, sourceInfo.URL, sourceInfo.PublicKey)
+	case storage.LocalSource:
+		return a.AddLocalRepository(sourceInfo.Alias, sourceInfo.URL)
+	default:
+		return a.AddRepository(sourceInfo.Alias, sourceInfo.URL, sourceInfo.ReferenceType, sourceInfo.Tracked())
 	}
+}
 
-	// remove it from our list of tracked repositories
-	return a.sourcesList.Delete(aliasBytes)
+// RemoveRepository stops tracking a repository and deletes its clone. If
+// [purge] is set, the VMs installed from it are uninstalled. Otherwise they're
+// left installed and listed.
+func (a *OPM) RemoveRepository(alias string, purge bool) error {
+	if alias == constant.CoreAlias {
+		fmt.Printf("Can't remove %s (required repository).\n", constant.CoreAlias)
+		return nil
+	}
+
+	return a.executor.Execute(workflow.NewRemoveRepository(workflow.RemoveRepositoryConfig{
+		Executor:         a.executor,
+		Alias:            alias,
+		Purge:            purge,
+		SourcesList:      a.sourcesList,
+		Registry:         a.registry,
+		Repository:       a.repoFactory.GetRepository([]byte(alias)),
+		InstalledVMs:     a.installedVMs,
+		JoinedSubnets:    a.joinedSubnets,
+		ConfigFiles:      a.configFiles,
+		RepositoriesPath: a.repositoriesPath,
+		PluginPath:       a.pluginPath,
+		Fs:               a.fs,
+	}))
+}
+
+func (a *OPM) Reindex(check bool) error {
+	return a.executor.Execute(workflow.NewReindex(workflow.ReindexConfig{
+		Check:       check,
+		SourcesList: a.sourcesList,
+		Registry:    a.registry,
+		RepoFactory: a.repoFactory,
+	}))
 }
 
 func (a *OPM) ListRepositories() error {
 	itr := a.sourcesList.Iterator()
 
 	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
-	fmt.Fprintln(w, "alias\turl\tbranch")
+	fmt.Fprintln(w, "alias\tsource\turl\ttype\treference")
 	for itr.Next() {
 		metadata, err := itr.Value()
 		if err != nil {
 			return err
 		}
 
-		fmt.Fprintf(w, "%s\t%s\t%s\n", metadata.Alias, metadata.URL, metadata.Branch)
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", metadata.Alias, metadata.Source, metadata.URL, metadata.ReferenceType, metadata.Tracked())
 	}
 	w.Flush()
 	return nil
 }
 
-func qualifiedName(name string) bool {
-	parsed := strings.Split(name, ":")
-	return len(parsed) > 1
+func (a *OPM) Search(query string, typ string, repository string) error {
+	wf := workflow.NewSearch(workflow.SearchConfig{
+		Query:       query,
+		Type:        typ,
+		Repository:  repository,
+		SourcesList: a.sourcesList,
+		RepoFactory: a.repoFactory,
+	})
+
+	return a.executor.Execute(wf)
+}
+
+func (a *OPM) ListInstalled() error {
+	return a.executor.Execute(workflow.NewListInstalled(workflow.ListInstalledConfig{
+		InstalledVMs: a.installedVMs,
+		PluginPath:   a.pluginPath,
+	}))
 }
 
-func getFullNameForAlias(registry storage.Storage[storage.RepoList], alias string) (string, error) {
+func (a *OPM) Outdated() error {
+	return a.executor.Execute(workflow.NewOutdated(workflow.OutdatedConfig{
+		InstalledVMs: a.installedVMs,
+		RepoFactory:  a.repoFactory,
+	}))
+}
+
+// getRepositoryForAlias returns the repository that defines the [kind]
+// [alias]. It's an error if more than one does.
+func getRepositoryForAlias(registry storage.Storage[storage.RepoList], kind string, alias string) (string, error) {
 	repoList, err := registry.Get([]byte(alias))
-	if err != nil {
+	if err == database.ErrNotFound {
+		return "", fmt.Errorf("%w: no %s named %s in the tracked repositories", err, kind, alias)
+	} else if err != nil {
 		return "", err
 	}
 
@@ -409,5 +884,5 @@ func getFullNameForAlias(registry storage.Storage[storage.RepoList], alias strin
 		return "", fmt.Errorf("more than one match found for %s. Please specify the fully qualified name. Matches: %s", alias, repoList.Repositories)
 	}
 
-	return fmt.Sprintf("%s:%s", repoList.Repositories[0], alias), nil
+	return repoList.Repositories[0], nil
 }
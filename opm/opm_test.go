@@ -0,0 +1,153 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package opm
+
+import (
+	"crypto/ed25519"
+	"strings"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/config"
+	"github.com/DioneProtocol/opm/engine"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestInstallsBinary(t *testing.T) {
+	const name = repoAlias + ":foovm"
+
+	installInfo := storage.InstallInfo{ID: "id", Version: version.Semantic{Major: 1}, URL: "url", SHA256: "sha256"}
+	rebuilt := installInfo
+	rebuilt.SHA256 = "rebuilt"
+
+	tests := []struct {
+		name      string
+		installed *storage.InstallInfo
+		install   *storage.InstallInfo
+		want      bool
+	}{
+		{
+			name:    "new install",
+			install: &installInfo,
+			want:    true,
+		},
+		{
+			name:      "already installed",
+			installed: &installInfo,
+		},
+		{
+			name:      "reinstalled",
+			installed: &installInfo,
+			install:   &rebuilt,
+			want:      true,
+		},
+		{
+			name: "nothing installed",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := &OPM{installedVMs: storage.NewInstalledVMs(memdb.New())}
+			if test.installed != nil {
+				assert.NoError(t, a.installedVMs.Put([]byte(name), *test.installed))
+			}
+
+			installed, err := a.installsBinary(name, func() error {
+				if test.install == nil {
+					return nil
+				}
+				return a.installedVMs.Put([]byte(name), *test.install)
+			})
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, installed)
+		})
+	}
+}
+
+func TestAddSource(t *testing.T) {
+	tests := []config.Repository{
+		{Alias: repoAlias, URL: repoURL, Branch: "main"},
+		{Alias: repoAlias, URL: repoURL, ReferenceType: storage.TagReference, Reference: "v1.0.0"},
+		{Alias: repoAlias, URL: repoURL, ReferenceType: storage.CommitReference, Reference: "0123456789abcdef0123456789abcdef01234567"},
+		{Alias: repoAlias, URL: "https://example.com/index.yaml", Source: storage.HTTPSource, PublicKey: strings.Repeat("ab", ed25519.PublicKeySize)},
+		{Alias: repoAlias, URL: t.TempDir(), Source: storage.LocalSource},
+	}
+
+	for _, repository := range tests {
+		t.Run(repository.String(), func(t *testing.T) {
+			a := &OPM{
+				sourcesList: storage.NewSourceInfo(memdb.New()),
+				executor:    engine.NewWorkflowEngine(),
+			}
+
+			assert.NoError(t, a.addSource(repository.SourceInfo()))
+
+			source, err := a.sourcesList.Get([]byte(repoAlias))
+			assert.NoError(t, err)
+			assert.Equal(t, repository.SourceInfo(), source)
+		})
+	}
+}
+
+func TestJoinedSubnetID(t *testing.T) {
+	const name = repoAlias + ":foo"
+
+	tests := []struct {
+		name       string
+		joinInfo   *storage.JoinInfo
+		definition *types.Subnet
+		want       string
+		wantErr    bool
+	}{
+		{
+			name:     "joined without a definition",
+			joinInfo: &storage.JoinInfo{ID: "joined"},
+			want:     "joined",
+		},
+		{
+			name:       "definition changed since joining",
+			joinInfo:   &storage.JoinInfo{ID: "joined"},
+			definition: &types.Subnet{ID: "changed", Alias: "foo"},
+			want:       "joined",
+		},
+		{
+			name:       "not joined by the opm",
+			definition: &types.Subnet{ID: "defined", Alias: "foo"},
+			want:       "defined",
+		},
+		{
+			name:    "unknown subnet",
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := memdb.New()
+			a := &OPM{
+				joinedSubnets: storage.NewJoinedSubnets(db),
+				repoFactory:   storage.NewRepositoryFactory(db),
+			}
+			if test.joinInfo != nil {
+				assert.NoError(t, a.joinedSubnets.Put([]byte(name), *test.joinInfo))
+			}
+			if test.definition != nil {
+				assert.NoError(t, a.repoFactory.GetRepository([]byte(repoAlias)).Subnets.Put([]byte("foo"), storage.Definition[types.Subnet]{Definition: *test.definition}))
+			}
+
+			got, err := a.joinedSubnetID(name)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
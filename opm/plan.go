@@ -0,0 +1,391 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package opm
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/DioneProtocol/opm/config"
+	"github.com/DioneProtocol/opm/constant"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+	"github.com/DioneProtocol/opm/util"
+	"github.com/DioneProtocol/opm/workflow"
+)
+
+type planAction string
+
+const (
+	actionUninstall         planAction = "uninstall"
+	actionLeave             planAction = "leave"
+	actionRemoveRepository  planAction = "remove-repository"
+	actionReplaceRepository planAction = "replace-repository"
+	actionAddRepository     planAction = "add-repository"
+	actionInstall           planAction = "install"
+	actionUpgrade           planAction = "upgrade"
+	actionJoin              planAction = "join"
+)
+
+// planStep is a single change needed to converge on a manifest.
+type planStep struct {
+	action planAction
+	// name is the fully qualified name of a VM or subnet, or the alias of a
+	// repository.
+	name   string
+	detail string
+	// repository is the repository to add for add and replace steps.
+	repository config.ManifestRepository
+	// locked is the lockfile entry to install for locked install steps.
+	locked *config.LockedVM
+	// pending steps can only be resolved once their repository is synced.
+	pending bool
+}
+
+// plan is the ordered list of changes needed to converge on a manifest.
+type plan struct {
+	steps []planStep
+	// sync is set if any repositories need to be synced before the plan can
+	// be completed.
+	sync bool
+}
+
+// plan computes the changes needed to converge the current state on
+// [manifest]. Steps are ordered so that anything being removed goes first,
+// followed by repository changes, installs, upgrades and joins. If [lockfile]
+// is set, VMs are installed at their locked versions instead of the latest
+// synced versions.
+func (a *OPM) plan(manifest config.Manifest, lockfile *config.Lockfile) (plan, error) {
+	sources, err := readAll(a.sourcesList)
+	if err != nil {
+		return plan{}, err
+	}
+	installed, err := readAll(a.installedVMs)
+	if err != nil {
+		return plan{}, err
+	}
+	joined, err := readAll(a.joinedSubnets)
+	if err != nil {
+		return plan{}, err
+	}
+
+	var (
+		result          plan
+		uninstallSteps  []planStep
+		leaveSteps      []planStep
+		removeSteps     []planStep
+		repositorySteps []planStep
+		installSteps    []planStep
+		upgradeSteps    []planStep
+		joinSteps       []planStep
+		pendingRepos    = map[string]struct{}{}
+		declaredRepos   = map[string]struct{}{constant.CoreAlias: {}}
+		declaredSubnets = make(map[string]struct{}, len(manifest.Subnets))
+		wantVMs         = map[string]types.VersionConstraint{}
+		joiningVMs      = map[string]struct{}{}
+		unresolvedRepos = map[string]struct{}{}
+		repoURLs        = map[string]string{constant.CoreAlias: constant.CoreURL}
+		locks           = map[string]config.LockedVM{}
+	)
+
+	// Repositories
+	for _, repository := range manifest.Repositories {
+		declaredRepos[repository.Alias] = struct{}{}
+		repoURLs[repository.Alias] = repository.URL
+
+		source, ok := sources[repository.Alias]
+		switch {
+		case !ok:
+			pendingRepos[repository.Alias] = struct{}{}
+			repositorySteps = append(repositorySteps, planStep{
+				action:     actionAddRepository,
+				name:       repository.Alias,
+				detail:     repository.String(),
+				repository: repository,
+			})
+		case !repository.Matches(source):
+			if repository.Alias == constant.CoreAlias {
+				return plan{}, fmt.Errorf("can't change the url or reference of %s (required repository)", constant.CoreAlias)
+			}
+
+			pendingRepos[repository.Alias] = struct{}{}
+			repositorySteps = append(repositorySteps, planStep{
+				action:     actionReplaceRepository,
+				name:       repository.Alias,
+				detail:     fmt.Sprintf("%s -> %s", config.NewRepository(source), repository),
+				repository: repository,
+			})
+		case source.Commit == plumbing.ZeroHash:
+			pendingRepos[repository.Alias] = struct{}{}
+		}
+	}
+
+	for _, alias := range sortedKeys(sources) {
+		if _, ok := declaredRepos[alias]; ok {
+			if alias == constant.CoreAlias && sources[alias].Commit == plumbing.ZeroHash {
+				pendingRepos[alias] = struct{}{}
+			}
+			continue
+		}
+
+		removeSteps = append(removeSteps, planStep{
+			action: actionRemoveRepository,
+			name:   alias,
+			detail: sources[alias].URL,
+		})
+	}
+	result.sync = len(pendingRepos) > 0
+
+	if lockfile != nil {
+		for _, repository := range lockfile.Repositories {
+			if url, ok := repoURLs[repository.Alias]; ok && url != repository.URL {
+				return plan{}, fmt.Errorf("%s is declared with %s but was locked from %s", repository.Alias, url, repository.URL)
+			}
+		}
+		for _, vm := range lockfile.VMs {
+			locks[vm.Name] = vm
+		}
+	}
+
+	for _, vm := range manifest.VMs {
+		wantVMs[vm.Name] = vm.Version
+	}
+
+	// Subnets
+	for _, name := range manifest.Subnets {
+		declaredSubnets[name] = struct{}{}
+		repoAlias, plugin := util.ParseQualifiedName(name)
+		_, isJoined := joined[name]
+
+		if _, ok := pendingRepos[repoAlias]; ok {
+			// We don't know which VMs the subnet needs until its repository
+			// is synced, so keep all of the repository's VMs until then.
+			unresolvedRepos[repoAlias] = struct{}{}
+			if !isJoined {
+				joinSteps = append(joinSteps, planStep{
+					action:  actionJoin,
+					name:    name,
+					detail:  fmt.Sprintf("after syncing %s", repoAlias),
+					pending: true,
+				})
+			}
+			continue
+		}
+
+		definition, err := a.repoFactory.GetRepository([]byte(repoAlias)).Subnets.Get([]byte(plugin))
+		if err == database.ErrNotFound {
+			return plan{}, fmt.Errorf("subnet %s doesn't exist in %s", plugin, repoAlias)
+		} else if err != nil {
+			return plan{}, err
+		}
+
+		newVMs := []string{}
+		for _, vm := range definition.Definition.VMs {
+			vmName := strings.Join([]string{repoAlias, vm}, constant.QualifiedNameDelimiter)
+			if _, ok := wantVMs[vmName]; !ok {
+				wantVMs[vmName] = types.VersionConstraint{}
+			}
+			// Locked VMs are installed at their locked versions before the
+			// subnet is joined.
+			if _, ok := installed[vmName]; !ok && !isJoined && lockfile == nil {
+				joiningVMs[vmName] = struct{}{}
+				newVMs = append(newVMs, vmName)
+			}
+		}
+
+		if !isJoined {
+			detail := ""
+			if len(newVMs) > 0 {
+				detail = fmt.Sprintf("installs %s", strings.Join(newVMs, ", "))
+			}
+			joinSteps = append(joinSteps, planStep{
+				action: actionJoin,
+				name:   name,
+				detail: detail,
+			})
+		}
+	}
+
+	for _, name := range sortedKeys(joined) {
+		if _, ok := declaredSubnets[name]; ok {
+			continue
+		}
+
+		leaveSteps = append(leaveSteps, planStep{
+			action: actionLeave,
+			name:   name,
+		})
+	}
+
+	// VMs
+	for _, name := range sortedKeys(installed) {
+		if _, ok := wantVMs[name]; ok {
+			continue
+		}
+		repoAlias, _ := util.ParseQualifiedName(name)
+		if _, ok := unresolvedRepos[repoAlias]; ok {
+			continue
+		}
+
+		installInfo := installed[name]
+		uninstallSteps = append(uninstallSteps, planStep{
+			action: actionUninstall,
+			name:   name,
+			detail: installInfo.Version.String(),
+		})
+	}
+
+	for _, name := range sortedKeys(wantVMs) {
+		constraint := wantVMs[name]
+		repoAlias, plugin := util.ParseQualifiedName(name)
+		installInfo, isInstalled := installed[name]
+		_, isPending := pendingRepos[repoAlias]
+
+		if lockfile != nil {
+			locked, ok := locks[name]
+			if !ok {
+				return plan{}, fmt.Errorf("%s isn't in the lockfile. Run `opm freeze` to update it", name)
+			}
+			lockedVersion, err := version.Parse(locked.Version)
+			if err != nil {
+				return plan{}, err
+			}
+			if !constraint.Check(*lockedVersion) {
+				return plan{}, fmt.Errorf("locked version %s of %s doesn't satisfy %s", locked.Version, name, constraint)
+			}
+			if isInstalled && workflow.IsLocked(installInfo, locked) {
+				continue
+			}
+
+			detail := fmt.Sprintf("%s locked at %s", locked.Version, locked.Commit)
+			if isInstalled {
+				detail = fmt.Sprintf("%s -> %s", installInfo.Version.String(), detail)
+			}
+			installSteps = append(installSteps, planStep{
+				action:  actionInstall,
+				name:    name,
+				detail:  detail,
+				locked:  &locked,
+				pending: isPending,
+			})
+			continue
+		}
+
+		if isPending {
+			if !isInstalled {
+				installSteps = append(installSteps, planStep{
+					action:  actionInstall,
+					name:    name,
+					detail:  fmt.Sprintf("%s after syncing %s", constraint, repoAlias),
+					pending: true,
+				})
+			}
+			continue
+		}
+
+		definition, err := a.repoFactory.GetRepository([]byte(repoAlias)).VMs.Get([]byte(plugin))
+		if err == database.ErrNotFound {
+			return plan{}, fmt.Errorf("vm %s doesn't exist in %s", plugin, repoAlias)
+		} else if err != nil {
+			return plan{}, err
+		}
+		latest := definition.Definition.Version
+
+		if isInstalled && constraint.Check(installInfo.Version) {
+			continue
+		}
+		if !constraint.Check(latest) {
+			return plan{}, fmt.Errorf(
+				"no synced version of %s satisfies %s (latest is %s). Run `opm update` to fetch newer definitions",
+				name,
+				constraint,
+				latest.String(),
+			)
+		}
+
+		switch {
+		case !isInstalled:
+			if _, ok := joiningVMs[name]; ok {
+				// Installed when the subnet is joined.
+				continue
+			}
+			installSteps = append(installSteps, planStep{
+				action: actionInstall,
+				name:   name,
+				detail: latest.String(),
+			})
+		case installInfo.Version.Compare(&latest) < 0:
+			upgradeSteps = append(upgradeSteps, planStep{
+				action: actionUpgrade,
+				name:   name,
+				detail: fmt.Sprintf("%s -> %s", installInfo.Version.String(), latest.String()),
+			})
+		default:
+			return plan{}, fmt.Errorf(
+				"installed version %s of %s doesn't satisfy %s, but the synced version %s is older. Uninstall it first to downgrade",
+				installInfo.Version.String(),
+				name,
+				constraint,
+				latest.String(),
+			)
+		}
+	}
+
+	for _, steps := range [][]planStep{
+		uninstallSteps,
+		leaveSteps,
+		removeSteps,
+		repositorySteps,
+		installSteps,
+		upgradeSteps,
+		joinSteps,
+	} {
+		result.steps = append(result.steps, steps...)
+	}
+
+	return result, nil
+}
+
+func (p plan) print(w io.Writer) error {
+	tw := tabwriter.NewWriter(w, 1, 1, 1, ' ', 0)
+	fmt.Fprintln(tw, "action\tname\tdetail")
+	for _, step := range p.steps {
+		fmt.Fprintf(tw, "%s\t%s\t%s\n", step.action, step.name, step.detail)
+	}
+	return tw.Flush()
+}
+
+// readAll reads every entry in [db] into a map.
+func readAll[V any](db storage.Storage[V]) (map[string]V, error) {
+	itr := db.Iterator()
+	defer itr.Release()
+
+	result := map[string]V{}
+	for itr.Next() {
+		value, err := itr.Value()
+		if err != nil {
+			return nil, err
+		}
+		result[string(itr.Key())] = value
+	}
+
+	return result, itr.Error()
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,387 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package opm
+
+import (
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/config"
+	"github.com/DioneProtocol/opm/constant"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+const (
+	repoAlias = "organization/repository"
+	repoURL   = "https://github.com/organization/repository.git"
+)
+
+var syncedCommit = plumbing.NewHash("d4e5f6")
+
+func mustConstraint(t *testing.T, s string) types.VersionConstraint {
+	constraint, err := types.ParseVersionConstraint(s)
+	assert.NoError(t, err)
+	return constraint
+}
+
+// newPlanOPM returns an opm with the core repository and
+// organization/repository synced. The repository defines foovm v1.2.0,
+// barvm v1.0.0 and the foo subnet, which uses foovm and barvm.
+func newPlanOPM(t *testing.T) *OPM {
+	db := memdb.New()
+	a := &OPM{
+		sourcesList:   storage.NewSourceInfo(db),
+		installedVMs:  storage.NewInstalledVMs(db),
+		joinedSubnets: storage.NewJoinedSubnets(db),
+		repoFactory:   storage.NewRepositoryFactory(db),
+	}
+
+	for _, source := range []storage.SourceInfo{
+		{Alias: constant.CoreAlias, URL: constant.CoreURL, Branch: plumbing.NewBranchReferenceName(constant.CoreBranch), Commit: syncedCommit, ReferenceType: storage.BranchReference, Source: storage.GitSource},
+		{Alias: repoAlias, URL: repoURL, Branch: plumbing.NewBranchReferenceName("main"), Commit: syncedCommit, ReferenceType: storage.BranchReference, Source: storage.GitSource},
+	} {
+		assert.NoError(t, a.sourcesList.Put([]byte(source.Alias), source))
+	}
+
+	repository := a.repoFactory.GetRepository([]byte(repoAlias))
+	assert.NoError(t, repository.VMs.Put([]byte("foovm"), storage.Definition[types.VM]{
+		Definition: types.VM{Alias: "foovm", Version: version.Semantic{Major: 1, Minor: 2}},
+	}))
+	assert.NoError(t, repository.VMs.Put([]byte("barvm"), storage.Definition[types.VM]{
+		Definition: types.VM{Alias: "barvm", Version: version.Semantic{Major: 1}},
+	}))
+	assert.NoError(t, repository.Subnets.Put([]byte("foo"), storage.Definition[types.Subnet]{
+		Definition: types.Subnet{Alias: "foo", VMs: []string{"foovm", "barvm"}},
+	}))
+
+	return a
+}
+
+func TestPlan(t *testing.T) {
+	repository := config.ManifestRepository{Alias: repoAlias, URL: repoURL, Branch: "main"}
+
+	lockedFoo := config.LockedVM{
+		Name:    repoAlias + ":foovm",
+		ID:      "foo",
+		Version: "v1.1.0",
+		Commit:  "0123456789abcdef0123456789abcdef01234567",
+		URL:     "https://foo.com/foovm.tar.gz",
+		SHA256:  "abcd",
+	}
+	lockfile := &config.Lockfile{
+		Version:      config.LockfileVersion,
+		Repositories: []config.LockedRepository{{Alias: repoAlias, URL: repoURL, Branch: "main"}},
+		VMs:          []config.LockedVM{lockedFoo},
+	}
+
+	tests := []struct {
+		name      string
+		setup     func(t *testing.T, a *OPM)
+		manifest  func(t *testing.T) config.Manifest
+		lockfile  *config.Lockfile
+		wantSteps []planStep
+		wantSync  bool
+		wantErr   bool
+	}{
+		{
+			name: "already converged",
+			setup: func(t *testing.T, a *OPM) {
+				assert.NoError(t, a.installedVMs.Put([]byte(repoAlias+":foovm"), storage.InstallInfo{Version: version.Semantic{Major: 1, Minor: 1}}))
+			},
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{repository},
+					VMs:          []config.ManifestVM{{Name: repoAlias + ":foovm", Version: mustConstraint(t, "^1.0.0")}},
+				}
+			},
+		},
+		{
+			name: "install, upgrade and join",
+			setup: func(t *testing.T, a *OPM) {
+				assert.NoError(t, a.installedVMs.Put([]byte(repoAlias+":foovm"), storage.InstallInfo{Version: version.Semantic{Major: 1, Minor: 1}}))
+			},
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{repository},
+					VMs:          []config.ManifestVM{{Name: repoAlias + ":foovm", Version: mustConstraint(t, ">=1.2.0")}},
+					Subnets:      []string{repoAlias + ":foo"},
+				}
+			},
+			wantSteps: []planStep{
+				{action: actionUpgrade, name: repoAlias + ":foovm", detail: "v1.1.0 -> v1.2.0"},
+				{action: actionJoin, name: repoAlias + ":foo", detail: "installs " + repoAlias + ":barvm"},
+			},
+		},
+		{
+			name: "install",
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{repository},
+					VMs:          []config.ManifestVM{{Name: repoAlias + ":barvm"}},
+				}
+			},
+			wantSteps: []planStep{
+				{action: actionInstall, name: repoAlias + ":barvm", detail: "v1.0.0"},
+			},
+		},
+		{
+			name: "remove everything undeclared",
+			setup: func(t *testing.T, a *OPM) {
+				assert.NoError(t, a.installedVMs.Put([]byte(repoAlias+":foovm"), storage.InstallInfo{Version: version.Semantic{Major: 1, Minor: 2}}))
+				assert.NoError(t, a.joinedSubnets.Put([]byte(repoAlias+":foo"), storage.JoinInfo{}))
+			},
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{}
+			},
+			wantSteps: []planStep{
+				{action: actionUninstall, name: repoAlias + ":foovm", detail: "v1.2.0"},
+				{action: actionLeave, name: repoAlias + ":foo"},
+				{action: actionRemoveRepository, name: repoAlias, detail: repoURL},
+			},
+		},
+		{
+			name: "new repository",
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{
+						repository,
+						{Alias: "foo/bar", URL: "https://github.com/foo/bar.git", Branch: "main"},
+					},
+					VMs:     []config.ManifestVM{{Name: "foo/bar:bazvm"}},
+					Subnets: []string{"foo/bar:baz"},
+				}
+			},
+			wantSteps: []planStep{
+				{
+					action:     actionAddRepository,
+					name:       "foo/bar",
+					detail:     "https://github.com/foo/bar.git@main",
+					repository: config.ManifestRepository{Alias: "foo/bar", URL: "https://github.com/foo/bar.git", Branch: "main"},
+				},
+				{action: actionInstall, name: "foo/bar:bazvm", detail: "* after syncing foo/bar", pending: true},
+				{action: actionJoin, name: "foo/bar:baz", detail: "after syncing foo/bar", pending: true},
+			},
+			wantSync: true,
+		},
+		{
+			name: "changed branch",
+			setup: func(t *testing.T, a *OPM) {
+				assert.NoError(t, a.installedVMs.Put([]byte(repoAlias+":foovm"), storage.InstallInfo{Version: version.Semantic{Major: 1, Minor: 2}}))
+			},
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{{Alias: repoAlias, URL: repoURL, Branch: "develop"}},
+					VMs:          []config.ManifestVM{{Name: repoAlias + ":foovm"}},
+				}
+			},
+			wantSteps: []planStep{
+				{
+					action:     actionReplaceRepository,
+					name:       repoAlias,
+					detail:     repoURL + "@main -> " + repoURL + "@develop",
+					repository: config.ManifestRepository{Alias: repoAlias, URL: repoURL, Branch: "develop"},
+				},
+			},
+			wantSync: true,
+		},
+		{
+			name: "tag-tracked repository",
+			setup: func(t *testing.T, a *OPM) {
+				assert.NoError(t, a.sourcesList.Put([]byte(repoAlias), storage.SourceInfo{
+					Alias:         repoAlias,
+					URL:           repoURL,
+					Commit:        syncedCommit,
+					ReferenceType: storage.TagReference,
+					Reference:     "v1.2.0",
+					Source:        storage.GitSource,
+				}))
+			},
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{{Alias: repoAlias, URL: repoURL, ReferenceType: storage.TagReference, Reference: "v1.2.0"}},
+				}
+			},
+		},
+		{
+			name: "changed tag",
+			setup: func(t *testing.T, a *OPM) {
+				assert.NoError(t, a.sourcesList.Put([]byte(repoAlias), storage.SourceInfo{
+					Alias:         repoAlias,
+					URL:           repoURL,
+					Commit:        syncedCommit,
+					ReferenceType: storage.TagReference,
+					Reference:     "v1.2.0",
+					Source:        storage.GitSource,
+				}))
+			},
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{{Alias: repoAlias, URL: repoURL, ReferenceType: storage.TagPatternReference, Reference: "v1.*"}},
+				}
+			},
+			wantSteps: []planStep{
+				{
+					action:     actionReplaceRepository,
+					name:       repoAlias,
+					detail:     repoURL + "@v1.2.0 -> " + repoURL + "@v1.*",
+					repository: config.ManifestRepository{Alias: repoAlias, URL: repoURL, ReferenceType: storage.TagPatternReference, Reference: "v1.*"},
+				},
+			},
+			wantSync: true,
+		},
+		{
+			name: "http repository",
+			setup: func(t *testing.T, a *OPM) {
+				assert.NoError(t, a.sourcesList.Put([]byte(repoAlias), storage.SourceInfo{
+					Alias:     repoAlias,
+					URL:       "https://example.com/index.yaml",
+					Commit:    syncedCommit,
+					Source:    storage.HTTPSource,
+					PublicKey: "abcd",
+				}))
+			},
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{{Alias: repoAlias, URL: "https://example.com/index.yaml", Source: storage.HTTPSource, PublicKey: "abcd"}},
+				}
+			},
+		},
+		{
+			name: "unsatisfiable constraint",
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{repository},
+					VMs:          []config.ManifestVM{{Name: repoAlias + ":foovm", Version: mustConstraint(t, ">=2.0.0")}},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "downgrade",
+			setup: func(t *testing.T, a *OPM) {
+				assert.NoError(t, a.installedVMs.Put([]byte(repoAlias+":foovm"), storage.InstallInfo{Version: version.Semantic{Major: 1, Minor: 3}}))
+			},
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{repository},
+					VMs:          []config.ManifestVM{{Name: repoAlias + ":foovm", Version: mustConstraint(t, "<1.3.0")}},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "missing vm",
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{repository},
+					VMs:          []config.ManifestVM{{Name: repoAlias + ":bazvm"}},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "changed core repository",
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{
+						repository,
+						{Alias: constant.CoreAlias, URL: constant.CoreURL, Branch: "main"},
+					},
+				}
+			},
+			wantErr: true,
+		},
+
+		{
+			name: "locked install",
+			setup: func(t *testing.T, a *OPM) {
+				assert.NoError(t, a.installedVMs.Put([]byte(repoAlias+":foovm"), storage.InstallInfo{Version: version.Semantic{Major: 1, Minor: 2}, SHA256: "beef"}))
+			},
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{repository},
+					VMs:          []config.ManifestVM{{Name: repoAlias + ":foovm"}},
+				}
+			},
+			lockfile: lockfile,
+			wantSteps: []planStep{
+				{
+					action: actionInstall,
+					name:   repoAlias + ":foovm",
+					detail: "v1.2.0 -> v1.1.0 locked at " + lockedFoo.Commit,
+					locked: &lockedFoo,
+				},
+			},
+		},
+		{
+			name: "locked already installed",
+			setup: func(t *testing.T, a *OPM) {
+				assert.NoError(t, a.installedVMs.Put([]byte(repoAlias+":foovm"), storage.InstallInfo{Version: version.Semantic{Major: 1, Minor: 1}, SHA256: "abcd"}))
+			},
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{repository},
+					VMs:          []config.ManifestVM{{Name: repoAlias + ":foovm"}},
+				}
+			},
+			lockfile: lockfile,
+		},
+		{
+			name: "subnet vm not locked",
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{repository},
+					Subnets:      []string{repoAlias + ":foo"},
+				}
+			},
+			lockfile: lockfile,
+			wantErr:  true,
+		},
+		{
+			name: "locked version doesn't satisfy constraint",
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{repository},
+					VMs:          []config.ManifestVM{{Name: repoAlias + ":foovm", Version: mustConstraint(t, ">=1.2.0")}},
+				}
+			},
+			lockfile: lockfile,
+			wantErr:  true,
+		},
+		{
+			name: "locked from another url",
+			manifest: func(t *testing.T) config.Manifest {
+				return config.Manifest{
+					Repositories: []config.ManifestRepository{{Alias: repoAlias, URL: "https://github.com/foo/bar.git", Branch: "main"}},
+				}
+			},
+			lockfile: lockfile,
+			wantErr:  true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			a := newPlanOPM(t)
+			if test.setup != nil {
+				test.setup(t, a)
+			}
+
+			got, err := a.plan(test.manifest(t), test.lockfile)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantSteps, got.steps)
+			assert.Equal(t, test.wantSync, got.sync)
+		})
+	}
+}
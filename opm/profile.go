@@ -0,0 +1,53 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package opm
+
+import (
+	"fmt"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/database/prefixdb"
+
+	"github.com/DioneProtocol/opm/storage"
+)
+
+// profileDB returns the part of [db] that belongs to the profile [name].
+func profileDB(db database.Database, name string) database.Database {
+	return prefixdb.New([]byte(fmt.Sprintf("%s/%s/", profilePrefix, name)), db)
+}
+
+// ForgetProfile deletes the installed VMs, config files and joined subnets
+// recorded for the profile [name], so that a profile added later with the same
+// name starts out empty. The names of the VMs and subnets that were forgotten
+// are returned. Their binaries and node configs are left as they are.
+func (a *OPM) ForgetProfile(name string) ([]string, []string, error) {
+	db := profileDB(a.db, name)
+
+	installed, err := readAll[storage.InstallInfo](storage.NewInstalledVMs(db))
+	if err != nil {
+		return nil, nil, err
+	}
+	joined, err := readAll[storage.JoinInfo](storage.NewJoinedSubnets(db))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	itr := db.NewIterator()
+	defer itr.Release()
+
+	batch := db.NewBatch()
+	for itr.Next() {
+		if err := batch.Delete(itr.Key()); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := itr.Error(); err != nil {
+		return nil, nil, err
+	}
+	if err := batch.Write(); err != nil {
+		return nil, nil, err
+	}
+
+	return sortedKeys(installed), sortedKeys(joined), nil
+}
@@ -0,0 +1,50 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package opm
+
+import (
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/storage"
+)
+
+func TestForgetProfile(t *testing.T) {
+	db := memdb.New()
+	a := &OPM{db: db}
+
+	for _, name := range []string{"testnet", "mainnet"} {
+		profile := profileDB(db, name)
+		assert.NoError(t, storage.NewInstalledVMs(profile).Put([]byte(repoAlias+":foovm"), storage.InstallInfo{ID: "foovm"}))
+		assert.NoError(t, storage.NewJoinedSubnets(profile).Put([]byte(repoAlias+":foo"), storage.JoinInfo{ID: "foo"}))
+		assert.NoError(t, storage.NewConfigFiles(profile).Put([]byte("configs/foo.json"), storage.ConfigFile{Owner: repoAlias + ":foo"}))
+	}
+	assert.NoError(t, storage.NewInstalledVMs(db).Put([]byte(repoAlias+":barvm"), storage.InstallInfo{ID: "barvm"}))
+
+	vms, subnets, err := a.ForgetProfile("testnet")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{repoAlias + ":foovm"}, vms)
+	assert.Equal(t, []string{repoAlias + ":foo"}, subnets)
+
+	// The profile starts out empty if it's added again.
+	testnet := profileDB(db, "testnet")
+	itr := testnet.NewIterator()
+	assert.False(t, itr.Next())
+	itr.Release()
+
+	// Other profiles and the default registry are left alone.
+	mainnet := profileDB(db, "mainnet")
+	for _, check := range []func() (bool, error){
+		func() (bool, error) { return storage.NewInstalledVMs(mainnet).Has([]byte(repoAlias + ":foovm")) },
+		func() (bool, error) { return storage.NewJoinedSubnets(mainnet).Has([]byte(repoAlias + ":foo")) },
+		func() (bool, error) { return storage.NewConfigFiles(mainnet).Has([]byte("configs/foo.json")) },
+		func() (bool, error) { return storage.NewInstalledVMs(db).Has([]byte(repoAlias + ":barvm")) },
+	} {
+		ok, err := check()
+		assert.NoError(t, err)
+		assert.True(t, ok)
+	}
+}
@@ -0,0 +1,151 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package opm
+
+import (
+	"fmt"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/DioneProtocol/opm/config"
+	"github.com/DioneProtocol/opm/util"
+	"github.com/DioneProtocol/opm/workflow"
+)
+
+// ExportState writes a state archive to [path] recording every tracked
+// repository, installed VM and joined subnet.
+func (a *OPM) ExportState(path string) error {
+	return a.executor.Execute(workflow.NewExportState(workflow.ExportStateConfig{
+		Path:             path,
+		SourcesList:      a.sourcesList,
+		InstalledVMs:     a.installedVMs,
+		JoinedSubnets:    a.joinedSubnets,
+		RepositoriesPath: a.repositoriesPath,
+		Reader:           a.reader,
+		Fs:               a.fs,
+	}))
+}
+
+// ImportState restores the state archive at [path]. Its repositories are
+// tracked and synced, its VMs are installed exactly as they were exported and
+// its subnets are joined. The archive is verified against the current state
+// before anything is changed. If [force] is set, VMs are installed even if
+// they're incompatible with the node.
+func (a *OPM) ImportState(path string, force bool) error {
+	state, err := config.ReadState(a.fs, path)
+	if err != nil {
+		return err
+	}
+
+	missing := []config.StateRepository{}
+	for _, repository := range state.Repositories {
+		source, err := a.sourcesList.Get([]byte(repository.Alias))
+		if err == database.ErrNotFound {
+			missing = append(missing, repository)
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		if !repository.Matches(source) {
+			return fmt.Errorf(
+				"%s already tracks %s, but %s has %s. Remove the repository to import it",
+				repository.Alias,
+				config.NewRepository(source),
+				path,
+				repository,
+			)
+		}
+	}
+
+	for _, repository := range missing {
+		if err := a.addSource(repository.SourceInfo()); err != nil {
+			return err
+		}
+	}
+	if len(missing) > 0 {
+		fmt.Println("Syncing imported repositories...")
+		if err := a.Update(); err != nil {
+			return err
+		}
+	}
+
+	for _, repository := range state.Repositories {
+		if repository.Commit == "" {
+			continue
+		}
+
+		source, err := a.sourcesList.Get([]byte(repository.Alias))
+		if err != nil {
+			return err
+		}
+		if source.Commit != plumbing.NewHash(repository.Commit) {
+			fmt.Printf(
+				"%s is synced to %s, but was exported at %s. Definitions may differ from the exporting host.\n",
+				repository.Alias,
+				source.Commit,
+				repository.Commit,
+			)
+		}
+	}
+
+	// Subnets are checked before anything is installed, so that an archive that
+	// can't be fully restored doesn't leave a partial install behind.
+	for _, subnet := range state.Subnets {
+		repoAlias, plugin := util.ParseQualifiedName(subnet.Name)
+		definition, err := a.repoFactory.GetRepository([]byte(repoAlias)).Subnets.Get([]byte(plugin))
+		if err == database.ErrNotFound {
+			return fmt.Errorf("subnet %s doesn't exist in %s", subnet.Name, repoAlias)
+		} else if err != nil {
+			return err
+		}
+		if id := definition.Definition.GetID(); id != subnet.ID {
+			return fmt.Errorf("subnet %s has id %s, but %s has %s", subnet.Name, id, path, subnet.ID)
+		}
+	}
+
+	checker := a.compatibilityChecker(force)
+	lockfile := state.Lockfile()
+	vms := make(map[string]string, len(state.VMs))
+	for _, vm := range state.VMs {
+		installed, err := a.installsBinary(vm.Name, func() error {
+			return a.installLocked(lockfile, vm, checker)
+		})
+		if err != nil {
+			return err
+		}
+		if installed {
+			vms[vm.Name] = vm.ID
+		}
+	}
+
+	for _, subnet := range state.Subnets {
+		if ok, err := a.joinedSubnets.Has([]byte(subnet.Name)); err != nil {
+			return err
+		} else if ok {
+			fmt.Printf("Subnet %s is already joined. Skipping.\n", subnet.Name)
+			continue
+		}
+
+		if err := a.joinSubnet(subnet.Name, checker); err != nil {
+			return err
+		}
+	}
+
+	if len(vms) > 0 {
+		if err := a.loadVMIDs(vms); err != nil {
+			return err
+		}
+	}
+
+	fmt.Printf(
+		"Imported %d repositories, %d virtual machines and %d subnets from %s.\n",
+		len(state.Repositories),
+		len(state.VMs),
+		len(state.Subnets),
+		path,
+	)
+	return nil
+}
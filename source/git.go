@@ -0,0 +1,63 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package source
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/DioneProtocol/opm/git"
+	"github.com/DioneProtocol/opm/storage"
+)
+
+var (
+	errUnknownReferenceType = errors.New("unknown reference type")
+
+	_ Source = Git{}
+)
+
+// Git syncs a clone of a git repository, whose version is the commit checked
+// out.
+type Git struct {
+	Factory git.Factory
+	// Credentials pick the auth method for each repository.
+	Credentials git.Credentials
+}
+
+func (g Git) Sync(sourceInfo storage.SourceInfo, path string) (string, plumbing.Hash, error) {
+	reference, err := gitReference(sourceInfo)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	auth, err := g.Credentials.Auth(sourceInfo.Alias, sourceInfo.URL)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	commit, err := g.Factory.GetRepository(sourceInfo.URL, path, reference, auth)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	return path, commit, nil
+}
+
+// gitReference returns the reference the clone of [sourceInfo] tracks.
+func gitReference(sourceInfo storage.SourceInfo) (git.Reference, error) {
+	switch sourceInfo.ReferenceType {
+	case storage.BranchReference:
+		return git.Reference{Name: sourceInfo.Branch}, nil
+	case storage.TagReference:
+		return git.Reference{Name: plumbing.NewTagReferenceName(sourceInfo.Reference)}, nil
+	case storage.CommitReference:
+		return git.Reference{Commit: plumbing.NewHash(sourceInfo.Reference)}, nil
+	case storage.TagPatternReference:
+		return git.Reference{TagPattern: sourceInfo.Reference}, nil
+	default:
+		return git.Reference{}, fmt.Errorf("%w: %s is tracked by %q", errUnknownReferenceType, sourceInfo.Alias, sourceInfo.ReferenceType)
+	}
+}
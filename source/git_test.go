@@ -0,0 +1,81 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package source
+
+import (
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/transport/http"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/DioneProtocol/opm/git"
+	"github.com/DioneProtocol/opm/storage"
+)
+
+func TestGitSync(t *testing.T) {
+	ctrl := gomock.NewController(t)
+
+	auth := http.BasicAuth{Username: "username", Password: "password"}
+	commit := plumbing.Hash{1}
+	factory := git.NewMockFactory(ctrl)
+	url := "https://github.com/organization/repository"
+	factory.EXPECT().GetRepository(url, "path", git.Reference{Name: "refs/heads/master"}, &auth).Return(commit, nil)
+
+	dir, version, err := Git{Factory: factory, Credentials: git.Credentials{Default: &auth}}.Sync(storage.SourceInfo{
+		Alias:         "organization/repository",
+		URL:           url,
+		Branch:        "refs/heads/master",
+		ReferenceType: storage.BranchReference,
+		Source:        storage.GitSource,
+	}, "path")
+	assert.NoError(t, err)
+	assert.Equal(t, "path", dir)
+	assert.Equal(t, commit, version)
+}
+
+func TestGitReference(t *testing.T) {
+	commit := "0123456789abcdef0123456789abcdef01234567"
+
+	tests := []struct {
+		name       string
+		sourceInfo storage.SourceInfo
+		want       git.Reference
+		wantErr    error
+	}{
+		{
+			name:       "branch",
+			sourceInfo: storage.SourceInfo{ReferenceType: storage.BranchReference, Branch: "refs/heads/master"},
+			want:       git.Reference{Name: "refs/heads/master"},
+		},
+		{
+			name:       "tag",
+			sourceInfo: storage.SourceInfo{ReferenceType: storage.TagReference, Reference: "v1.3.0"},
+			want:       git.Reference{Name: "refs/tags/v1.3.0"},
+		},
+		{
+			name:       "commit",
+			sourceInfo: storage.SourceInfo{ReferenceType: storage.CommitReference, Reference: commit},
+			want:       git.Reference{Commit: plumbing.NewHash(commit)},
+		},
+		{
+			name:       "tag pattern",
+			sourceInfo: storage.SourceInfo{ReferenceType: storage.TagPatternReference, Reference: "v1.*"},
+			want:       git.Reference{TagPattern: "v1.*"},
+		},
+		{
+			name:       "unknown reference type",
+			sourceInfo: storage.SourceInfo{ReferenceType: "unknown"},
+			wantErr:    errUnknownReferenceType,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reference, err := gitReference(test.sourceInfo)
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.want, reference)
+		})
+	}
+}
@@ -0,0 +1,202 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package source
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/util"
+)
+
+const (
+	vmKey     = "vm"
+	subnetKey = "subnet"
+
+	// indexFile and etagFile cache the latest index and its ETag.
+	indexFile = "index"
+	etagFile  = "index.etag"
+
+	// signatureSuffix is appended to the url of an index to get the url of
+	// its signature.
+	signatureSuffix = ".sig"
+)
+
+var (
+	ErrInvalidSignature = errors.New("invalid index signature")
+
+	_ Source = Index{}
+)
+
+// Index fetches a signed document listing every definition of a repository,
+// and writes each definition to its own file, so they're loaded the same way
+// as the ones of a git repository. The document is cached along with its
+// ETag, so it's only downloaded again if it changed. Its version is the hash
+// of the document.
+//
+// The document is YAML or JSON with a list of vms and a list of subnets. Its
+// signature is served at the url of the document with a .sig suffix, as the
+// base64-encoded ed25519 signature of the document.
+type Index struct {
+	// Client is http.DefaultClient if it's nil.
+	Client *http.Client
+	Fs     afero.Fs
+}
+
+// index is the document served by an http source. Definitions are kept as
+// nodes, so they're written out exactly as they were listed.
+type index struct {
+	VMs     []yaml.Node `yaml:"vms"`
+	Subnets []yaml.Node `yaml:"subnets"`
+}
+
+func (i Index) Sync(sourceInfo storage.SourceInfo, path string) (string, plumbing.Hash, error) {
+	publicKey, err := hex.DecodeString(sourceInfo.PublicKey)
+	if err != nil || len(publicKey) != ed25519.PublicKeySize {
+		return "", plumbing.ZeroHash, fmt.Errorf("%w: %s doesn't have a hex-encoded ed25519 public key", ErrInvalidSignature, sourceInfo.Alias)
+	}
+
+	if err := i.Fs.MkdirAll(path, perms.ReadWriteExecute); err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	indexPath := filepath.Join(path, indexFile)
+	etagPath := filepath.Join(path, etagFile)
+
+	// The ETag is only sent if the index it belongs to is still cached.
+	etag := ""
+	if ok, err := afero.Exists(i.Fs, indexPath); err != nil {
+		return "", plumbing.ZeroHash, err
+	} else if ok {
+		if etagBytes, err := afero.ReadFile(i.Fs, etagPath); err == nil {
+			etag = string(etagBytes)
+		}
+	}
+
+	document, etag, err := i.fetch(sourceInfo.URL, etag)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	if document == nil {
+		fmt.Printf("Index of %s wasn't modified.\n", sourceInfo.Alias)
+
+		document, err := afero.ReadFile(i.Fs, indexPath)
+		if err != nil {
+			return "", plumbing.ZeroHash, err
+		}
+		return path, plumbing.ComputeHash(plumbing.BlobObject, document), nil
+	}
+
+	encodedSignature, _, err := i.fetch(sourceInfo.URL+signatureSuffix, "")
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	signature, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(encodedSignature)))
+	if err != nil || !ed25519.Verify(publicKey, document, signature) {
+		return "", plumbing.ZeroHash, fmt.Errorf("%w: %s isn't signed by the key of %s", ErrInvalidSignature, sourceInfo.URL, sourceInfo.Alias)
+	}
+
+	parsed := index{}
+	if err := yaml.Unmarshal(document, &parsed); err != nil {
+		return "", plumbing.ZeroHash, fmt.Errorf("failed to parse the index of %s: %w", sourceInfo.Alias, err)
+	}
+	if err := writeDefinitions(i.Fs, filepath.Join(path, vmDir), vmKey, parsed.VMs); err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	if err := writeDefinitions(i.Fs, filepath.Join(path, subnetDir), subnetKey, parsed.Subnets); err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	// The cache is written last, so the index is downloaded again if writing
+	// the definitions failed.
+	if err := afero.WriteFile(i.Fs, indexPath, document, perms.ReadWrite); err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+	if err := afero.WriteFile(i.Fs, etagPath, []byte(etag), perms.ReadWrite); err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	return path, plumbing.ComputeHash(plumbing.BlobObject, document), nil
+}
+
+// fetch downloads [url]. If [etag] is set and still matches, nil is returned.
+// Returns the ETag of the response.
+func (i Index) fetch(url string, etag string) ([]byte, string, error) {
+	request, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	if etag != "" {
+		request.Header.Set("If-None-Match", etag)
+	}
+
+	client := i.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, "", err
+	}
+	defer response.Body.Close()
+
+	switch response.StatusCode {
+	case http.StatusNotModified:
+		return nil, etag, nil
+	case http.StatusOK:
+		body, err := io.ReadAll(response.Body)
+		return body, response.Header.Get("ETag"), err
+	default:
+		return nil, "", fmt.Errorf("failed to fetch %s: %s", url, response.Status)
+	}
+}
+
+// writeDefinitions replaces the files in [dir] with a [key] definition file
+// for each of [definitions], named after its alias.
+func writeDefinitions(fs afero.Fs, dir string, key string, definitions []yaml.Node) error {
+	if err := fs.RemoveAll(dir); err != nil {
+		return err
+	}
+	if err := fs.MkdirAll(dir, perms.ReadWriteExecute); err != nil {
+		return err
+	}
+
+	for i := range definitions {
+		definition := &definitions[i]
+
+		identity := struct {
+			Alias string `yaml:"alias"`
+		}{}
+		if err := definition.Decode(&identity); err != nil {
+			return err
+		}
+		if !util.ValidName(identity.Alias) {
+			fmt.Printf("Skipping %s with invalid alias %q.\n", key, identity.Alias)
+			continue
+		}
+
+		fileBytes, err := yaml.Marshal(map[string]*yaml.Node{key: definition})
+		if err != nil {
+			return err
+		}
+		if err := afero.WriteFile(fs, filepath.Join(dir, identity.Alias+".yaml"), fileBytes, perms.ReadWrite); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
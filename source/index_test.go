@@ -0,0 +1,149 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package source
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestIndexSync(t *testing.T) {
+	const path = "repository"
+
+	publicKey, privateKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+	_, otherKey, err := ed25519.GenerateKey(nil)
+	assert.NoError(t, err)
+
+	yamlIndex := []byte(`
+vms:
+  - id: foo
+    alias: foovm
+    version:
+      major: 1
+      minor: 2
+      patch: 3
+  - id: bar
+    alias: "invalid:alias"
+subnets:
+  - id: baz
+    alias: bazsubnet
+    vms: [foovm]
+`)
+	jsonIndex := []byte(`{"vms": [{"id": "foo", "alias": "foovm"}], "subnets": []}`)
+
+	tests := []struct {
+		name       string
+		document   []byte
+		signingKey ed25519.PrivateKey
+		publicKey  string
+		wantVMs    []string
+		wantErr    error
+	}{
+		{
+			name:       "yaml",
+			document:   yamlIndex,
+			signingKey: privateKey,
+			publicKey:  hex.EncodeToString(publicKey),
+			wantVMs:    []string{"foovm.yaml"},
+		},
+		{
+			name:       "json",
+			document:   jsonIndex,
+			signingKey: privateKey,
+			publicKey:  hex.EncodeToString(publicKey),
+			wantVMs:    []string{"foovm.yaml"},
+		},
+		{
+			name:       "signed by another key",
+			document:   yamlIndex,
+			signingKey: otherKey,
+			publicKey:  hex.EncodeToString(publicKey),
+			wantErr:    ErrInvalidSignature,
+		},
+		{
+			name:       "invalid public key",
+			document:   yamlIndex,
+			signingKey: privateKey,
+			publicKey:  "public key",
+			wantErr:    ErrInvalidSignature,
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			requests := 0
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				switch r.URL.Path {
+				case "/index.yaml":
+					requests++
+					if r.Header.Get("If-None-Match") == `"v1"` {
+						w.WriteHeader(http.StatusNotModified)
+						return
+					}
+					w.Header().Set("ETag", `"v1"`)
+					_, _ = w.Write(test.document)
+				case "/index.yaml.sig":
+					_, _ = w.Write([]byte(base64.StdEncoding.EncodeToString(ed25519.Sign(test.signingKey, test.document))))
+				default:
+					w.WriteHeader(http.StatusNotFound)
+				}
+			}))
+			defer server.Close()
+
+			fs := afero.NewMemMapFs()
+			index := Index{Client: server.Client(), Fs: fs}
+			sourceInfo := storage.SourceInfo{
+				Alias:     "organization/repository",
+				URL:       server.URL + "/index.yaml",
+				Source:    storage.HTTPSource,
+				PublicKey: test.publicKey,
+			}
+
+			dir, version, err := index.Sync(sourceInfo, path)
+			if test.wantErr != nil {
+				assert.ErrorIs(t, err, test.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, path, dir)
+			assert.Equal(t, plumbing.ComputeHash(plumbing.BlobObject, test.document), version)
+
+			files, err := afero.ReadDir(fs, filepath.Join(path, vmDir))
+			assert.NoError(t, err)
+			names := []string{}
+			for _, file := range files {
+				names = append(names, file.Name())
+			}
+			assert.Equal(t, test.wantVMs, names)
+
+			// Definitions are written the same way as they are in git
+			// repositories.
+			fileBytes, err := afero.ReadFile(fs, filepath.Join(path, vmDir, "foovm.yaml"))
+			assert.NoError(t, err)
+			definition := map[string]types.VM{}
+			assert.NoError(t, yaml.Unmarshal(fileBytes, definition))
+			assert.Equal(t, "foo", definition[vmKey].ID)
+
+			// The cached index is used while it isn't modified.
+			dir, cachedVersion, err := index.Sync(sourceInfo, path)
+			assert.NoError(t, err)
+			assert.Equal(t, path, dir)
+			assert.Equal(t, version, cachedVersion)
+			assert.Equal(t, 2, requests)
+		})
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package source
+
+import (
+	"crypto/sha1"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+
+	"github.com/DioneProtocol/opm/storage"
+)
+
+var _ Source = Local{}
+
+// Local reads definitions straight from a directory, whose version is a hash
+// of the definition files in it.
+type Local struct {
+	Fs afero.Fs
+}
+
+func (l Local) Sync(sourceInfo storage.SourceInfo, _ string) (string, plumbing.Hash, error) {
+	dir := sourceInfo.URL
+	if ok, err := afero.DirExists(l.Fs, dir); err != nil {
+		return "", plumbing.ZeroHash, err
+	} else if !ok {
+		return "", plumbing.ZeroHash, fmt.Errorf("%s isn't a directory", dir)
+	}
+
+	version, err := Checksum(l.Fs, dir)
+	if err != nil {
+		return "", plumbing.ZeroHash, err
+	}
+
+	return dir, version, nil
+}
+
+// Checksum returns a hash of the names and contents of the files in the vms
+// and subnets directories of [dir]. Directories that don't exist are empty.
+func Checksum(fs afero.Fs, dir string) (plumbing.Hash, error) {
+	hasher := sha1.New()
+	for _, definitionDir := range []string{vmDir, subnetDir} {
+		files, err := afero.ReadDir(fs, filepath.Join(dir, definitionDir))
+		if errors.Is(err, os.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return plumbing.ZeroHash, err
+		}
+
+		// Files are sorted by name, so the hash doesn't depend on the order
+		// they're listed in.
+		for _, file := range files {
+			if file.IsDir() {
+				continue
+			}
+
+			name := filepath.Join(definitionDir, file.Name())
+			contents, err := afero.ReadFile(fs, filepath.Join(dir, name))
+			if err != nil {
+				return plumbing.ZeroHash, err
+			}
+			fmt.Fprintf(hasher, "%s %d\n", name, len(contents))
+			hasher.Write(contents)
+		}
+	}
+
+	var version plumbing.Hash
+	copy(version[:], hasher.Sum(nil))
+	return version, nil
+}
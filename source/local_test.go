@@ -0,0 +1,59 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package source
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/storage"
+)
+
+func TestLocalSync(t *testing.T) {
+	const dir = "/plugins"
+
+	fs := afero.NewMemMapFs()
+	local := Local{Fs: fs}
+	sourceInfo := storage.SourceInfo{
+		Alias:  "organization/repository",
+		URL:    dir,
+		Source: storage.LocalSource,
+	}
+
+	_, _, err := local.Sync(sourceInfo, "repository")
+	assert.Error(t, err)
+
+	// Missing definition directories are empty.
+	assert.NoError(t, fs.MkdirAll(dir, perms.ReadWriteExecute))
+	syncedDir, empty, err := local.Sync(sourceInfo, "repository")
+	assert.NoError(t, err)
+	assert.Equal(t, dir, syncedDir)
+
+	assert.NoError(t, afero.WriteFile(fs, filepath.Join(dir, vmDir, "foovm.yaml"), []byte("v1"), perms.ReadWrite))
+	first, err := Checksum(fs, dir)
+	assert.NoError(t, err)
+	assert.NotEqual(t, empty, first)
+	assert.NotEqual(t, plumbing.ZeroHash, first)
+
+	// The version only changes with the definitions.
+	assert.NoError(t, afero.WriteFile(fs, filepath.Join(dir, "README.md"), []byte("readme"), perms.ReadWrite))
+	unchanged, err := Checksum(fs, dir)
+	assert.NoError(t, err)
+	assert.Equal(t, first, unchanged)
+
+	assert.NoError(t, afero.WriteFile(fs, filepath.Join(dir, vmDir, "foovm.yaml"), []byte("v2"), perms.ReadWrite))
+	_, second, err := local.Sync(sourceInfo, "repository")
+	assert.NoError(t, err)
+	assert.NotEqual(t, first, second)
+
+	assert.NoError(t, fs.Rename(filepath.Join(dir, vmDir, "foovm.yaml"), filepath.Join(dir, subnetDir, "foovm.yaml")))
+	moved, err := Checksum(fs, dir)
+	assert.NoError(t, err)
+	assert.NotEqual(t, second, moved)
+}
@@ -0,0 +1,24 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package source
+
+import (
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/DioneProtocol/opm/storage"
+)
+
+const (
+	vmDir     = "vms"
+	subnetDir = "subnets"
+)
+
+// Source fetches the definitions of a repository.
+type Source interface {
+	// Sync fetches the latest definitions of the repository [sourceInfo]
+	// describes. [path] is the directory the opm keeps the repository in.
+	// Returns the directory the definitions are in, which has a vms and a
+	// subnets directory, and the version of the definitions.
+	Sync(sourceInfo storage.SourceInfo, path string) (string, plumbing.Hash, error)
+}
@@ -0,0 +1,78 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package storage
+
+import (
+	"github.com/DioneProtocol/odysseygo/database"
+	"gopkg.in/yaml.v3"
+)
+
+var _ AnyBatch = &Batch[any]{}
+
+// AnyBatch is a batch of writes to a store of any type.
+type AnyBatch interface {
+	// Inner returns the underlying database batch.
+	Inner() database.Batch
+}
+
+func NewBatch[V any](batch database.Batch) *Batch[V] {
+	return &Batch[V]{
+		batch: batch,
+	}
+}
+
+// Batch buffers writes to a store until they're written atomically. Reads
+// from the store don't see the buffered writes.
+type Batch[V any] struct {
+	batch database.Batch
+}
+
+func (b *Batch[V]) Put(key []byte, value V) error {
+	valueBytes, err := yaml.Marshal(value)
+	if err != nil {
+		return err
+	}
+	return b.batch.Put(key, valueBytes)
+}
+
+func (b *Batch[V]) Delete(key []byte) error {
+	return b.batch.Delete(key)
+}
+
+func (b *Batch[V]) Write() error {
+	return b.batch.Write()
+}
+
+func (b *Batch[V]) Inner() database.Batch {
+	return b.batch
+}
+
+// WriteAll atomically writes [batches] of several stores. The stores must
+// share the same underlying database.
+func WriteAll(batches ...AnyBatch) error {
+	if len(batches) == 0 {
+		return nil
+	}
+
+	// Stores are prefixed views of the same database, so their batches are
+	// combined by replaying their fully prefixed writes into one batch on that
+	// database.
+	combined := baseBatch(batches[0].Inner())
+	for _, batch := range batches[1:] {
+		if err := baseBatch(batch.Inner()).Replay(combined); err != nil {
+			return err
+		}
+	}
+
+	return combined.Write()
+}
+
+// baseBatch returns the batch writing to the database at the bottom of
+// [batch]'s stack of databases.
+func baseBatch(batch database.Batch) database.Batch {
+	for inner := batch.Inner(); inner != batch; inner = batch.Inner() {
+		batch = inner
+	}
+	return batch
+}
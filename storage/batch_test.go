@@ -0,0 +1,85 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestBatch(t *testing.T) {
+	db := memdb.New()
+	sourcesList := NewSourceInfo(db)
+
+	assert.NoError(t, sourcesList.Put([]byte("foo/baz"), SourceInfo{Alias: "foo/baz"}))
+
+	batch := sourcesList.NewBatch()
+	assert.NoError(t, batch.Put([]byte("foo/bar"), SourceInfo{Alias: "foo/bar"}))
+	assert.NoError(t, batch.Delete([]byte("foo/baz")))
+
+	// Nothing is written until the batch is.
+	_, err := sourcesList.Get([]byte("foo/bar"))
+	assert.ErrorIs(t, err, database.ErrNotFound)
+	ok, err := sourcesList.Has([]byte("foo/baz"))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.NoError(t, batch.Write())
+
+	source, err := sourcesList.Get([]byte("foo/bar"))
+	assert.NoError(t, err)
+	assert.Equal(t, SourceInfo{Alias: "foo/bar"}, source)
+	ok, err = sourcesList.Has([]byte("foo/baz"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestWriteAll(t *testing.T) {
+	db := memdb.New()
+	sourcesList := NewSourceInfo(db)
+	registry := NewVMRegistry(db)
+	// Repositories are nested two prefixes deep.
+	repository := NewRepositoryFactory(db).GetRepository([]byte("foo/bar"))
+
+	assert.NoError(t, registry.Put([]byte("stale"), RepoList{Repositories: []string{"foo/bar"}}))
+
+	sourcesBatch := sourcesList.NewBatch()
+	registryBatch := registry.NewBatch()
+	vmsBatch := repository.VMs.NewBatch()
+
+	vm := Definition[types.VM]{Definition: types.VM{Alias: "foovm", Maintainers: []string{}}}
+	assert.NoError(t, sourcesBatch.Put([]byte("foo/bar"), SourceInfo{Alias: "foo/bar"}))
+	assert.NoError(t, registryBatch.Put([]byte("foovm"), RepoList{Repositories: []string{"foo/bar"}}))
+	assert.NoError(t, registryBatch.Delete([]byte("stale")))
+	assert.NoError(t, vmsBatch.Put([]byte("foovm"), vm))
+
+	count, err := database.Count(db)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	assert.NoError(t, WriteAll(sourcesBatch, registryBatch, vmsBatch))
+
+	source, err := sourcesList.Get([]byte("foo/bar"))
+	assert.NoError(t, err)
+	assert.Equal(t, SourceInfo{Alias: "foo/bar"}, source)
+
+	repoList, err := registry.Get([]byte("foovm"))
+	assert.NoError(t, err)
+	assert.Equal(t, RepoList{Repositories: []string{"foo/bar"}}, repoList)
+
+	ok, err := registry.Has([]byte("stale"))
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	got, err := repository.VMs.Get([]byte("foovm"))
+	assert.NoError(t, err)
+	assert.Equal(t, vm, got)
+
+	assert.NoError(t, WriteAll())
+}
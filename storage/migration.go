@@ -0,0 +1,260 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/database/prefixdb"
+	"github.com/DioneProtocol/odysseygo/database/versiondb"
+	"github.com/go-git/go-git/v5/plumbing"
+)
+
+// schemaVersionKey stores the schema version of the database. Prefixed stores
+// hash their prefix, so this can't collide with any of their keys.
+var schemaVersionKey = []byte("schema_version")
+
+var ErrSchemaTooNew = errors.New("database schema is newer than this version of the opm")
+
+// Migration upgrades the database schema by one version.
+type Migration struct {
+	Description string
+	// Migrate rewrites the records in [db] to the new schema. Writes are
+	// committed atomically once it returns successfully.
+	Migrate func(db database.Database) error
+}
+
+// Migrations are the schema migrations in the order they're run. The migration
+// at index i upgrades the schema from version i to i+1, so entries must only
+// ever be appended.
+var Migrations = []Migration{
+	{
+		// Databases written before schema versioning have no version key, and
+		// are already in the first schema.
+		Description: "record the schema version",
+		Migrate:     func(database.Database) error { return nil },
+	},
+	{
+		Description: "split the registry into separate VM and subnet registries",
+		Migrate:     splitRegistry,
+	},
+	{
+		Description: "record how repositories are tracked",
+		Migrate:     recordReferenceTypes,
+	},
+	{
+		Description: "record where repositories come from",
+		Migrate:     recordSourceTypes,
+	},
+	{
+		Description: "resync definitions to store subnet configs as they're written",
+		Migrate:     resyncDefinitions,
+	},
+}
+
+// SchemaVersion is the schema version written by this version of the opm.
+func SchemaVersion() uint64 {
+	return uint64(len(Migrations))
+}
+
+// GetSchemaVersion returns the schema version of [db]. Databases written
+// before schema versioning are version 0.
+func GetSchemaVersion(db database.KeyValueReader) (uint64, error) {
+	version, err := database.GetUInt64(db, schemaVersionKey)
+	if err == database.ErrNotFound {
+		return 0, nil
+	}
+
+	return version, err
+}
+
+// Migrate upgrades [db] to the latest schema by running [migrations] in order.
+// [backup] is called with the current schema version before the first
+// migration runs. Each migration is committed atomically along with the new
+// schema version, so a failed migration leaves the database at the version
+// before it. An empty database is at the latest schema already.
+func Migrate(db database.Database, migrations []Migration, backup func(version uint64) error) error {
+	latest := uint64(len(migrations))
+
+	has, err := db.Has(schemaVersionKey)
+	if err != nil {
+		return err
+	}
+	if !has {
+		empty, err := database.IsEmpty(db)
+		if err != nil {
+			return err
+		}
+		if empty {
+			return database.PutUInt64(db, schemaVersionKey, latest)
+		}
+	}
+
+	version, err := GetSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	if version > latest {
+		return fmt.Errorf("%w (database is at version %d, but only versions up to %d are supported). Upgrade the opm", ErrSchemaTooNew, version, latest)
+	}
+	if version == latest {
+		return nil
+	}
+
+	if err := backup(version); err != nil {
+		return fmt.Errorf("failed to back up the database before migrating: %w", err)
+	}
+
+	for ; version < latest; version++ {
+		migration := migrations[version]
+		fmt.Printf("Migrating database to version %d: %s...\n", version+1, migration.Description)
+
+		tx := versiondb.New(db)
+		if err := migration.Migrate(tx); err != nil {
+			tx.Abort()
+			return fmt.Errorf("failed to migrate database to version %d: %w", version+1, err)
+		}
+		if err := database.PutUInt64(tx, schemaVersionKey, version+1); err != nil {
+			tx.Abort()
+			return err
+		}
+		if err := tx.Commit(); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// legacyRegistryPrefix is the prefix of the registry shared by VMs and subnets
+// before schema version 2.
+var legacyRegistryPrefix = []byte("registry")
+
+// splitRegistry moves each alias in the shared registry into the VM and subnet
+// registries, according to which of its repositories define a VM or a subnet
+// by that alias. Repositories that define neither are dropped.
+func splitRegistry(db database.Database) error {
+	legacy := &Database[RepoList]{
+		db: prefixdb.New(legacyRegistryPrefix, db),
+	}
+	registry := NewRegistry(db)
+	repoFactory := NewRepositoryFactory(db)
+
+	// The registry is read in full before it's rewritten, so it isn't modified
+	// while it's being iterated.
+	entries := map[string]RepoList{}
+	itr := legacy.Iterator()
+	for itr.Next() {
+		repoList, err := itr.Value()
+		if err != nil {
+			itr.Release()
+			return err
+		}
+		entries[string(itr.Key())] = repoList
+	}
+	err := itr.Error()
+	itr.Release()
+	if err != nil {
+		return err
+	}
+
+	for alias, repoList := range entries {
+		aliasBytes := []byte(alias)
+
+		vms, subnets := []string{}, []string{}
+		for _, repositoryAlias := range repoList.Repositories {
+			repository := repoFactory.GetRepository([]byte(repositoryAlias))
+
+			isVM, err := repository.VMs.Has(aliasBytes)
+			if err != nil {
+				return err
+			}
+			if isVM {
+				vms = append(vms, repositoryAlias)
+			}
+
+			isSubnet, err := repository.Subnets.Has(aliasBytes)
+			if err != nil {
+				return err
+			}
+			if isSubnet {
+				subnets = append(subnets, repositoryAlias)
+			}
+		}
+
+		if len(vms) > 0 {
+			if err := registry.VMs.Put(aliasBytes, RepoList{Repositories: vms}); err != nil {
+				return err
+			}
+		}
+		if len(subnets) > 0 {
+			if err := registry.Subnets.Put(aliasBytes, RepoList{Repositories: subnets}); err != nil {
+				return err
+			}
+		}
+		if err := legacy.Delete(aliasBytes); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// recordReferenceTypes marks every repository as tracking its branch, which
+// was the only way to track repositories before schema version 3.
+func recordReferenceTypes(db database.Database) error {
+	return updateSources(db, func(sourceInfo *SourceInfo) {
+		sourceInfo.ReferenceType = BranchReference
+	})
+}
+
+// recordSourceTypes marks every repository as a git repository, which was the
+// only source of repositories before schema version 4.
+func recordSourceTypes(db database.Database) error {
+	return updateSources(db, func(sourceInfo *SourceInfo) {
+		sourceInfo.Source = GitSource
+	})
+}
+
+// resyncDefinitions clears the commit every repository was synced to, so all
+// of their definitions are synced again on the next update. Subnet configs
+// were stored with every key of odysseygo's subnet config before schema
+// version 5, rather than only the keys their definition sets.
+func resyncDefinitions(db database.Database) error {
+	return updateSources(db, func(sourceInfo *SourceInfo) {
+		sourceInfo.Commit = plumbing.ZeroHash
+	})
+}
+
+// updateSources rewrites every repository in [db] with [update].
+func updateSources(db database.Database, update func(*SourceInfo)) error {
+	sourcesList := NewSourceInfo(db)
+
+	sources := map[string]SourceInfo{}
+	itr := sourcesList.Iterator()
+	for itr.Next() {
+		sourceInfo, err := itr.Value()
+		if err != nil {
+			itr.Release()
+			return err
+		}
+		sources[string(itr.Key())] = sourceInfo
+	}
+	err := itr.Error()
+	itr.Release()
+	if err != nil {
+		return err
+	}
+
+	for alias, sourceInfo := range sources {
+		update(&sourceInfo)
+		if err := sourcesList.Put([]byte(alias), sourceInfo); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,285 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package storage
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/database/prefixdb"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestMigrate(t *testing.T) {
+	errFailed := errors.New("failed")
+
+	// Each migration renames key "v<i>" to "v<i+1>", so the key left in the
+	// database shows which migrations ran.
+	rename := func(from string, to string) Migration {
+		return Migration{
+			Description: "rename " + from,
+			Migrate: func(db database.Database) error {
+				value, err := db.Get([]byte(from))
+				if err != nil {
+					return err
+				}
+				if err := db.Delete([]byte(from)); err != nil {
+					return err
+				}
+				return db.Put([]byte(to), value)
+			},
+		}
+	}
+	migrations := []Migration{rename("v0", "v1"), rename("v1", "v2")}
+
+	tests := []struct {
+		name        string
+		setup       func(*testing.T, database.Database)
+		migrations  []Migration
+		wantVersion uint64
+		wantKey     string
+		wantBackup  bool
+		wantErr     error
+	}{
+		{
+			name:        "empty database",
+			setup:       func(*testing.T, database.Database) {},
+			migrations:  migrations,
+			wantVersion: 2,
+		},
+		{
+			name: "unversioned database",
+			setup: func(t *testing.T, db database.Database) {
+				assert.NoError(t, db.Put([]byte("v0"), []byte("value")))
+			},
+			migrations:  migrations,
+			wantVersion: 2,
+			wantKey:     "v2",
+			wantBackup:  true,
+		},
+		{
+			name: "partially migrated database",
+			setup: func(t *testing.T, db database.Database) {
+				assert.NoError(t, database.PutUInt64(db, schemaVersionKey, 1))
+				assert.NoError(t, db.Put([]byte("v1"), []byte("value")))
+			},
+			migrations:  migrations,
+			wantVersion: 2,
+			wantKey:     "v2",
+			wantBackup:  true,
+		},
+		{
+			name: "up to date database",
+			setup: func(t *testing.T, db database.Database) {
+				assert.NoError(t, database.PutUInt64(db, schemaVersionKey, 2))
+				assert.NoError(t, db.Put([]byte("v2"), []byte("value")))
+			},
+			migrations:  migrations,
+			wantVersion: 2,
+			wantKey:     "v2",
+		},
+		{
+			name: "newer database",
+			setup: func(t *testing.T, db database.Database) {
+				assert.NoError(t, database.PutUInt64(db, schemaVersionKey, 3))
+			},
+			migrations:  migrations,
+			wantVersion: 3,
+			wantErr:     ErrSchemaTooNew,
+		},
+		{
+			name: "failed migration is rolled back",
+			setup: func(t *testing.T, db database.Database) {
+				assert.NoError(t, db.Put([]byte("v0"), []byte("value")))
+			},
+			migrations: []Migration{
+				rename("v0", "v1"),
+				{
+					Description: "fail after writing",
+					Migrate: func(db database.Database) error {
+						if err := db.Delete([]byte("v1")); err != nil {
+							return err
+						}
+						return errFailed
+					},
+				},
+			},
+			wantVersion: 1,
+			wantKey:     "v1",
+			wantBackup:  true,
+			wantErr:     errFailed,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := memdb.New()
+			test.setup(t, db)
+
+			backedUp := false
+			err := Migrate(db, test.migrations, func(uint64) error {
+				backedUp = true
+				return nil
+			})
+			assert.ErrorIs(t, err, test.wantErr)
+			assert.Equal(t, test.wantBackup, backedUp)
+
+			version, err := GetSchemaVersion(db)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantVersion, version)
+
+			if test.wantKey != "" {
+				value, err := db.Get([]byte(test.wantKey))
+				assert.NoError(t, err)
+				assert.Equal(t, []byte("value"), value)
+			}
+		})
+	}
+}
+
+func TestMigrateBackupFailure(t *testing.T) {
+	db := memdb.New()
+	assert.NoError(t, db.Put([]byte("foo"), []byte("bar")))
+
+	errFailed := errors.New("failed")
+	migrated := false
+	err := Migrate(db, []Migration{
+		{
+			Migrate: func(database.Database) error {
+				migrated = true
+				return nil
+			},
+		},
+	}, func(uint64) error {
+		return errFailed
+	})
+	assert.ErrorIs(t, err, errFailed)
+	assert.False(t, migrated)
+
+	version, err := GetSchemaVersion(db)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), version)
+}
+
+func TestSplitRegistry(t *testing.T) {
+	db := memdb.New()
+	legacy := &Database[RepoList]{
+		db: prefixdb.New(legacyRegistryPrefix, db),
+	}
+	repoFactory := NewRepositoryFactory(db)
+
+	foo := repoFactory.GetRepository([]byte("organization/foo"))
+	bar := repoFactory.GetRepository([]byte("organization/bar"))
+	assert.NoError(t, foo.VMs.Put([]byte("spaces"), Definition[types.VM]{}))
+	assert.NoError(t, foo.VMs.Put([]byte("foovm"), Definition[types.VM]{}))
+	assert.NoError(t, bar.Subnets.Put([]byte("spaces"), Definition[types.Subnet]{}))
+
+	// spaces is a VM in foo and a subnet in bar, and stalevm isn't defined
+	// anymore.
+	assert.NoError(t, legacy.Put([]byte("spaces"), RepoList{Repositories: []string{"organization/bar", "organization/foo"}}))
+	assert.NoError(t, legacy.Put([]byte("foovm"), RepoList{Repositories: []string{"organization/foo"}}))
+	assert.NoError(t, legacy.Put([]byte("stalevm"), RepoList{Repositories: []string{"organization/foo"}}))
+
+	assert.NoError(t, splitRegistry(db))
+
+	registry := NewRegistry(db)
+	for _, test := range []struct {
+		registry Storage[RepoList]
+		alias    string
+		want     []string
+	}{
+		{registry: registry.VMs, alias: "spaces", want: []string{"organization/foo"}},
+		{registry: registry.VMs, alias: "foovm", want: []string{"organization/foo"}},
+		{registry: registry.Subnets, alias: "spaces", want: []string{"organization/bar"}},
+		{registry: registry.VMs, alias: "stalevm"},
+		{registry: registry.Subnets, alias: "foovm"},
+		{registry: registry.Subnets, alias: "stalevm"},
+	} {
+		repoList, err := test.registry.Get([]byte(test.alias))
+		if test.want == nil {
+			assert.ErrorIs(t, err, database.ErrNotFound)
+			continue
+		}
+		assert.NoError(t, err)
+		assert.Equal(t, RepoList{Repositories: test.want}, repoList)
+	}
+
+	empty, err := database.IsEmpty(legacy.db)
+	assert.NoError(t, err)
+	assert.True(t, empty)
+}
+
+func TestRecordReferenceTypes(t *testing.T) {
+	db := memdb.New()
+	sourcesList := NewSourceInfo(db)
+
+	legacy := SourceInfo{
+		Alias:  "organization/repository",
+		URL:    "url",
+		Commit: plumbing.NewHash("0123456789abcdef0123456789abcdef01234567"),
+		Branch: "refs/heads/master",
+	}
+	assert.NoError(t, sourcesList.Put([]byte(legacy.Alias), legacy))
+
+	assert.NoError(t, recordReferenceTypes(db))
+
+	sourceInfo, err := sourcesList.Get([]byte(legacy.Alias))
+	assert.NoError(t, err)
+
+	want := legacy
+	want.ReferenceType = BranchReference
+	assert.Equal(t, want, sourceInfo)
+}
+
+func TestRecordSourceTypes(t *testing.T) {
+	db := memdb.New()
+	sourcesList := NewSourceInfo(db)
+
+	legacy := SourceInfo{
+		Alias:         "organization/repository",
+		URL:           "url",
+		Branch:        "refs/heads/master",
+		ReferenceType: BranchReference,
+	}
+	assert.NoError(t, sourcesList.Put([]byte(legacy.Alias), legacy))
+
+	assert.NoError(t, recordSourceTypes(db))
+
+	sourceInfo, err := sourcesList.Get([]byte(legacy.Alias))
+	assert.NoError(t, err)
+
+	want := legacy
+	want.Source = GitSource
+	assert.Equal(t, want, sourceInfo)
+	assert.Equal(t, "master", sourceInfo.Tracked())
+}
+
+func TestResyncDefinitions(t *testing.T) {
+	db := memdb.New()
+	sourcesList := NewSourceInfo(db)
+
+	synced := SourceInfo{
+		Alias:         "organization/repository",
+		URL:           "url",
+		Commit:        plumbing.NewHash("0123456789abcdef0123456789abcdef01234567"),
+		Branch:        "refs/heads/master",
+		ReferenceType: BranchReference,
+		Source:        GitSource,
+	}
+	assert.NoError(t, sourcesList.Put([]byte(synced.Alias), synced))
+
+	assert.NoError(t, resyncDefinitions(db))
+
+	sourceInfo, err := sourcesList.Get([]byte(synced.Alias))
+	assert.NoError(t, err)
+
+	want := synced
+	want.Commit = plumbing.ZeroHash
+	assert.Equal(t, want, sourceInfo)
+}
@@ -94,6 +94,20 @@ func (mr *MockStorageMockRecorder[V]) Iterator() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Iterator", reflect.TypeOf((*MockStorage[V])(nil).Iterator))
 }
 
+// NewBatch mocks base method.
+func (m *MockStorage[V]) NewBatch() *Batch[V] {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewBatch")
+	ret0, _ := ret[0].(*Batch[V])
+	return ret0
+}
+
+// NewBatch indicates an expected call of NewBatch.
+func (mr *MockStorageMockRecorder[V]) NewBatch() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewBatch", reflect.TypeOf((*MockStorage[V])(nil).NewBatch))
+}
+
 // Put mocks base method.
 func (m *MockStorage[V]) Put(key []byte, value V) error {
 	m.ctrl.T.Helper()
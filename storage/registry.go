@@ -0,0 +1,23 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package storage
+
+import (
+	"github.com/DioneProtocol/odysseygo/database"
+)
+
+// Registry maps the aliases of VMs and subnets to the repositories that
+// define them. VMs and subnets have separate namespaces, so a VM and a subnet
+// may share an alias.
+type Registry struct {
+	VMs     Storage[RepoList]
+	Subnets Storage[RepoList]
+}
+
+func NewRegistry(db database.Database) Registry {
+	return Registry{
+		VMs:     NewVMRegistry(db),
+		Subnets: NewSubnetRegistry(db),
+	}
+}
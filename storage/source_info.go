@@ -10,12 +10,63 @@ import (
 	"github.com/DioneProtocol/opm/types"
 )
 
+// SourceType is where the definitions of a repository come from.
+type SourceType string
+
+const (
+	// GitSource is a git repository.
+	GitSource SourceType = "git"
+	// HTTPSource is a signed index listing every definition, served over
+	// http(s).
+	HTTPSource SourceType = "http"
+	// LocalSource is a directory on this machine.
+	LocalSource SourceType = "local"
+)
+
+// ReferenceType is how a repository is tracked.
+type ReferenceType string
+
+const (
+	// BranchReference follows the latest commit of a branch.
+	BranchReference ReferenceType = "branch"
+	// TagReference stays at a tag.
+	TagReference ReferenceType = "tag"
+	// CommitReference stays at a commit.
+	CommitReference ReferenceType = "commit"
+	// TagPatternReference follows the newest tag matching a pattern, by
+	// semantic version.
+	TagPatternReference ReferenceType = "tag-pattern"
+)
+
 // SourceInfo represents a repository, its source, and the last synced commit.
 type SourceInfo struct {
 	Alias  string                 `yaml:"alias"`
 	URL    string                 `yaml:"url"`
 	Commit plumbing.Hash          `yaml:"commit"`
 	Branch plumbing.ReferenceName `yaml:"branch"`
+
+	// ReferenceType is how the repository is tracked. Branch is only set for
+	// branches, and Reference is the tag, commit or tag pattern otherwise.
+	// Only git sources are tracked by a reference.
+	ReferenceType ReferenceType `yaml:"referenceType,omitempty"`
+	Reference     string        `yaml:"reference,omitempty"`
+
+	// Source is where the definitions come from. URL is the path of the
+	// directory for local sources.
+	Source SourceType `yaml:"source"`
+	// PublicKey is the hex-encoded ed25519 key the index of an http source is
+	// signed with.
+	PublicKey string `yaml:"publicKey,omitempty"`
+}
+
+// Tracked returns the branch, tag, commit or tag pattern the repository is
+// tracked by.
+func (s SourceInfo) Tracked() string {
+	if s.Source == GitSource && s.ReferenceType == BranchReference {
+		return s.Branch.Short()
+	}
+
+	return s.Reference
 }
 
 // RepoList is a list of repositories that support a single plugin alias.
@@ -24,9 +75,36 @@ type RepoList struct {
 	Repositories []string `yaml:"repositories"`
 }
 
+// InstallInfo represents an installed VM, the version that was installed, and
+// where it was installed from.
 type InstallInfo struct {
-	ID      string           `yaml:"id"`
-	Version version.Semantic `yaml:"version"`
+	ID         string           `yaml:"id"`
+	Version    version.Semantic `yaml:"version"`
+	Commit     plumbing.Hash    `yaml:"commit"`
+	BinaryPath string           `yaml:"binaryPath"`
+	// URL and SHA256 identify the artifact the VM was built from.
+	URL    string `yaml:"url"`
+	SHA256 string `yaml:"sha256"`
+}
+
+// Installs returns true if the installed artifact is the one [vm] defines.
+func (i InstallInfo) Installs(vm types.VM) bool {
+	return i.Version.Compare(&vm.Version) == 0 && i.URL == vm.URL && i.SHA256 == vm.SHA256
+}
+
+// JoinInfo represents a subnet the node was configured to track by the opm.
+type JoinInfo struct {
+	ID string `yaml:"id"`
+}
+
+// ConfigFile is a node config file written by the opm.
+type ConfigFile struct {
+	// Owner is the fully qualified name of the VM or subnet the file was
+	// installed for.
+	Owner string `yaml:"owner"`
+	// Contents are the contents of the file as provided by its definition. This
+	// is used as the base when merging in changes from newer definitions.
+	Contents string `yaml:"contents"`
 }
 
 // Definition stores a plugin definition alongside the plugin-repository's commit
@@ -0,0 +1,25 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestInstallInfoInstalls(t *testing.T) {
+	installInfo := InstallInfo{Version: version.Semantic{Major: 1}, URL: "url", SHA256: "sha256"}
+	// Formatting a version caches its string, which mustn't affect the
+	// comparison.
+	_ = installInfo.Version.String()
+
+	assert.True(t, installInfo.Installs(types.VM{Version: version.Semantic{Major: 1}, URL: "url", SHA256: "sha256"}))
+	assert.False(t, installInfo.Installs(types.VM{Version: version.Semantic{Major: 2}, URL: "url", SHA256: "sha256"}))
+	assert.False(t, installInfo.Installs(types.VM{Version: version.Semantic{Major: 1}, URL: "other", SHA256: "sha256"}))
+	assert.False(t, installInfo.Installs(types.VM{Version: version.Semantic{Major: 1}, URL: "url", SHA256: "other"}))
+}
@@ -12,11 +12,14 @@ import (
 )
 
 var (
-	sourceInfoPrefix   = []byte("source_info")
-	vmPrefix           = []byte("vm")
-	subnetPrefix       = []byte("subnet")
-	registryPrefix     = []byte("registry")
-	installedVMsPrefix = []byte("installed_vms")
+	sourceInfoPrefix     = []byte("source_info")
+	vmPrefix             = []byte("vm")
+	subnetPrefix         = []byte("subnet")
+	vmRegistryPrefix     = []byte("vm_registry")
+	subnetRegistryPrefix = []byte("subnet_registry")
+	installedVMsPrefix   = []byte("installed_vms")
+	configFilesPrefix    = []byte("config_files")
+	joinedSubnetsPrefix  = []byte("joined_subnets")
 
 	_ Storage[any] = &Database[any]{}
 )
@@ -27,7 +30,8 @@ type Storage[V any] interface {
 	Get(key []byte) (V, error)
 	Delete(key []byte) error
 	Iterator() Iterator[V]
-	// TODO batching
+	// NewBatch returns a batch of writes to this store.
+	NewBatch() *Batch[V]
 }
 
 func NewSourceInfo(db database.Database) *Database[SourceInfo] {
@@ -48,9 +52,15 @@ func NewSubnet(db database.Database) *Database[Definition[types.Subnet]] {
 	}
 }
 
-func NewRegistry(db database.Database) *Database[RepoList] {
+func NewVMRegistry(db database.Database) *Database[RepoList] {
 	return &Database[RepoList]{
-		db: prefixdb.New(registryPrefix, db),
+		db: prefixdb.New(vmRegistryPrefix, db),
+	}
+}
+
+func NewSubnetRegistry(db database.Database) *Database[RepoList] {
+	return &Database[RepoList]{
+		db: prefixdb.New(subnetRegistryPrefix, db),
 	}
 }
 
@@ -60,6 +70,18 @@ func NewInstalledVMs(db database.Database) *Database[InstallInfo] {
 	}
 }
 
+func NewConfigFiles(db database.Database) *Database[ConfigFile] {
+	return &Database[ConfigFile]{
+		db: prefixdb.New(configFilesPrefix, db),
+	}
+}
+
+func NewJoinedSubnets(db database.Database) *Database[JoinInfo] {
+	return &Database[JoinInfo]{
+		db: prefixdb.New(joinedSubnetsPrefix, db),
+	}
+}
+
 type Database[V any] struct {
 	db database.Database
 }
@@ -94,6 +116,10 @@ func (c *Database[V]) Delete(key []byte) error {
 	return c.db.Delete(key)
 }
 
+func (c *Database[V]) NewBatch() *Batch[V] {
+	return NewBatch[V](c.db.NewBatch())
+}
+
 func (c *Database[V]) Iterator() Iterator[V] {
 	return Iterator[V]{
 		itr: c.db.NewIterator(),
@@ -0,0 +1,58 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	errMissingChain = errors.New("missing chain")
+	errInvalidChain = errors.New("chain must be a chain ID or alias")
+)
+
+// ChainConfig holds the default config and network upgrade files for a chain.
+// They're installed into the node's chain config directory under the chain's
+// ID or alias.
+type ChainConfig struct {
+	// Chain is the chain ID or alias the node reads these files for.
+	Chain string `yaml:"chain"`
+	// Config is the contents of the chain's config.json.
+	Config string `yaml:"config,omitempty"`
+	// Upgrade is the contents of the chain's upgrade.json.
+	Upgrade string `yaml:"upgrade,omitempty"`
+}
+
+func (c ChainConfig) Verify() error {
+	switch {
+	case c.Chain == "":
+		return errMissingChain
+	case c.Chain == "." || c.Chain == ".." || strings.ContainsAny(c.Chain, `/\`):
+		return fmt.Errorf("%w: %q", errInvalidChain, c.Chain)
+	case c.Config != "" && !json.Valid([]byte(c.Config)):
+		return fmt.Errorf("config for chain %s isn't valid JSON", c.Chain)
+	case c.Upgrade != "" && !json.Valid([]byte(c.Upgrade)):
+		return fmt.Errorf("upgrade for chain %s isn't valid JSON", c.Chain)
+	default:
+		return nil
+	}
+}
+
+func verifyChainConfigs(chainConfigs []ChainConfig) error {
+	chains := make(map[string]struct{}, len(chainConfigs))
+	for _, chainConfig := range chainConfigs {
+		if err := chainConfig.Verify(); err != nil {
+			return err
+		}
+		if _, ok := chains[chainConfig.Chain]; ok {
+			return fmt.Errorf("duplicate chain config for %s", chainConfig.Chain)
+		}
+		chains[chainConfig.Chain] = struct{}{}
+	}
+
+	return nil
+}
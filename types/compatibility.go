@@ -0,0 +1,97 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/DioneProtocol/odysseygo/version"
+)
+
+var (
+	errInvalidNodeVersionRange = errors.New("minNodeVersion is greater than maxNodeVersion")
+	errIncompatibleProtocol    = errors.New("incompatible rpcchainvm protocol version")
+	errIncompatibleNodeVersion = errors.New("unsupported node version")
+)
+
+// Compatibility describes the nodes that are able to load a VM. Fields that
+// aren't set aren't checked.
+type Compatibility struct {
+	// RPCChainVMProtocol is the rpcchainvm protocol version the VM was built
+	// against.
+	RPCChainVMProtocol uint32 `yaml:"rpcChainVMProtocol,omitempty"`
+	// MinNodeVersion is the oldest odysseygo version supported (e.g. v1.10.0).
+	MinNodeVersion string `yaml:"minNodeVersion,omitempty"`
+	// MaxNodeVersion is the newest odysseygo version supported (e.g. v1.10.10).
+	MaxNodeVersion string `yaml:"maxNodeVersion,omitempty"`
+}
+
+// Declared returns true if any compatibility constraints are set.
+func (c Compatibility) Declared() bool {
+	return c != Compatibility{}
+}
+
+func (c Compatibility) Verify() error {
+	minVersion, maxVersion, err := c.versionRange()
+	if err != nil {
+		return err
+	}
+
+	if minVersion != nil && maxVersion != nil && minVersion.Compare(maxVersion) > 0 {
+		return errInvalidNodeVersionRange
+	}
+
+	return nil
+}
+
+// Check returns an error if a node running [nodeVersion] that speaks
+// [rpcProtocolVersion] isn't able to load the VM.
+func (c Compatibility) Check(nodeVersion version.Semantic, rpcProtocolVersion uint32) error {
+	if c.RPCChainVMProtocol != 0 && c.RPCChainVMProtocol != rpcProtocolVersion {
+		return fmt.Errorf(
+			"%w: VM requires %d but the node uses %d",
+			errIncompatibleProtocol,
+			c.RPCChainVMProtocol,
+			rpcProtocolVersion,
+		)
+	}
+
+	minVersion, maxVersion, err := c.versionRange()
+	if err != nil {
+		return err
+	}
+
+	if minVersion != nil && nodeVersion.Compare(minVersion) < 0 {
+		return fmt.Errorf("%w: VM requires at least %s but the node is running %s", errIncompatibleNodeVersion, minVersion, &nodeVersion)
+	}
+	if maxVersion != nil && nodeVersion.Compare(maxVersion) > 0 {
+		return fmt.Errorf("%w: VM supports up to %s but the node is running %s", errIncompatibleNodeVersion, maxVersion, &nodeVersion)
+	}
+
+	return nil
+}
+
+func (c Compatibility) versionRange() (*version.Semantic, *version.Semantic, error) {
+	var (
+		minVersion, maxVersion *version.Semantic
+		err                    error
+	)
+
+	if c.MinNodeVersion != "" {
+		minVersion, err = version.Parse(c.MinNodeVersion)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid minNodeVersion: %w", err)
+		}
+	}
+
+	if c.MaxNodeVersion != "" {
+		maxVersion, err = version.Parse(c.MaxNodeVersion)
+		if err != nil {
+			return nil, nil, fmt.Errorf("invalid maxNodeVersion: %w", err)
+		}
+	}
+
+	return minVersion, maxVersion, nil
+}
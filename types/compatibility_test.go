@@ -0,0 +1,116 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompatibilityVerify(t *testing.T) {
+	tests := []struct {
+		name          string
+		compatibility Compatibility
+		wantErr       bool
+	}{
+		{
+			name: "unconstrained",
+		},
+		{
+			name: "valid range",
+			compatibility: Compatibility{
+				MinNodeVersion: "v1.10.0",
+				MaxNodeVersion: "v1.10.10",
+			},
+		},
+		{
+			name: "invalid min version",
+			compatibility: Compatibility{
+				MinNodeVersion: "1.10.0",
+			},
+			wantErr: true,
+		},
+		{
+			name: "invalid max version",
+			compatibility: Compatibility{
+				MaxNodeVersion: "latest",
+			},
+			wantErr: true,
+		},
+		{
+			name: "empty range",
+			compatibility: Compatibility{
+				MinNodeVersion: "v1.10.1",
+				MaxNodeVersion: "v1.10.0",
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := test.compatibility.Verify()
+			if test.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestCompatibilityCheck(t *testing.T) {
+	compatibility := Compatibility{
+		RPCChainVMProtocol: 28,
+		MinNodeVersion:     "v1.10.0",
+		MaxNodeVersion:     "v1.10.10",
+	}
+
+	tests := []struct {
+		name               string
+		nodeVersion        version.Semantic
+		rpcProtocolVersion uint32
+		wantErr            error
+	}{
+		{
+			name:               "compatible",
+			nodeVersion:        version.Semantic{Major: 1, Minor: 10, Patch: 10},
+			rpcProtocolVersion: 28,
+		},
+		{
+			name:               "wrong protocol",
+			nodeVersion:        version.Semantic{Major: 1, Minor: 10, Patch: 5},
+			rpcProtocolVersion: 27,
+			wantErr:            errIncompatibleProtocol,
+		},
+		{
+			name:               "node too old",
+			nodeVersion:        version.Semantic{Major: 1, Minor: 9, Patch: 16},
+			rpcProtocolVersion: 28,
+			wantErr:            errIncompatibleNodeVersion,
+		},
+		{
+			name:               "node too new",
+			nodeVersion:        version.Semantic{Major: 1, Minor: 11, Patch: 0},
+			rpcProtocolVersion: 28,
+			wantErr:            errIncompatibleNodeVersion,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := compatibility.Check(test.nodeVersion, test.rpcProtocolVersion)
+			if test.wantErr == nil {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, test.wantErr)
+			}
+		})
+	}
+
+	assert.False(t, Compatibility{}.Declared())
+	assert.NoError(t, Compatibility{}.Check(version.Semantic{}, 0))
+}
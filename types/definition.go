@@ -3,10 +3,30 @@
 
 package types
 
+import "errors"
+
+var (
+	errMissingID    = errors.New("missing id")
+	errMissingAlias = errors.New("missing alias")
+)
+
 type Definition interface {
 	GetID() string
 	GetAlias() string
 	GetHomepage() string
 	GetDescription() string
 	GetMaintainers() []string
+	// Verify returns an error if the definition is malformed.
+	Verify() error
+}
+
+func verifyIdentity(definition Definition) error {
+	switch {
+	case definition.GetID() == "":
+		return errMissingID
+	case definition.GetAlias() == "":
+		return errMissingAlias
+	default:
+		return nil
+	}
 }
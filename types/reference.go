@@ -0,0 +1,151 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DioneProtocol/opm/constant"
+	"github.com/DioneProtocol/opm/util"
+)
+
+const (
+	versionDelimiter = "@"
+	digestDelimiter  = "#"
+)
+
+var (
+	errInvalidReference = errors.New("invalid reference")
+	errVersionMismatch  = errors.New("version doesn't satisfy the reference")
+	errDigestMismatch   = errors.New("sha256 doesn't match the reference")
+)
+
+// Reference refers to a VM or subnet by its alias. Its grammar is
+//
+//	[vm:|subnet:][organization/repository:]name[@version][#sha256]
+//
+// The type prefix disambiguates between a VM and a subnet sharing an alias,
+// and the repository between repositories defining the same alias. A VM can be
+// pinned to versions satisfying a VersionConstraint (e.g. @^v1.2.0), and to
+// the sha256 of its release.
+type Reference struct {
+	// Type is constant.VMType, constant.SubnetType or empty.
+	Type string
+	// Repository is the alias of the repository, or empty for a partial
+	// alias.
+	Repository string
+	Name       string
+	Version    VersionConstraint
+	// Digest is the lowercase hex-encoded sha256, or empty.
+	Digest string
+}
+
+// ParseReference parses [s] into a Reference.
+func ParseReference(s string) (Reference, error) {
+	reference := Reference{}
+	rest := strings.TrimSpace(s)
+	if rest == "" {
+		return Reference{}, fmt.Errorf("%w: empty reference", errInvalidReference)
+	}
+
+	for _, kind := range []string{constant.VMType, constant.SubnetType} {
+		if prefix := kind + constant.QualifiedNameDelimiter; strings.HasPrefix(rest, prefix) {
+			reference.Type = kind
+			rest = strings.TrimPrefix(rest, prefix)
+			break
+		}
+	}
+
+	if i := strings.LastIndex(rest, digestDelimiter); i >= 0 {
+		digest := strings.ToLower(rest[i+1:])
+		if decoded, err := hex.DecodeString(digest); err != nil || len(decoded) != 32 {
+			return Reference{}, fmt.Errorf("%w %q: %q isn't a hex-encoded sha256", errInvalidReference, s, rest[i+1:])
+		}
+		reference.Digest = digest
+		rest = rest[:i]
+	}
+
+	if i := strings.Index(rest, versionDelimiter); i >= 0 {
+		raw := rest[i+1:]
+		if strings.TrimSpace(raw) == "" {
+			return Reference{}, fmt.Errorf("%w %q: missing version after %s", errInvalidReference, s, versionDelimiter)
+		}
+		constraint, err := ParseVersionConstraint(raw)
+		if err != nil {
+			return Reference{}, fmt.Errorf("%w %q: %s", errInvalidReference, s, err)
+		}
+		reference.Version = constraint
+		rest = rest[:i]
+	}
+
+	parts := strings.Split(rest, constant.QualifiedNameDelimiter)
+	switch len(parts) {
+	case 1:
+		reference.Name = parts[0]
+	case 2:
+		reference.Repository, reference.Name = parts[0], parts[1]
+		if !util.ValidAlias(reference.Repository) {
+			return Reference{}, fmt.Errorf("%w %q: repository %q isn't in the form of organization/repository", errInvalidReference, s, reference.Repository)
+		}
+	default:
+		return Reference{}, fmt.Errorf("%w %q: expected at most one %q between the repository and the name", errInvalidReference, s, constant.QualifiedNameDelimiter)
+	}
+
+	if !util.ValidName(reference.Name) {
+		return Reference{}, fmt.Errorf("%w %q: %q isn't a valid name", errInvalidReference, s, reference.Name)
+	}
+
+	return reference, nil
+}
+
+// Qualified returns true if the reference includes its repository.
+func (r Reference) Qualified() bool {
+	return r.Repository != ""
+}
+
+// QualifiedName returns the fully qualified name of the reference (e.g.
+// organization/repository:spacesvm).
+func (r Reference) QualifiedName() string {
+	if !r.Qualified() {
+		return r.Name
+	}
+
+	return r.Repository + constant.QualifiedNameDelimiter + r.Name
+}
+
+// Pinned returns true if the reference is pinned to a version or a digest.
+func (r Reference) Pinned() bool {
+	return r.Version.raw != "" || r.Digest != ""
+}
+
+// Check returns an error if [vm] doesn't satisfy the version and digest the
+// reference is pinned to.
+func (r Reference) Check(vm VM) error {
+	if !r.Version.Check(vm.Version) {
+		return fmt.Errorf("%w: %s is at %s, which doesn't satisfy %s", errVersionMismatch, r.QualifiedName(), vm.Version.String(), r.Version)
+	}
+	if r.Digest != "" && !strings.EqualFold(r.Digest, vm.SHA256) {
+		return fmt.Errorf("%w: %s has sha256 %s, but %s is pinned", errDigestMismatch, r.QualifiedName(), vm.SHA256, r.Digest)
+	}
+
+	return nil
+}
+
+func (r Reference) String() string {
+	s := r.QualifiedName()
+	if r.Type != "" {
+		s = r.Type + constant.QualifiedNameDelimiter + s
+	}
+	if r.Version.raw != "" {
+		s += versionDelimiter + r.Version.raw
+	}
+	if r.Digest != "" {
+		s += digestDelimiter + r.Digest
+	}
+
+	return s
+}
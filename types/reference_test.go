@@ -0,0 +1,118 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseReference(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+
+	tests := []struct {
+		reference      string
+		wantType       string
+		wantRepository string
+		wantName       string
+		wantVersion    string
+		wantDigest     string
+	}{
+		{reference: "spacesvm", wantName: "spacesvm"},
+		{reference: " spacesvm ", wantName: "spacesvm"},
+		{reference: "organization/repository:spacesvm", wantRepository: "organization/repository", wantName: "spacesvm"},
+		{reference: "vm:spacesvm", wantType: "vm", wantName: "spacesvm"},
+		{reference: "subnet:organization/repository:spaces", wantType: "subnet", wantRepository: "organization/repository", wantName: "spaces"},
+		// Organizations may share a name with a type.
+		{reference: "vm/repository:spacesvm", wantRepository: "vm/repository", wantName: "spacesvm"},
+		{reference: "spacesvm@v1.2.3", wantName: "spacesvm", wantVersion: "v1.2.3"},
+		{reference: "spacesvm@>=1.2.0,<2.0.0", wantName: "spacesvm", wantVersion: ">=1.2.0,<2.0.0"},
+		{reference: "spacesvm#" + strings.ToUpper(digest), wantName: "spacesvm", wantDigest: digest},
+		{
+			reference:      "vm:organization/repository:spacesvm@^v1.2.0#" + digest,
+			wantType:       "vm",
+			wantRepository: "organization/repository",
+			wantName:       "spacesvm",
+			wantVersion:    "^v1.2.0",
+			wantDigest:     digest,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.reference, func(t *testing.T) {
+			reference, err := ParseReference(test.reference)
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantType, reference.Type)
+			assert.Equal(t, test.wantRepository, reference.Repository)
+			assert.Equal(t, test.wantName, reference.Name)
+			assert.Equal(t, test.wantVersion, reference.Version.raw)
+			assert.Equal(t, test.wantDigest, reference.Digest)
+		})
+	}
+}
+
+func TestParseReferenceInvalid(t *testing.T) {
+	for _, reference := range []string{
+		"",
+		"vm:",
+		"a:b:c",
+		"organization:spacesvm",
+		"organization/repository/extra:spacesvm",
+		"organization/repository:",
+		":spacesvm",
+		"organization/repository:spaces/vm",
+		"spacesvm@",
+		"spacesvm@latest",
+		"spacesvm#",
+		"spacesvm#abc",
+		"spacesvm#" + strings.Repeat("zz", 32),
+	} {
+		t.Run(reference, func(t *testing.T) {
+			_, err := ParseReference(reference)
+			assert.ErrorIs(t, err, errInvalidReference)
+		})
+	}
+}
+
+func TestReferenceString(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	for _, s := range []string{
+		"spacesvm",
+		"subnet:organization/repository:spaces",
+		"vm:organization/repository:spacesvm@^v1.2.0#" + digest,
+	} {
+		reference, err := ParseReference(s)
+		assert.NoError(t, err)
+		assert.Equal(t, s, reference.String())
+	}
+}
+
+func TestReferenceCheck(t *testing.T) {
+	digest := strings.Repeat("ab", 32)
+	vm := VM{
+		Version: version.Semantic{Major: 1, Minor: 2, Patch: 3},
+		SHA256:  digest,
+	}
+
+	tests := []struct {
+		reference string
+		wantErr   error
+	}{
+		{reference: "spacesvm"},
+		{reference: "spacesvm@^v1.0.0#" + digest},
+		{reference: "spacesvm@v1.2.4", wantErr: errVersionMismatch},
+		{reference: "spacesvm#" + strings.Repeat("cd", 32), wantErr: errDigestMismatch},
+	}
+
+	for _, test := range tests {
+		t.Run(test.reference, func(t *testing.T) {
+			reference, err := ParseReference(test.reference)
+			assert.NoError(t, err)
+			assert.ErrorIs(t, reference.Check(vm), test.wantErr)
+		})
+	}
+}
@@ -3,8 +3,27 @@
 
 package types
 
-// import "github.com/DioneProtocol/odysseygo/subnets"
-var _ Definition = &Subnet{}
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/snow/consensus/snowball"
+	"github.com/DioneProtocol/odysseygo/subnets"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	_ Definition       = &Subnet{}
+	_ yaml.Unmarshaler = &Subnet{}
+
+	// durationKeys are the subnet config keys that hold a time.Duration.
+	durationKeys = map[string]struct{}{
+		"maxItemProcessingTime": {},
+		"proposerMinBlockDelay": {},
+	}
+)
 
 type Subnet struct {
 	ID          string   `yaml:"id"`
@@ -13,7 +32,14 @@ type Subnet struct {
 	Description string   `yaml:"description"`
 	Maintainers []string `yaml:"maintainers"`
 	VMs         []string `yaml:"vms"`
-	// Config      subnets.SubnetConfig `yaml:"config,omitempty"`
+	// Config is the node configuration for this subnet, keyed the same way as
+	// odysseygo's subnet config files. Only the keys it sets are written, so
+	// the node's defaults are used for the rest.
+	Config map[string]interface{} `yaml:"config,omitempty"`
+	// ChainConfigs are the default chain configs for the subnet's chains. They
+	// take precedence over chain configs for the same chain provided by the
+	// subnet's VMs.
+	ChainConfigs []ChainConfig `yaml:"chainConfigs,omitempty"`
 }
 
 func (s Subnet) GetID() string {
@@ -35,3 +61,89 @@ func (s Subnet) GetDescription() string {
 func (s Subnet) GetMaintainers() []string {
 	return s.Maintainers
 }
+
+// Verify returns an error if the subnet is missing its ID or alias, if it has
+// malformed chain configs, or if its config isn't a valid odysseygo subnet
+// config.
+func (s Subnet) Verify() error {
+	if err := verifyIdentity(s); err != nil {
+		return err
+	}
+
+	if err := verifyChainConfigs(s.ChainConfigs); err != nil {
+		return err
+	}
+
+	if s.Config == nil {
+		return nil
+	}
+
+	config, err := s.NodeConfig()
+	if err != nil {
+		return fmt.Errorf("invalid config for subnet %s: %w", s.ID, err)
+	}
+	if err := config.Valid(); err != nil {
+		return fmt.Errorf("invalid config for subnet %s: %w", s.ID, err)
+	}
+
+	return nil
+}
+
+// NodeConfig returns the config decoded over odysseygo's defaults, the same way
+// the node reads it.
+func (s Subnet) NodeConfig() (subnets.Config, error) {
+	configBytes, err := json.Marshal(s.Config)
+	if err != nil {
+		return subnets.Config{}, err
+	}
+
+	config := subnets.Config{
+		ConsensusParameters: snowball.DefaultParameters,
+	}
+	if err := json.Unmarshal(configBytes, &config); err != nil {
+		return subnets.Config{}, err
+	}
+
+	return config, nil
+}
+
+// UnmarshalYAML allows durations in the subnet config to be written as
+// duration strings (e.g. 2m). They're stored as integer nanoseconds, which is
+// how odysseygo reads them from its JSON configs.
+func (s *Subnet) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == "config" {
+				normalizeDurations(node.Content[i+1])
+			}
+		}
+	}
+
+	type subnet Subnet // prevents infinite recursion
+	return node.Decode((*subnet)(s))
+}
+
+// normalizeDurations rewrites the duration strings in [node] as integer
+// nanoseconds. Values that aren't durations are left as-is, so they fail to
+// verify instead of failing to parse.
+func normalizeDurations(node *yaml.Node) {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if _, ok := durationKeys[key.Value]; !ok || value.Tag != "!!str" {
+				continue
+			}
+
+			duration, err := time.ParseDuration(value.Value)
+			if err != nil {
+				continue
+			}
+			value.Tag = "!!int"
+			value.Value = strconv.FormatInt(int64(duration), 10)
+		}
+	}
+
+	for _, child := range node.Content {
+		normalizeDurations(child)
+	}
+}
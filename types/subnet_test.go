@@ -0,0 +1,154 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/snow/consensus/snowball"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestSubnetUnmarshalYAML(t *testing.T) {
+	tests := []struct {
+		name      string
+		subnet    string
+		want      time.Duration
+		wantErr   bool
+		verifyErr bool
+	}{
+		{
+			name: "integer nanoseconds",
+			subnet: `id: "id"
+alias: "spaces"
+config:
+  validatorOnly: true
+  consensusParameters:
+    k: 20
+    alpha: 15
+    betaVirtuous: 15
+    betaRogue: 20
+    concurrentRepolls: 4
+    optimalProcessing: 50
+    maxOutstandingItems: 1024
+    maxItemProcessingTime: 120_000_000_000`,
+			want: 2 * time.Minute,
+		},
+		{
+			name: "duration string",
+			subnet: `id: "id"
+alias: "spaces"
+config:
+  consensusParameters:
+    k: 20
+    alpha: 15
+    betaVirtuous: 15
+    betaRogue: 20
+    concurrentRepolls: 4
+    optimalProcessing: 50
+    maxOutstandingItems: 1024
+    maxItemProcessingTime: 2m`,
+			want: 2 * time.Minute,
+		},
+		{
+			name: "invalid consensus parameters",
+			subnet: `id: "id"
+alias: "spaces"
+config:
+  consensusParameters:
+    k: 20
+    alpha: 5`,
+			verifyErr: true,
+		},
+		{
+			name: "invalid duration",
+			subnet: `id: "id"
+alias: "spaces"
+config:
+  consensusParameters:
+    maxItemProcessingTime: 0xZZ`,
+			verifyErr: true,
+		},
+		{
+			name: "invalid allowed nodes",
+			subnet: `id: "id"
+alias: "spaces"
+config:
+  validatorOnly: true
+  allowedNodes:
+    - not-a-node-id`,
+			verifyErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			subnet := Subnet{}
+			err := yaml.Unmarshal([]byte(test.subnet), &subnet)
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			if test.verifyErr {
+				assert.Error(t, subnet.Verify())
+				return
+			}
+			assert.NoError(t, subnet.Verify())
+			config, err := subnet.NodeConfig()
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, config.ConsensusParameters.MaxItemProcessingTime)
+		})
+	}
+}
+
+func TestSubnetNodeConfig(t *testing.T) {
+	nodeID := ids.GenerateTestNodeID()
+	contents := fmt.Sprintf(`id: "id"
+alias: "spaces"
+config:
+  validatorOnly: true
+  allowedNodes:
+    - %s
+  gossipAcceptedFrontierValidatorSize: 5
+  appGossipPeerSize: 3`, nodeID)
+
+	subnet := Subnet{}
+	assert.NoError(t, yaml.Unmarshal([]byte(contents), &subnet))
+	assert.NoError(t, subnet.Verify())
+
+	// Only the keys in the definition are kept.
+	assert.Equal(t, []string{"allowedNodes", "appGossipPeerSize", "gossipAcceptedFrontierValidatorSize", "validatorOnly"}, keys(subnet.Config))
+
+	config, err := subnet.NodeConfig()
+	assert.NoError(t, err)
+	assert.True(t, config.ValidatorOnly)
+	assert.True(t, config.AllowedNodes.Contains(nodeID))
+	assert.Equal(t, uint(5), config.AcceptedFrontierValidatorSize)
+	assert.Equal(t, uint(3), config.AppGossipPeerSize)
+	// Omitted keys fall back to the defaults.
+	assert.Equal(t, snowball.DefaultParameters, config.ConsensusParameters)
+}
+
+func TestSubnetVerify(t *testing.T) {
+	assert.ErrorIs(t, Subnet{Alias: "spaces"}.Verify(), errMissingID)
+	assert.ErrorIs(t, Subnet{ID: "id"}.Verify(), errMissingAlias)
+	assert.NoError(t, Subnet{ID: "id", Alias: "spaces"}.Verify())
+}
+
+func keys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,132 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/DioneProtocol/odysseygo/version"
+	"gopkg.in/yaml.v3"
+)
+
+var errInvalidConstraint = errors.New("invalid version constraint")
+
+// operators are ordered so that longer operators are matched first.
+var operators = []string{">=", "<=", "!=", ">", "<", "=", "^", "~"}
+
+// VersionConstraint restricts the versions of a VM that may be installed. It's
+// a comma-separated list of comparisons which must all hold
+// (e.g. ">=v1.2.0, <v2.0.0"). Supported operators are =, !=, >, >=, <, <=,
+// ^ (same major version) and ~ (same minor version). A version without an
+// operator must match exactly, and the empty constraint allows any version.
+type VersionConstraint struct {
+	raw         string
+	comparisons []comparison
+}
+
+type comparison struct {
+	operator string
+	version  version.Semantic
+}
+
+// ParseVersionConstraint parses [s] into a VersionConstraint.
+func ParseVersionConstraint(s string) (VersionConstraint, error) {
+	constraint := VersionConstraint{raw: strings.TrimSpace(s)}
+	if constraint.raw == "" {
+		return constraint, nil
+	}
+
+	for _, term := range strings.Split(constraint.raw, ",") {
+		term = strings.TrimSpace(term)
+
+		operator := "="
+		for _, op := range operators {
+			if strings.HasPrefix(term, op) {
+				operator = op
+				term = strings.TrimSpace(strings.TrimPrefix(term, op))
+				break
+			}
+		}
+
+		if !strings.HasPrefix(term, "v") {
+			term = "v" + term
+		}
+		v, err := version.Parse(term)
+		if err != nil {
+			return VersionConstraint{}, fmt.Errorf("%w %q: %s", errInvalidConstraint, s, err)
+		}
+
+		constraint.comparisons = append(constraint.comparisons, comparison{
+			operator: operator,
+			version:  *v,
+		})
+	}
+
+	return constraint, nil
+}
+
+// Check returns true if [v] satisfies the constraint.
+func (c VersionConstraint) Check(v version.Semantic) bool {
+	for _, comparison := range c.comparisons {
+		if !comparison.check(v) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// String returns the constraint as it was written, or "*" if any version is
+// allowed.
+func (c VersionConstraint) String() string {
+	if c.raw == "" {
+		return "*"
+	}
+
+	return c.raw
+}
+
+func (c *VersionConstraint) UnmarshalYAML(value *yaml.Node) error {
+	raw := ""
+	if err := value.Decode(&raw); err != nil {
+		return err
+	}
+
+	constraint, err := ParseVersionConstraint(raw)
+	if err != nil {
+		return err
+	}
+
+	*c = constraint
+	return nil
+}
+
+func (c VersionConstraint) MarshalYAML() (interface{}, error) {
+	return c.raw, nil
+}
+
+func (c comparison) check(v version.Semantic) bool {
+	result := v.Compare(&c.version)
+
+	switch c.operator {
+	case "!=":
+		return result != 0
+	case ">":
+		return result > 0
+	case ">=":
+		return result >= 0
+	case "<":
+		return result < 0
+	case "<=":
+		return result <= 0
+	case "^":
+		return result >= 0 && v.Major == c.version.Major
+	case "~":
+		return result >= 0 && v.Major == c.version.Major && v.Minor == c.version.Minor
+	default:
+		return result == 0
+	}
+}
@@ -0,0 +1,67 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package types
+
+import (
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+func TestVersionConstraintCheck(t *testing.T) {
+	tests := []struct {
+		constraint string
+		version    version.Semantic
+		want       bool
+	}{
+		{constraint: "", version: version.Semantic{Major: 1}, want: true},
+		{constraint: "v1.2.3", version: version.Semantic{Major: 1, Minor: 2, Patch: 3}, want: true},
+		{constraint: "1.2.3", version: version.Semantic{Major: 1, Minor: 2, Patch: 4}, want: false},
+		{constraint: "=v1.2.3", version: version.Semantic{Major: 1, Minor: 2, Patch: 3}, want: true},
+		{constraint: "!=v1.2.3", version: version.Semantic{Major: 1, Minor: 2, Patch: 3}, want: false},
+		{constraint: ">=v1.2.0, <v2.0.0", version: version.Semantic{Major: 1, Minor: 9}, want: true},
+		{constraint: ">=v1.2.0, <v2.0.0", version: version.Semantic{Major: 2}, want: false},
+		{constraint: "> 1.2.0", version: version.Semantic{Major: 1, Minor: 2}, want: false},
+		{constraint: "<=1.2.0", version: version.Semantic{Major: 1, Minor: 2}, want: true},
+		{constraint: "^1.2.0", version: version.Semantic{Major: 1, Minor: 5}, want: true},
+		{constraint: "^1.2.0", version: version.Semantic{Major: 2}, want: false},
+		{constraint: "^1.2.0", version: version.Semantic{Major: 1, Minor: 1}, want: false},
+		{constraint: "~1.2.0", version: version.Semantic{Major: 1, Minor: 2, Patch: 7}, want: true},
+		{constraint: "~1.2.0", version: version.Semantic{Major: 1, Minor: 3}, want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.constraint, func(t *testing.T) {
+			constraint, err := ParseVersionConstraint(test.constraint)
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, constraint.Check(test.version))
+		})
+	}
+}
+
+func TestParseVersionConstraintInvalid(t *testing.T) {
+	for _, constraint := range []string{"latest", ">=1.2", "1.2.3,", ">=>1.2.3"} {
+		t.Run(constraint, func(t *testing.T) {
+			_, err := ParseVersionConstraint(constraint)
+			assert.ErrorIs(t, err, errInvalidConstraint)
+		})
+	}
+}
+
+func TestVersionConstraintYAML(t *testing.T) {
+	vm := struct {
+		Version VersionConstraint `yaml:"version"`
+	}{}
+	assert.NoError(t, yaml.Unmarshal([]byte(`version: ">=v1.0.0"`), &vm))
+	assert.Equal(t, ">=v1.0.0", vm.Version.String())
+	assert.True(t, vm.Version.Check(version.Semantic{Major: 1}))
+
+	out, err := yaml.Marshal(vm)
+	assert.NoError(t, err)
+	assert.Equal(t, "version: '>=v1.0.0'\n", string(out))
+
+	assert.Error(t, yaml.Unmarshal([]byte(`version: latest`), &vm))
+}
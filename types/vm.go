@@ -18,6 +18,10 @@ type VM struct {
 	URL           string           `yaml:"url"`
 	SHA256        string           `yaml:"sha256"`
 	Version       version.Semantic `yaml:"version"`
+	// ChainConfigs are the default chain configs for chains running this VM.
+	ChainConfigs []ChainConfig `yaml:"chainConfigs,omitempty"`
+	// Compatibility describes the nodes that are able to load this VM.
+	Compatibility Compatibility `yaml:"compatibility,omitempty"`
 }
 
 func (vm VM) GetID() string {
@@ -39,3 +43,15 @@ func (vm VM) GetDescription() string {
 func (vm VM) GetMaintainers() []string {
 	return vm.Maintainers
 }
+
+func (vm VM) Verify() error {
+	if err := verifyIdentity(vm); err != nil {
+		return err
+	}
+
+	if err := verifyChainConfigs(vm.ChainConfigs); err != nil {
+		return err
+	}
+
+	return vm.Compatibility.Verify()
+}
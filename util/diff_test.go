@@ -0,0 +1,50 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		before string
+		after  string
+		want   string
+	}{
+		{
+			name:   "identical",
+			before: "a\nb\n",
+			after:  "a\nb\n",
+			want:   " a\n b\n",
+		},
+		{
+			name:   "line changed",
+			before: "a\nb\nc\n",
+			after:  "a\nd\nc\n",
+			want:   " a\n-b\n+d\n c\n",
+		},
+		{
+			name:   "line added",
+			before: "a\nc",
+			after:  "a\nb\nc",
+			want:   " a\n+b\n c\n",
+		},
+		{
+			name:   "line removed",
+			before: "a\nb\nc",
+			after:  "a\nc",
+			want:   " a\n-b\n c\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, Diff(test.before, test.after))
+		})
+	}
+}
@@ -9,22 +9,32 @@ import (
 	"github.com/DioneProtocol/opm/constant"
 )
 
-func ParseQualifiedName(name string) (source string, plugin string) {
-	parsed := strings.Split(name, constant.QualifiedNameDelimiter)
+// reservedCharacters may not appear in organizations, repositories or names,
+// since they delimit the parts of a reference.
+const reservedCharacters = constant.AliasDelimiter + constant.QualifiedNameDelimiter + "@# \t\r\n"
 
-	return parsed[0], parsed[1]
+// ParseQualifiedName splits [name] into the repository alias and the name of
+// the plugin. [plugin] is empty if [name] isn't qualified.
+func ParseQualifiedName(name string) (source string, plugin string) {
+	source, plugin, _ = strings.Cut(name, constant.QualifiedNameDelimiter)
+	return source, plugin
 }
 
+// ParseAlias splits [alias] into its organization and repository.
+// [repository] is empty if [alias] isn't in the form of
+// organization/repository.
 func ParseAlias(alias string) (organization string, repository string) {
-	parsed := strings.Split(alias, constant.AliasDelimiter)
-
-	return parsed[0], parsed[1]
+	organization, repository, _ = strings.Cut(alias, constant.AliasDelimiter)
+	return organization, repository
 }
 
+// ValidAlias returns true if [alias] is in the form of organization/repository.
 func ValidAlias(alias string) bool {
-	if organization, repository := ParseAlias(alias); organization == "" || repository == "" {
-		return false
-	}
+	organization, repository, ok := strings.Cut(alias, constant.AliasDelimiter)
+	return ok && ValidName(organization) && ValidName(repository)
+}
 
-	return true
+// ValidName returns true if [name] can be used as a part of a reference.
+func ValidName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, reservedCharacters)
 }
@@ -0,0 +1,52 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseQualifiedName(t *testing.T) {
+	tests := []struct {
+		name       string
+		wantSource string
+		wantPlugin string
+	}{
+		{name: "organization/repository:spacesvm", wantSource: "organization/repository", wantPlugin: "spacesvm"},
+		{name: "spacesvm", wantSource: "spacesvm"},
+		{name: "a:b:c", wantSource: "a", wantPlugin: "b:c"},
+		{name: ""},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			source, plugin := ParseQualifiedName(test.name)
+			assert.Equal(t, test.wantSource, source)
+			assert.Equal(t, test.wantPlugin, plugin)
+		})
+	}
+}
+
+func TestValidAlias(t *testing.T) {
+	tests := []struct {
+		alias string
+		want  bool
+	}{
+		{alias: "organization/repository", want: true},
+		{alias: "organization"},
+		{alias: "organization/"},
+		{alias: "/repository"},
+		{alias: "organization/repository/extra"},
+		{alias: "organization/repository:spacesvm"},
+		{alias: "organization/repo sitory"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.alias, func(t *testing.T) {
+			assert.Equal(t, test.want, ValidAlias(test.alias))
+		})
+	}
+}
@@ -4,34 +4,56 @@
 package workflow
 
 import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"path"
 
 	"github.com/go-git/go-git/v5/plumbing"
 
 	"github.com/DioneProtocol/opm/storage"
 )
 
-var _ Workflow = AddRepository{}
+var (
+	errInvalidReference = errors.New("invalid reference")
+	errInvalidPublicKey = errors.New("invalid public key")
+
+	_ Workflow = AddRepository{}
+)
 
 func NewAddRepository(config AddRepositoryConfig) *AddRepository {
 	return &AddRepository{
-		sourcesList: config.SourcesList,
-		alias:       config.Alias,
-		url:         config.URL,
-		branch:      config.Branch,
+		sourcesList:   config.SourcesList,
+		alias:         config.Alias,
+		url:           config.URL,
+		referenceType: config.ReferenceType,
+		reference:     config.Reference,
+		source:        config.Source,
+		publicKey:     config.PublicKey,
 	}
 }
 
 type AddRepositoryConfig struct {
 	SourcesList storage.Storage[storage.SourceInfo]
 	Alias, URL  string
-	Branch      plumbing.ReferenceName
+	// ReferenceType is how a git repository is tracked, and Reference is the
+	// branch, tag, commit or tag pattern it's tracked by.
+	ReferenceType storage.ReferenceType
+	Reference     string
+	Source        storage.SourceType
+	// PublicKey is the hex-encoded ed25519 key the index of an http source is
+	// signed with.
+	PublicKey string
 }
 
 type AddRepository struct {
-	sourcesList storage.Storage[storage.SourceInfo]
-	alias, url  string
-	branch      plumbing.ReferenceName
+	sourcesList   storage.Storage[storage.SourceInfo]
+	alias, url    string
+	referenceType storage.ReferenceType
+	reference     string
+	source        storage.SourceType
+	publicKey     string
 }
 
 func (a AddRepository) Execute() error {
@@ -46,8 +68,60 @@ func (a AddRepository) Execute() error {
 	unsynced := storage.SourceInfo{
 		Alias:  a.alias,
 		URL:    a.url,
-		Branch: a.branch,
 		Commit: plumbing.ZeroHash, // hasn't been synced yet
+		Source: a.source,
+	}
+
+	switch a.source {
+	case storage.GitSource:
+		if err := a.setReference(&unsynced); err != nil {
+			return err
+		}
+	case storage.HTTPSource:
+		if a.referenceType != "" {
+			return fmt.Errorf("%w: %s sources can't track a %s", errInvalidReference, a.source, a.referenceType)
+		}
+		if publicKey, err := hex.DecodeString(a.publicKey); err != nil || len(publicKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("%w: %q isn't a hex-encoded ed25519 public key", errInvalidPublicKey, a.publicKey)
+		}
+		unsynced.PublicKey = a.publicKey
+	case storage.LocalSource:
+		if a.referenceType != "" {
+			return fmt.Errorf("%w: %s sources can't track a %s", errInvalidReference, a.source, a.referenceType)
+		}
+	default:
+		return fmt.Errorf("unknown source %q", a.source)
 	}
+
 	return a.sourcesList.Put(aliasBytes, unsynced)
 }
+
+// setReference records the reference a git repository is tracked by in
+// [sourceInfo].
+func (a AddRepository) setReference(sourceInfo *storage.SourceInfo) error {
+	if a.reference == "" {
+		return fmt.Errorf("%w: missing %s to track for %s", errInvalidReference, a.referenceType, a.alias)
+	}
+
+	sourceInfo.ReferenceType = a.referenceType
+	switch a.referenceType {
+	case storage.BranchReference:
+		sourceInfo.Branch = plumbing.NewBranchReferenceName(a.reference)
+	case storage.TagReference:
+		sourceInfo.Reference = a.reference
+	case storage.CommitReference:
+		if !plumbing.IsHash(a.reference) {
+			return fmt.Errorf("%w: %s isn't a full commit hash", errInvalidReference, a.reference)
+		}
+		sourceInfo.Reference = a.reference
+	case storage.TagPatternReference:
+		if _, err := path.Match(a.reference, ""); err != nil {
+			return fmt.Errorf("%w: %s isn't a valid tag pattern: %s", errInvalidReference, a.reference, err)
+		}
+		sourceInfo.Reference = a.reference
+	default:
+		return fmt.Errorf("%w: unknown reference type %q", errInvalidReference, a.referenceType)
+	}
+
+	return nil
+}
@@ -16,17 +16,25 @@ import (
 
 func TestAddRepositoryExecute(t *testing.T) {
 	errWrong := fmt.Errorf("something went wrong")
+	publicKey := "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
 
 	type mocks struct {
 		sourcesList *storage.MockStorage[storage.SourceInfo]
 	}
 	tests := []struct {
-		name    string
-		setup   func(mocks)
-		wantErr assert.ErrorAssertionFunc
+		name          string
+		source        storage.SourceType
+		referenceType storage.ReferenceType
+		reference     string
+		publicKey     string
+		setup         func(mocks)
+		wantErr       assert.ErrorAssertionFunc
 	}{
 		{
-			name: "can't read from sources list",
+			name:          "can't read from sources list",
+			source:        storage.GitSource,
+			referenceType: storage.BranchReference,
+			reference:     "master",
 			setup: func(mocks mocks) {
 				mocks.sourcesList.EXPECT().Has([]byte("alias")).Return(false, errWrong)
 			},
@@ -35,7 +43,10 @@ func TestAddRepositoryExecute(t *testing.T) {
 			},
 		},
 		{
-			name: "duplicate alias",
+			name:          "duplicate alias",
+			source:        storage.GitSource,
+			referenceType: storage.BranchReference,
+			reference:     "master",
 			setup: func(mocks mocks) {
 				mocks.sourcesList.EXPECT().Has([]byte("alias")).Return(true, nil)
 			},
@@ -44,17 +55,22 @@ func TestAddRepositoryExecute(t *testing.T) {
 			},
 		},
 		{
-			name: "adding to sources list fails",
+			name:          "adding to sources list fails",
+			source:        storage.GitSource,
+			referenceType: storage.BranchReference,
+			reference:     "master",
 			setup: func(mocks mocks) {
 				mocks.sourcesList.EXPECT().Has([]byte("alias")).Return(false, nil)
 				mocks.sourcesList.EXPECT().
 					Put(
 						[]byte("alias"),
 						storage.SourceInfo{
-							Alias:  "alias",
-							URL:    "url",
-							Branch: "master",
-							Commit: plumbing.ZeroHash,
+							Alias:         "alias",
+							URL:           "url",
+							Branch:        "refs/heads/master",
+							Commit:        plumbing.ZeroHash,
+							ReferenceType: storage.BranchReference,
+							Source:        storage.GitSource,
 						},
 					).
 					Return(errWrong)
@@ -64,17 +80,22 @@ func TestAddRepositoryExecute(t *testing.T) {
 			},
 		},
 		{
-			name: "success",
+			name:          "success",
+			source:        storage.GitSource,
+			referenceType: storage.BranchReference,
+			reference:     "master",
 			setup: func(mocks mocks) {
 				mocks.sourcesList.EXPECT().Has([]byte("alias")).Return(false, nil)
 				mocks.sourcesList.EXPECT().
 					Put(
 						[]byte("alias"),
 						storage.SourceInfo{
-							Alias:  "alias",
-							URL:    "url",
-							Branch: "master",
-							Commit: plumbing.ZeroHash,
+							Alias:         "alias",
+							URL:           "url",
+							Branch:        "refs/heads/master",
+							Commit:        plumbing.ZeroHash,
+							ReferenceType: storage.BranchReference,
+							Source:        storage.GitSource,
 						},
 					).
 					Return(nil)
@@ -83,6 +104,112 @@ func TestAddRepositoryExecute(t *testing.T) {
 				return assert.Nil(t, err)
 			},
 		},
+		{
+			name:          "tag",
+			source:        storage.GitSource,
+			referenceType: storage.TagReference,
+			reference:     "v1.3.0",
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Has([]byte("alias")).Return(false, nil)
+				mocks.sourcesList.EXPECT().
+					Put(
+						[]byte("alias"),
+						storage.SourceInfo{
+							Alias:         "alias",
+							URL:           "url",
+							Commit:        plumbing.ZeroHash,
+							ReferenceType: storage.TagReference,
+							Reference:     "v1.3.0",
+							Source:        storage.GitSource,
+						},
+					).
+					Return(nil)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.Nil(t, err)
+			},
+		},
+		{
+			name:          "abbreviated commit",
+			source:        storage.GitSource,
+			referenceType: storage.CommitReference,
+			reference:     "0123456",
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Has([]byte("alias")).Return(false, nil)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, errInvalidReference)
+			},
+		},
+		{
+			name:          "invalid tag pattern",
+			source:        storage.GitSource,
+			referenceType: storage.TagPatternReference,
+			reference:     "v1.[",
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Has([]byte("alias")).Return(false, nil)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, errInvalidReference)
+			},
+		},
+		{
+			name:      "http source",
+			source:    storage.HTTPSource,
+			publicKey: publicKey,
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Has([]byte("alias")).Return(false, nil)
+				mocks.sourcesList.EXPECT().
+					Put(
+						[]byte("alias"),
+						storage.SourceInfo{
+							Alias:     "alias",
+							URL:       "url",
+							Commit:    plumbing.ZeroHash,
+							Source:    storage.HTTPSource,
+							PublicKey: publicKey,
+						},
+					).
+					Return(nil)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.Nil(t, err)
+			},
+		},
+		{
+			name:      "http source with an invalid public key",
+			source:    storage.HTTPSource,
+			publicKey: "0123",
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Has([]byte("alias")).Return(false, nil)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, errInvalidPublicKey)
+			},
+		},
+		{
+			name:          "local source with a reference",
+			source:        storage.LocalSource,
+			referenceType: storage.BranchReference,
+			reference:     "master",
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Has([]byte("alias")).Return(false, nil)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, errInvalidReference)
+			},
+		},
+		{
+			name:          "missing reference",
+			source:        storage.GitSource,
+			referenceType: storage.TagReference,
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Has([]byte("alias")).Return(false, nil)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, errInvalidReference)
+			},
+		},
 	}
 
 	for _, test := range tests {
@@ -100,10 +227,13 @@ func TestAddRepositoryExecute(t *testing.T) {
 
 			wf := NewAddRepository(
 				AddRepositoryConfig{
-					SourcesList: sourcesList,
-					Alias:       "alias",
-					URL:         "url",
-					Branch:      "master",
+					SourcesList:   sourcesList,
+					Alias:         "alias",
+					URL:           "url",
+					ReferenceType: test.referenceType,
+					Reference:     test.reference,
+					Source:        test.source,
+					PublicKey:     test.publicKey,
 				},
 			)
 
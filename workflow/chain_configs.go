@@ -0,0 +1,251 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/spf13/afero"
+
+	"github.com/DioneProtocol/opm/constant"
+	"github.com/DioneProtocol/opm/node"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+	"github.com/DioneProtocol/opm/util"
+)
+
+var _ Workflow = &InstallChainConfigs{}
+
+type InstallChainConfigsConfig struct {
+	// Owner is the fully qualified name of the VM or subnet that provides the
+	// chain configs.
+	Owner        string
+	ChainConfigs []types.ChainConfig
+
+	ConfigDir   *node.ConfigDir
+	ConfigFiles storage.Storage[storage.ConfigFile]
+	Fs          afero.Fs
+}
+
+func NewInstallChainConfigs(config InstallChainConfigsConfig) *InstallChainConfigs {
+	return &InstallChainConfigs{
+		owner:        config.Owner,
+		chainConfigs: config.ChainConfigs,
+		configDir:    config.ConfigDir,
+		configFiles:  config.ConfigFiles,
+		fs:           config.Fs,
+	}
+}
+
+// InstallChainConfigs writes the chain configs of a VM or subnet into the
+// node's config directory. Local modifications to files previously written by
+// the opm are preserved with a three-way merge, and files the owner no longer
+// provides are removed.
+type InstallChainConfigs struct {
+	owner        string
+	chainConfigs []types.ChainConfig
+
+	configDir   *node.ConfigDir
+	configFiles storage.Storage[storage.ConfigFile]
+	fs          afero.Fs
+}
+
+func (i *InstallChainConfigs) Execute() error {
+	installed := map[string]struct{}{}
+
+	for _, chainConfig := range i.chainConfigs {
+		files := []struct {
+			name     string
+			contents string
+		}{
+			{name: node.ChainConfigFile, contents: chainConfig.Config},
+			{name: node.ChainUpgradeFile, contents: chainConfig.Upgrade},
+		}
+
+		for _, file := range files {
+			if file.contents == "" {
+				continue
+			}
+
+			path := i.configDir.ChainConfigPath(chainConfig.Chain, file.name)
+			if err := i.install(path, file.contents); err != nil {
+				return err
+			}
+			installed[path] = struct{}{}
+		}
+	}
+
+	// Clean up anything from an older definition that's no longer provided.
+	return removeConfigFiles(i.fs, i.configFiles, i.owner, installed)
+}
+
+func (i *InstallChainConfigs) install(path string, contents string) error {
+	var base []byte
+
+	record, err := i.configFiles.Get([]byte(path))
+	switch {
+	case err == nil:
+		base = []byte(record.Contents)
+	case err == database.ErrNotFound:
+		// We've never written this file before, so there's no common ancestor.
+	default:
+		return err
+	}
+
+	local, err := afero.ReadFile(i.fs, path)
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+		if err := node.WriteFile(i.fs, path, []byte(contents)); err != nil {
+			return err
+		}
+	case err != nil:
+		return err
+	default:
+		merged, conflicts, err := node.MergeJSON(base, local, []byte(contents))
+		if err != nil {
+			return fmt.Errorf("failed to merge %s: %w", path, err)
+		}
+
+		for _, conflict := range conflicts {
+			fmt.Printf("Warning - %s in %s was modified locally and conflicts with the new default. Keeping the local value.\n", conflict, path)
+		}
+
+		if err := node.WriteFile(i.fs, path, merged); err != nil {
+			return err
+		}
+	}
+
+	return i.configFiles.Put([]byte(path), storage.ConfigFile{
+		Owner:    i.owner,
+		Contents: contents,
+	})
+}
+
+// VMChainConfigs returns the chain configs of [vm] that aren't overridden by
+// a chain config of one of [subnets] for the same chain. A subnet's chain
+// configs take precedence over the ones provided by its VMs.
+func VMChainConfigs(vm types.VM, subnets ...types.Subnet) []types.ChainConfig {
+	subnetChains := map[string]struct{}{}
+	for _, subnet := range subnets {
+		for _, chainConfig := range subnet.ChainConfigs {
+			subnetChains[chainConfig.Chain] = struct{}{}
+		}
+	}
+
+	chainConfigs := []types.ChainConfig{}
+	for _, chainConfig := range vm.ChainConfigs {
+		if _, ok := subnetChains[chainConfig.Chain]; !ok {
+			chainConfigs = append(chainConfigs, chainConfig)
+		}
+	}
+
+	return chainConfigs
+}
+
+// joinedSubnet is a joined subnet along with its fully qualified name.
+type joinedSubnet struct {
+	name       string
+	repoAlias  string
+	definition types.Subnet
+}
+
+// joinedSubnetDefinitions returns the synced definitions of the joined
+// subnets. Subnets that are no longer defined are skipped.
+func joinedSubnetDefinitions(
+	joinedSubnets storage.Storage[storage.JoinInfo],
+	repoFactory storage.RepositoryFactory,
+) ([]joinedSubnet, error) {
+	itr := joinedSubnets.Iterator()
+	defer itr.Release()
+
+	subnets := []joinedSubnet{}
+	for itr.Next() {
+		name := string(itr.Key())
+		repoAlias, plugin := util.ParseQualifiedName(name)
+
+		definition, err := repoFactory.GetRepository([]byte(repoAlias)).Subnets.Get([]byte(plugin))
+		if err == database.ErrNotFound {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		subnets = append(subnets, joinedSubnet{
+			name:       name,
+			repoAlias:  repoAlias,
+			definition: definition.Definition,
+		})
+	}
+
+	return subnets, itr.Error()
+}
+
+// includes returns true if the subnet runs the VM with the fully qualified
+// name [vmName].
+func (s joinedSubnet) includes(vmName string) bool {
+	for _, vm := range s.definition.VMs {
+		if strings.Join([]string{s.repoAlias, vm}, constant.QualifiedNameDelimiter) == vmName {
+			return true
+		}
+	}
+
+	return false
+}
+
+// removeConfigFiles deletes the config files installed for [owner], except for
+// the ones in [keep]. Files that were modified after the opm wrote them are
+// left in place.
+func removeConfigFiles(
+	afs afero.Fs,
+	configFiles storage.Storage[storage.ConfigFile],
+	owner string,
+	keep map[string]struct{},
+) error {
+	itr := configFiles.Iterator()
+	defer itr.Release()
+
+	for itr.Next() {
+		path := string(itr.Key())
+		if _, ok := keep[path]; ok {
+			continue
+		}
+
+		record, err := itr.Value()
+		if err != nil {
+			return err
+		}
+		if record.Owner != owner {
+			continue
+		}
+
+		local, err := afero.ReadFile(afs, path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+			fmt.Printf("%s doesn't exist already. Nothing to delete here.\n", path)
+		case err != nil:
+			return err
+		default:
+			// Only delete the file if it's unchanged since we wrote it.
+			if !node.EqualJSON(local, []byte(record.Contents)) {
+				fmt.Printf("Warning - %s was modified locally. Leaving it in place.\n", path)
+				break
+			}
+
+			fmt.Printf("Deleting %s...\n", path)
+			if err := afs.Remove(path); err != nil {
+				return err
+			}
+		}
+
+		if err := configFiles.Delete(itr.Key()); err != nil {
+			return err
+		}
+	}
+
+	return itr.Error()
+}
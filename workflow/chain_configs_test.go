@@ -0,0 +1,209 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/node"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestInstallChainConfigsExecute(t *testing.T) {
+	const (
+		owner = "organization/repository:spacesvm"
+		chain = "spaces"
+	)
+
+	var (
+		configPath  = filepath.Join("configs", "chains", chain, node.ChainConfigFile)
+		upgradePath = filepath.Join("configs", "chains", chain, node.ChainUpgradeFile)
+	)
+
+	tests := []struct {
+		name         string
+		setup        func(*testing.T, afero.Fs, storage.Storage[storage.ConfigFile])
+		chainConfigs []types.ChainConfig
+		want         map[string]string
+		wantMissing  []string
+	}{
+		{
+			name: "fresh install",
+			setup: func(*testing.T, afero.Fs, storage.Storage[storage.ConfigFile]) {
+			},
+			chainConfigs: []types.ChainConfig{
+				{Chain: chain, Config: `{"a": 1}`, Upgrade: `{"b": 1}`},
+			},
+			want: map[string]string{
+				configPath:  `{"a": 1}`,
+				upgradePath: `{"b": 1}`,
+			},
+		},
+		{
+			name: "local changes are merged",
+			setup: func(t *testing.T, fs afero.Fs, configFiles storage.Storage[storage.ConfigFile]) {
+				assert.NoError(t, afero.WriteFile(fs, configPath, []byte(`{"a": 1, "b": 2}`), perms.ReadWrite))
+				assert.NoError(t, configFiles.Put([]byte(configPath), storage.ConfigFile{
+					Owner:    owner,
+					Contents: `{"a": 1, "b": 1}`,
+				}))
+			},
+			chainConfigs: []types.ChainConfig{
+				{Chain: chain, Config: `{"a": 2, "b": 1}`},
+			},
+			want: map[string]string{
+				configPath: "{\n  \"a\": 2,\n  \"b\": 2\n}\n",
+			},
+		},
+		{
+			name: "files no longer provided are removed",
+			setup: func(t *testing.T, fs afero.Fs, configFiles storage.Storage[storage.ConfigFile]) {
+				assert.NoError(t, afero.WriteFile(fs, configPath, []byte(`{"a": 1}`), perms.ReadWrite))
+				assert.NoError(t, afero.WriteFile(fs, upgradePath, []byte(`{"b": 1}`), perms.ReadWrite))
+				assert.NoError(t, configFiles.Put([]byte(configPath), storage.ConfigFile{
+					Owner:    owner,
+					Contents: `{"a": 1}`,
+				}))
+				assert.NoError(t, configFiles.Put([]byte(upgradePath), storage.ConfigFile{
+					Owner:    owner,
+					Contents: `{"b": 1}`,
+				}))
+			},
+			chainConfigs: []types.ChainConfig{
+				{Chain: chain, Config: `{"a": 1}`},
+			},
+			want: map[string]string{
+				configPath: `{"a": 1}`,
+			},
+			wantMissing: []string{upgradePath},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			configFiles := storage.NewConfigFiles(memdb.New())
+
+			test.setup(t, fs, configFiles)
+
+			wf := NewInstallChainConfigs(InstallChainConfigsConfig{
+				Owner:        owner,
+				ChainConfigs: test.chainConfigs,
+				ConfigDir:    node.NewConfigDir(fs, "configs"),
+				ConfigFiles:  configFiles,
+				Fs:           fs,
+			})
+			assert.NoError(t, wf.Execute())
+
+			for path, contents := range test.want {
+				got, err := afero.ReadFile(fs, path)
+				assert.NoError(t, err)
+				assert.Equal(t, contents, string(got))
+
+				ok, err := configFiles.Has([]byte(path))
+				assert.NoError(t, err)
+				assert.True(t, ok)
+			}
+
+			for _, path := range test.wantMissing {
+				ok, err := afero.Exists(fs, path)
+				assert.NoError(t, err)
+				assert.False(t, ok)
+
+				ok, err = configFiles.Has([]byte(path))
+				assert.NoError(t, err)
+				assert.False(t, ok)
+			}
+		})
+	}
+}
+
+func TestRemoveConfigFiles(t *testing.T) {
+	const owner = "organization/repository:spacesvm"
+
+	var (
+		unmodifiedPath = filepath.Join("configs", "chains", "a", node.ChainConfigFile)
+		modifiedPath   = filepath.Join("configs", "chains", "b", node.ChainConfigFile)
+		otherPath      = filepath.Join("configs", "chains", "c", node.ChainConfigFile)
+	)
+
+	fs := afero.NewMemMapFs()
+	configFiles := storage.NewConfigFiles(memdb.New())
+
+	for path, record := range map[string]storage.ConfigFile{
+		unmodifiedPath: {Owner: owner, Contents: `{"a": 1}`},
+		modifiedPath:   {Owner: owner, Contents: `{"b": 1}`},
+		otherPath:      {Owner: "organization/repository:timestampvm", Contents: `{"c": 1}`},
+	} {
+		assert.NoError(t, configFiles.Put([]byte(path), record))
+	}
+	assert.NoError(t, afero.WriteFile(fs, unmodifiedPath, []byte(`{ "a": 1 }`), perms.ReadWrite))
+	assert.NoError(t, afero.WriteFile(fs, modifiedPath, []byte(`{"b": 2}`), perms.ReadWrite))
+	assert.NoError(t, afero.WriteFile(fs, otherPath, []byte(`{"c": 1}`), perms.ReadWrite))
+
+	assert.NoError(t, removeConfigFiles(fs, configFiles, owner, nil))
+
+	exists := func(path string) bool {
+		ok, err := afero.Exists(fs, path)
+		assert.NoError(t, err)
+		return ok
+	}
+	assert.False(t, exists(unmodifiedPath))
+	assert.True(t, exists(modifiedPath))
+	assert.True(t, exists(otherPath))
+
+	for path, want := range map[string]bool{
+		unmodifiedPath: false,
+		modifiedPath:   false,
+		otherPath:      true,
+	} {
+		ok, err := configFiles.Has([]byte(path))
+		assert.NoError(t, err)
+		assert.Equal(t, want, ok)
+	}
+}
+
+func TestVMChainConfigs(t *testing.T) {
+	vm := types.VM{
+		ChainConfigs: []types.ChainConfig{
+			{Chain: "a", Config: `{"vm": 1}`},
+			{Chain: "b", Config: `{"vm": 1}`},
+			{Chain: "c", Config: `{"vm": 1}`},
+		},
+	}
+	subnets := []types.Subnet{
+		{ChainConfigs: []types.ChainConfig{{Chain: "a", Config: `{"subnet": 1}`}}},
+		{ChainConfigs: []types.ChainConfig{{Chain: "c", Upgrade: `{"subnet": 1}`}}},
+	}
+
+	assert.Equal(t, vm.ChainConfigs, VMChainConfigs(vm))
+	assert.Equal(t, []types.ChainConfig{{Chain: "b", Config: `{"vm": 1}`}}, VMChainConfigs(vm, subnets...))
+}
+
+func TestJoinedSubnetDefinitions(t *testing.T) {
+	const repoAlias = "organization/repository"
+
+	db := memdb.New()
+	joinedSubnets := storage.NewJoinedSubnets(db)
+	repoFactory := storage.NewRepositoryFactory(db)
+
+	spaces := types.Subnet{ID: "id", Alias: "spaces", Maintainers: []string{}, VMs: []string{"spacesvm"}}
+	assert.NoError(t, repoFactory.GetRepository([]byte(repoAlias)).Subnets.Put([]byte(spaces.Alias), storage.Definition[types.Subnet]{Definition: spaces}))
+	assert.NoError(t, joinedSubnets.Put([]byte(repoAlias+":spaces"), storage.JoinInfo{ID: spaces.ID}))
+	assert.NoError(t, joinedSubnets.Put([]byte(repoAlias+":removed"), storage.JoinInfo{ID: "removed"}))
+
+	subnets, err := joinedSubnetDefinitions(joinedSubnets, repoFactory)
+	assert.NoError(t, err)
+	assert.Equal(t, []joinedSubnet{{name: repoAlias + ":spaces", repoAlias: repoAlias, definition: spaces}}, subnets)
+
+	assert.True(t, subnets[0].includes(repoAlias+":spacesvm"))
+	assert.False(t, subnets[0].includes("organization/other:spacesvm"))
+}
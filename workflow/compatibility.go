@@ -0,0 +1,86 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+
+	"github.com/DioneProtocol/opm/info"
+	"github.com/DioneProtocol/opm/types"
+)
+
+var _ CompatibilityChecker = &NodeCompatibilityChecker{}
+
+// CompatibilityChecker checks whether a VM can be loaded by the node before
+// it's installed.
+type CompatibilityChecker interface {
+	// Check returns an error if the VM with the fully qualified [name] can't
+	// be installed.
+	Check(name string, vm types.VM) error
+}
+
+type NodeCompatibilityCheckerConfig struct {
+	InfoClient      info.Client
+	NodeAPIEndpoint string
+	// Force installs VMs even if they're incompatible with the node.
+	Force bool
+}
+
+func NewNodeCompatibilityChecker(config NodeCompatibilityCheckerConfig) *NodeCompatibilityChecker {
+	return &NodeCompatibilityChecker{
+		infoClient:      config.InfoClient,
+		nodeAPIEndpoint: config.NodeAPIEndpoint,
+		force:           config.Force,
+	}
+}
+
+// NodeCompatibilityChecker checks VMs against the version of the running
+// node. If the node is offline, the check is skipped.
+type NodeCompatibilityChecker struct {
+	infoClient      info.Client
+	nodeAPIEndpoint string
+	force           bool
+
+	// The node's version is only queried once.
+	queried     bool
+	offline     bool
+	nodeVersion info.NodeVersion
+}
+
+func (n *NodeCompatibilityChecker) Check(name string, vm types.VM) error {
+	if !vm.Compatibility.Declared() {
+		return nil
+	}
+
+	if !n.queried {
+		nodeVersion, err := n.infoClient.GetNodeVersion()
+		switch {
+		case errors.Is(err, syscall.ECONNREFUSED):
+			n.offline = true
+		case err != nil:
+			return fmt.Errorf("failed to get the node's version: %w", err)
+		}
+
+		n.queried = true
+		n.nodeVersion = nodeVersion
+	}
+
+	if n.offline {
+		fmt.Printf("Warning - node at %s was offline. Skipping compatibility check for %s.\n", n.nodeAPIEndpoint, name)
+		return nil
+	}
+
+	err := vm.Compatibility.Check(n.nodeVersion.Version, n.nodeVersion.RPCProtocolVersion)
+	switch {
+	case err == nil:
+		return nil
+	case n.force:
+		fmt.Printf("Warning - %s is incompatible with the node (%s). Installing anyways...\n", name, err)
+		return nil
+	default:
+		return fmt.Errorf("%s is incompatible with the node (use --force to install anyways): %w", name, err)
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/info"
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestNodeCompatibilityCheckerCheck(t *testing.T) {
+	var (
+		nodeVersion = info.NodeVersion{
+			Version:            version.Semantic{Major: 1, Minor: 10, Patch: 10},
+			RPCProtocolVersion: 28,
+		}
+		compatibleVM = types.VM{
+			Compatibility: types.Compatibility{RPCChainVMProtocol: 28},
+		}
+		incompatibleVM = types.VM{
+			Compatibility: types.Compatibility{MaxNodeVersion: "v1.10.0"},
+		}
+
+		errFoo = errors.New("foo")
+	)
+
+	tests := []struct {
+		name    string
+		vm      types.VM
+		force   bool
+		setup   func(*info.MockClient)
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "no constraints",
+			vm:      types.VM{},
+			setup:   func(*info.MockClient) {},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "compatible",
+			vm:   compatibleVM,
+			setup: func(client *info.MockClient) {
+				client.EXPECT().GetNodeVersion().Return(nodeVersion, nil)
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "incompatible",
+			vm:   incompatibleVM,
+			setup: func(client *info.MockClient) {
+				client.EXPECT().GetNodeVersion().Return(nodeVersion, nil)
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name:  "incompatible with force",
+			vm:    incompatibleVM,
+			force: true,
+			setup: func(client *info.MockClient) {
+				client.EXPECT().GetNodeVersion().Return(nodeVersion, nil)
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "node offline",
+			vm:   incompatibleVM,
+			setup: func(client *info.MockClient) {
+				client.EXPECT().GetNodeVersion().Return(info.NodeVersion{}, fmt.Errorf("failed to issue request: %w", syscall.ECONNREFUSED))
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "request fails",
+			vm:   compatibleVM,
+			setup: func(client *info.MockClient) {
+				client.EXPECT().GetNodeVersion().Return(info.NodeVersion{}, errFoo)
+			},
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, errFoo)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			client := info.NewMockClient(ctrl)
+			test.setup(client)
+
+			checker := NewNodeCompatibilityChecker(NodeCompatibilityCheckerConfig{
+				InfoClient:      client,
+				NodeAPIEndpoint: "127.0.0.1:9650",
+				Force:           test.force,
+			})
+
+			test.wantErr(t, checker.Check("organization/repository:vm", test.vm))
+		})
+	}
+}
+
+func TestNodeCompatibilityCheckerQueriesOnce(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	client := info.NewMockClient(ctrl)
+	client.EXPECT().GetNodeVersion().Return(info.NodeVersion{RPCProtocolVersion: 28}, nil).Times(1)
+
+	checker := NewNodeCompatibilityChecker(NodeCompatibilityCheckerConfig{
+		InfoClient: client,
+	})
+
+	vm := types.VM{Compatibility: types.Compatibility{RPCChainVMProtocol: 28}}
+	assert.NoError(t, checker.Check("organization/repository:foovm", vm))
+	assert.NoError(t, checker.Check("organization/repository:barvm", vm))
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+
+	"github.com/DioneProtocol/opm/config"
+	"github.com/DioneProtocol/opm/git"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/util"
+)
+
+var _ Workflow = &ExportState{}
+
+type ExportStateConfig struct {
+	// Path is where the state archive is written.
+	Path string
+
+	SourcesList   storage.Storage[storage.SourceInfo]
+	InstalledVMs  storage.Storage[storage.InstallInfo]
+	JoinedSubnets storage.Storage[storage.JoinInfo]
+
+	RepositoriesPath string
+	Reader           git.FileReader
+	Fs               afero.Fs
+}
+
+func NewExportState(config ExportStateConfig) *ExportState {
+	return &ExportState{
+		path:             config.Path,
+		sourcesList:      config.SourcesList,
+		installedVMs:     config.InstalledVMs,
+		joinedSubnets:    config.JoinedSubnets,
+		repositoriesPath: config.RepositoriesPath,
+		reader:           config.Reader,
+		fs:               config.Fs,
+	}
+}
+
+// ExportState writes a state archive recording every tracked repository,
+// installed VM and joined subnet.
+type ExportState struct {
+	path string
+
+	sourcesList   storage.Storage[storage.SourceInfo]
+	installedVMs  storage.Storage[storage.InstallInfo]
+	joinedSubnets storage.Storage[storage.JoinInfo]
+
+	repositoriesPath string
+	reader           git.FileReader
+	fs               afero.Fs
+}
+
+func (e *ExportState) Execute() error {
+	state := config.State{
+		Version:      config.StateVersion,
+		Repositories: []config.StateRepository{},
+		VMs:          []config.LockedVM{},
+		Subnets:      []config.StateSubnet{},
+	}
+
+	repositories := map[string]storage.SourceInfo{}
+	sourceItr := e.sourcesList.Iterator()
+	defer sourceItr.Release()
+
+	for sourceItr.Next() {
+		alias := string(sourceItr.Key())
+		source, err := sourceItr.Value()
+		if err != nil {
+			return err
+		}
+
+		repository := config.StateRepository{Repository: config.NewRepository(source)}
+		repository.Alias = alias
+		if source.Commit != plumbing.ZeroHash {
+			repository.Commit = source.Commit.String()
+		}
+		state.Repositories = append(state.Repositories, repository)
+		repositories[alias] = source
+	}
+	if err := sourceItr.Error(); err != nil {
+		return err
+	}
+
+	vmItr := e.installedVMs.Iterator()
+	defer vmItr.Release()
+
+	for vmItr.Next() {
+		name := string(vmItr.Key())
+		installInfo, err := vmItr.Value()
+		if err != nil {
+			return err
+		}
+
+		repoAlias, _ := util.ParseQualifiedName(name)
+		source, ok := repositories[repoAlias]
+		if !ok {
+			return fmt.Errorf("%s was installed from %s, which is no longer tracked", name, repoAlias)
+		}
+
+		locked, err := lockVM(e.reader, e.repositoriesPath, name, source, installInfo)
+		if err != nil {
+			return err
+		}
+		state.VMs = append(state.VMs, locked)
+	}
+	if err := vmItr.Error(); err != nil {
+		return err
+	}
+
+	subnetItr := e.joinedSubnets.Iterator()
+	defer subnetItr.Release()
+
+	for subnetItr.Next() {
+		name := string(subnetItr.Key())
+		joinInfo, err := subnetItr.Value()
+		if err != nil {
+			return err
+		}
+
+		repoAlias, _ := util.ParseQualifiedName(name)
+		if _, ok := repositories[repoAlias]; !ok {
+			return fmt.Errorf("%s was joined from %s, which is no longer tracked", name, repoAlias)
+		}
+
+		state.Subnets = append(state.Subnets, config.StateSubnet{
+			Name: name,
+			ID:   joinInfo.ID,
+		})
+	}
+	if err := subnetItr.Error(); err != nil {
+		return err
+	}
+
+	if err := config.WriteState(e.fs, e.path, state); err != nil {
+		return err
+	}
+
+	fmt.Printf(
+		"Exported %d repositories, %d virtual machines and %d subnets to %s.\n",
+		len(state.Repositories),
+		len(state.VMs),
+		len(state.Subnets),
+		e.path,
+	)
+	return nil
+}
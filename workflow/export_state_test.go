@@ -0,0 +1,161 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/DioneProtocol/opm/config"
+	"github.com/DioneProtocol/opm/git"
+	"github.com/DioneProtocol/opm/storage"
+)
+
+func TestExportStateExecute(t *testing.T) {
+	commit := plumbing.NewHash(lockedCommit)
+	subnetID := ids.GenerateTestID().String()
+
+	type stores struct {
+		sourcesList   storage.Storage[storage.SourceInfo]
+		installedVMs  storage.Storage[storage.InstallInfo]
+		joinedSubnets storage.Storage[storage.JoinInfo]
+	}
+
+	tests := []struct {
+		name    string
+		setup   func(*testing.T, stores)
+		want    config.State
+		wantErr bool
+	}{
+		{
+			name:  "nothing tracked",
+			setup: func(*testing.T, stores) {},
+			want: config.State{
+				Version:      config.StateVersion,
+				Repositories: []config.StateRepository{},
+				VMs:          []config.LockedVM{},
+				Subnets:      []config.StateSubnet{},
+			},
+		},
+		{
+			name: "everything exported",
+			setup: func(t *testing.T, s stores) {
+				assert.NoError(t, s.sourcesList.Put([]byte(lockedRepository.Alias), storage.SourceInfo{
+					URL:           lockedRepository.URL,
+					Branch:        plumbing.NewBranchReferenceName(lockedRepository.Branch),
+					Commit:        commit,
+					ReferenceType: storage.BranchReference,
+					Source:        storage.GitSource,
+				}))
+				assert.NoError(t, s.sourcesList.Put([]byte("organization/unsynced"), storage.SourceInfo{
+					URL:           "https://github.com/organization/unsynced.git",
+					ReferenceType: storage.TagReference,
+					Reference:     "v1.0.0",
+					Source:        storage.GitSource,
+				}))
+				assert.NoError(t, s.sourcesList.Put([]byte("organization/index"), storage.SourceInfo{
+					URL:       "https://example.com/index.yaml",
+					Source:    storage.HTTPSource,
+					PublicKey: "abcd",
+				}))
+				assert.NoError(t, s.installedVMs.Put([]byte(lockedVM.Name), storage.InstallInfo{
+					ID:      lockedVM.ID,
+					Version: version.Semantic{Major: 1, Minor: 2, Patch: 3},
+					Commit:  commit,
+					URL:     lockedVM.URL,
+					SHA256:  lockedVM.SHA256,
+				}))
+				assert.NoError(t, s.joinedSubnets.Put([]byte("organization/repository:foo"), storage.JoinInfo{ID: subnetID}))
+			},
+			want: config.State{
+				Version: config.StateVersion,
+				Repositories: []config.StateRepository{
+					{
+						Repository: config.Repository{
+							Alias:     "organization/index",
+							URL:       "https://example.com/index.yaml",
+							Source:    storage.HTTPSource,
+							PublicKey: "abcd",
+						},
+					},
+					{
+						Repository: config.Repository{
+							Alias:         lockedRepository.Alias,
+							URL:           lockedRepository.URL,
+							Source:        storage.GitSource,
+							Branch:        lockedRepository.Branch,
+							ReferenceType: storage.BranchReference,
+						},
+						Commit: lockedCommit,
+					},
+					{
+						Repository: config.Repository{
+							Alias:         "organization/unsynced",
+							URL:           "https://github.com/organization/unsynced.git",
+							Source:        storage.GitSource,
+							ReferenceType: storage.TagReference,
+							Reference:     "v1.0.0",
+						},
+					},
+				},
+				VMs:     []config.LockedVM{lockedVM},
+				Subnets: []config.StateSubnet{{Name: "organization/repository:foo", ID: subnetID}},
+			},
+		},
+		{
+			name: "vm from untracked repository",
+			setup: func(t *testing.T, s stores) {
+				assert.NoError(t, s.installedVMs.Put([]byte(lockedVM.Name), storage.InstallInfo{ID: lockedVM.ID, Commit: commit}))
+			},
+			wantErr: true,
+		},
+		{
+			name: "subnet from untracked repository",
+			setup: func(t *testing.T, s stores) {
+				assert.NoError(t, s.joinedSubnets.Put([]byte("organization/repository:foo"), storage.JoinInfo{ID: subnetID}))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := memdb.New()
+			fs := afero.NewMemMapFs()
+			s := stores{
+				sourcesList:   storage.NewSourceInfo(db),
+				installedVMs:  storage.NewInstalledVMs(db),
+				joinedSubnets: storage.NewJoinedSubnets(db),
+			}
+			test.setup(t, s)
+
+			err := NewExportState(ExportStateConfig{
+				Path:             "opm-state.yaml",
+				SourcesList:      s.sourcesList,
+				InstalledVMs:     s.installedVMs,
+				JoinedSubnets:    s.joinedSubnets,
+				RepositoriesPath: "repositories",
+				Reader:           git.NewMockFileReader(ctrl),
+				Fs:               fs,
+			}).Execute()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			got, err := config.ReadState(fs, "opm-state.yaml")
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"fmt"
+	"path/filepath"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+
+	"github.com/DioneProtocol/opm/config"
+	"github.com/DioneProtocol/opm/git"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/util"
+)
+
+var _ Workflow = &Freeze{}
+
+type FreezeConfig struct {
+	// Path is where the lockfile is written.
+	Path string
+
+	InstalledVMs storage.Storage[storage.InstallInfo]
+	SourcesList  storage.Storage[storage.SourceInfo]
+
+	RepositoriesPath string
+	Reader           git.FileReader
+	Fs               afero.Fs
+}
+
+func NewFreeze(config FreezeConfig) *Freeze {
+	return &Freeze{
+		path:             config.Path,
+		installedVMs:     config.InstalledVMs,
+		sourcesList:      config.SourcesList,
+		repositoriesPath: config.RepositoriesPath,
+		reader:           config.Reader,
+		fs:               config.Fs,
+	}
+}
+
+// Freeze writes a lockfile recording every installed VM.
+type Freeze struct {
+	path string
+
+	installedVMs storage.Storage[storage.InstallInfo]
+	sourcesList  storage.Storage[storage.SourceInfo]
+
+	repositoriesPath string
+	reader           git.FileReader
+	fs               afero.Fs
+}
+
+func (f *Freeze) Execute() error {
+	lockfile := config.Lockfile{
+		Version:      config.LockfileVersion,
+		Repositories: []config.LockedRepository{},
+		VMs:          []config.LockedVM{},
+	}
+	repositories := map[string]struct{}{}
+
+	itr := f.installedVMs.Iterator()
+	defer itr.Release()
+
+	for itr.Next() {
+		name := string(itr.Key())
+		installInfo, err := itr.Value()
+		if err != nil {
+			return err
+		}
+
+		repoAlias, _ := util.ParseQualifiedName(name)
+		source, err := f.sourcesList.Get([]byte(repoAlias))
+		if err == database.ErrNotFound {
+			return fmt.Errorf("%s was installed from %s, which is no longer tracked", name, repoAlias)
+		} else if err != nil {
+			return err
+		}
+
+		if _, ok := repositories[repoAlias]; !ok {
+			repositories[repoAlias] = struct{}{}
+			repository := config.NewRepository(source)
+			repository.Alias = repoAlias
+			lockfile.Repositories = append(lockfile.Repositories, repository)
+		}
+
+		locked, err := lockVM(f.reader, f.repositoriesPath, name, source, installInfo)
+		if err != nil {
+			return err
+		}
+		lockfile.VMs = append(lockfile.VMs, locked)
+	}
+	if err := itr.Error(); err != nil {
+		return err
+	}
+
+	if err := config.WriteLockfile(f.fs, f.path, lockfile); err != nil {
+		return err
+	}
+
+	fmt.Printf("Locked %d virtual machines in %s.\n", len(lockfile.VMs), f.path)
+	return nil
+}
+
+// lockVM returns the installed VM [name] from [source] as it's recorded in a
+// lockfile.
+func lockVM(reader git.FileReader, repositoriesPath string, name string, source storage.SourceInfo, installInfo storage.InstallInfo) (config.LockedVM, error) {
+	if installInfo.Commit == plumbing.ZeroHash {
+		return config.LockedVM{}, fmt.Errorf("%s was installed by an older version of the opm which didn't record its commit. Reinstall it to lock it", name)
+	}
+
+	// VMs installed by older versions of opm don't have their artifact
+	// recorded, so it's read from the definition they were installed from
+	// instead.
+	url, sha256 := installInfo.URL, installInfo.SHA256
+	if url == "" || sha256 == "" {
+		if source.Source == storage.HTTPSource || source.Source == storage.LocalSource {
+			return config.LockedVM{}, fmt.Errorf("%s didn't record its artifact, and %s repositories don't keep old definitions. Reinstall it to lock it", name, source.Source)
+		}
+
+		repoAlias, plugin := util.ParseQualifiedName(name)
+		organization, repo := util.ParseAlias(repoAlias)
+		vm, err := definitionAt(reader, filepath.Join(repositoriesPath, organization, repo), installInfo.Commit, plugin)
+		if err != nil {
+			return config.LockedVM{}, fmt.Errorf("failed to read the definition %s was installed from: %w", name, err)
+		}
+		url, sha256 = vm.URL, vm.SHA256
+	}
+
+	return config.LockedVM{
+		Name:    name,
+		ID:      installInfo.ID,
+		Version: installInfo.Version.String(),
+		Commit:  installInfo.Commit.String(),
+		URL:     url,
+		SHA256:  sha256,
+	}, nil
+}
@@ -0,0 +1,158 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/DioneProtocol/opm/config"
+	"github.com/DioneProtocol/opm/git"
+	"github.com/DioneProtocol/opm/storage"
+)
+
+func TestFreezeExecute(t *testing.T) {
+	commit := plumbing.NewHash(lockedCommit)
+
+	tests := []struct {
+		name    string
+		setup   func(*testing.T, storage.Storage[storage.SourceInfo], storage.Storage[storage.InstallInfo], *git.MockFileReader)
+		want    config.Lockfile
+		wantErr bool
+	}{
+		{
+			name: "nothing installed",
+			setup: func(*testing.T, storage.Storage[storage.SourceInfo], storage.Storage[storage.InstallInfo], *git.MockFileReader) {
+			},
+			want: config.Lockfile{
+				Version:      config.LockfileVersion,
+				Repositories: []config.LockedRepository{},
+				VMs:          []config.LockedVM{},
+			},
+		},
+		{
+			name: "recorded artifact",
+			setup: func(t *testing.T, sourcesList storage.Storage[storage.SourceInfo], installedVMs storage.Storage[storage.InstallInfo], _ *git.MockFileReader) {
+				assert.NoError(t, sourcesList.Put([]byte(lockedRepository.Alias), storage.SourceInfo{
+					URL:    lockedRepository.URL,
+					Branch: plumbing.NewBranchReferenceName(lockedRepository.Branch),
+				}))
+				assert.NoError(t, installedVMs.Put([]byte(lockedVM.Name), storage.InstallInfo{
+					ID:      lockedVM.ID,
+					Version: version.Semantic{Major: 1, Minor: 2, Patch: 3},
+					Commit:  commit,
+					URL:     lockedVM.URL,
+					SHA256:  lockedVM.SHA256,
+				}))
+			},
+			want: config.Lockfile{
+				Version:      config.LockfileVersion,
+				Repositories: []config.LockedRepository{lockedRepository},
+				VMs:          []config.LockedVM{lockedVM},
+			},
+		},
+		{
+			name: "artifact read from definition",
+			setup: func(t *testing.T, sourcesList storage.Storage[storage.SourceInfo], installedVMs storage.Storage[storage.InstallInfo], reader *git.MockFileReader) {
+				assert.NoError(t, sourcesList.Put([]byte(lockedRepository.Alias), storage.SourceInfo{
+					URL:    lockedRepository.URL,
+					Branch: plumbing.NewBranchReferenceName(lockedRepository.Branch),
+				}))
+				assert.NoError(t, installedVMs.Put([]byte(lockedVM.Name), storage.InstallInfo{
+					ID:      lockedVM.ID,
+					Version: version.Semantic{Major: 1, Minor: 2, Patch: 3},
+					Commit:  commit,
+				}))
+				reader.EXPECT().ReadDir(filepath.Join("repositories", "organization", "repository"), commit, "vms").Return(map[string][]byte{"foovm.yaml": lockedDefinition}, nil)
+			},
+			want: config.Lockfile{
+				Version:      config.LockfileVersion,
+				Repositories: []config.LockedRepository{lockedRepository},
+				VMs:          []config.LockedVM{lockedVM},
+			},
+		},
+		{
+			name: "local repository",
+			setup: func(t *testing.T, sourcesList storage.Storage[storage.SourceInfo], installedVMs storage.Storage[storage.InstallInfo], _ *git.MockFileReader) {
+				assert.NoError(t, sourcesList.Put([]byte(lockedRepository.Alias), storage.SourceInfo{URL: "/plugins", Source: storage.LocalSource}))
+				assert.NoError(t, installedVMs.Put([]byte(lockedVM.Name), storage.InstallInfo{
+					ID:      lockedVM.ID,
+					Version: version.Semantic{Major: 1, Minor: 2, Patch: 3},
+					Commit:  commit,
+					URL:     lockedVM.URL,
+					SHA256:  lockedVM.SHA256,
+				}))
+			},
+			want: config.Lockfile{
+				Version:      config.LockfileVersion,
+				Repositories: []config.LockedRepository{{Alias: lockedRepository.Alias, URL: "/plugins", Source: storage.LocalSource}},
+				VMs:          []config.LockedVM{lockedVM},
+			},
+		},
+		{
+			name: "local repository without recorded artifact",
+			setup: func(t *testing.T, sourcesList storage.Storage[storage.SourceInfo], installedVMs storage.Storage[storage.InstallInfo], _ *git.MockFileReader) {
+				assert.NoError(t, sourcesList.Put([]byte(lockedRepository.Alias), storage.SourceInfo{URL: "/plugins", Source: storage.LocalSource}))
+				assert.NoError(t, installedVMs.Put([]byte(lockedVM.Name), storage.InstallInfo{
+					ID:      lockedVM.ID,
+					Version: version.Semantic{Major: 1, Minor: 2, Patch: 3},
+					Commit:  commit,
+				}))
+			},
+			wantErr: true,
+		},
+		{
+			name: "unknown commit",
+			setup: func(t *testing.T, sourcesList storage.Storage[storage.SourceInfo], installedVMs storage.Storage[storage.InstallInfo], _ *git.MockFileReader) {
+				assert.NoError(t, sourcesList.Put([]byte(lockedRepository.Alias), storage.SourceInfo{URL: lockedRepository.URL}))
+				assert.NoError(t, installedVMs.Put([]byte(lockedVM.Name), storage.InstallInfo{ID: lockedVM.ID}))
+			},
+			wantErr: true,
+		},
+		{
+			name: "repository removed",
+			setup: func(t *testing.T, _ storage.Storage[storage.SourceInfo], installedVMs storage.Storage[storage.InstallInfo], _ *git.MockFileReader) {
+				assert.NoError(t, installedVMs.Put([]byte(lockedVM.Name), storage.InstallInfo{ID: lockedVM.ID, Commit: commit}))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := memdb.New()
+			fs := afero.NewMemMapFs()
+			sourcesList := storage.NewSourceInfo(db)
+			installedVMs := storage.NewInstalledVMs(db)
+			reader := git.NewMockFileReader(ctrl)
+			test.setup(t, sourcesList, installedVMs, reader)
+
+			err := NewFreeze(FreezeConfig{
+				Path:             "opm.lock",
+				InstalledVMs:     installedVMs,
+				SourcesList:      sourcesList,
+				RepositoriesPath: "repositories",
+				Reader:           reader,
+				Fs:               fs,
+			}).Execute()
+			if test.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			got, err := config.ReadLockfile(fs, "opm.lock")
+			assert.NoError(t, err)
+			assert.Equal(t, test.want, got)
+		})
+	}
+}
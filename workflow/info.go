@@ -0,0 +1,183 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/DioneProtocol/odysseygo/database"
+
+	"github.com/DioneProtocol/opm/constant"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+var _ Workflow = &Info{}
+
+type InfoConfig struct {
+	Name      string
+	Plugin    string
+	RepoAlias string
+	// Type limits the definitions shown to VMs or subnets. Both are shown if
+	// it's empty.
+	Type string
+
+	Repository   storage.Repository
+	SourcesList  storage.Storage[storage.SourceInfo]
+	InstalledVMs storage.Storage[storage.InstallInfo]
+}
+
+func NewInfo(config InfoConfig) *Info {
+	return &Info{
+		name:         config.Name,
+		plugin:       config.Plugin,
+		repoAlias:    config.RepoAlias,
+		kind:         config.Type,
+		repository:   config.Repository,
+		sourcesList:  config.SourcesList,
+		installedVMs: config.InstalledVMs,
+		out:          os.Stdout,
+	}
+}
+
+type Info struct {
+	name      string
+	plugin    string
+	repoAlias string
+	kind      string
+
+	repository   storage.Repository
+	sourcesList  storage.Storage[storage.SourceInfo]
+	installedVMs storage.Storage[storage.InstallInfo]
+
+	out io.Writer
+}
+
+func (i *Info) Execute() error {
+	sourceInfo, err := i.sourcesList.Get([]byte(i.repoAlias))
+	if err == database.ErrNotFound {
+		return fmt.Errorf("%s is not a tracked repository", i.repoAlias)
+	} else if err != nil {
+		return err
+	}
+
+	// A VM and a subnet may share the same alias, so show both if they exist
+	// and the type isn't specified.
+	found := false
+
+	if i.kind != subnetKey {
+		vm, err := i.repository.VMs.Get([]byte(i.plugin))
+		switch err {
+		case nil:
+			found = true
+			if err := i.vmInfo(sourceInfo, vm); err != nil {
+				return err
+			}
+		case database.ErrNotFound:
+		default:
+			return err
+		}
+	}
+
+	if i.kind != vmKey {
+		subnet, err := i.repository.Subnets.Get([]byte(i.plugin))
+		switch err {
+		case nil:
+			if found {
+				fmt.Fprintln(i.out)
+			}
+			found = true
+			if err := i.subnetInfo(sourceInfo, subnet); err != nil {
+				return err
+			}
+		case database.ErrNotFound:
+		default:
+			return err
+		}
+	}
+
+	if !found {
+		return fmt.Errorf("no virtual machine or subnet definition found for %s", i.name)
+	}
+
+	return nil
+}
+
+func (i *Info) vmInfo(sourceInfo storage.SourceInfo, definition storage.Definition[types.VM]) error {
+	vm := definition.Definition
+
+	installed, err := i.installedVersion(i.name)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(i.out, 1, 1, 1, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", i.name)
+	fmt.Fprintf(w, "Type:\t%s\n", vmKey)
+	fmt.Fprintf(w, "ID:\t%s\n", vm.ID)
+	fmt.Fprintf(w, "Version:\t%s\n", vm.Version.String())
+	fmt.Fprintf(w, "Homepage:\t%s\n", vm.Homepage)
+	fmt.Fprintf(w, "Description:\t%s\n", strings.TrimSpace(vm.Description))
+	fmt.Fprintf(w, "Maintainers:\t%s\n", strings.Join(vm.Maintainers, ", "))
+	fmt.Fprintf(w, "URL:\t%s\n", vm.URL)
+	fmt.Fprintf(w, "SHA256:\t%s\n", vm.SHA256)
+	fmt.Fprintf(w, "Install script:\t%s\n", valueOrNone(vm.InstallScript))
+	fmt.Fprintf(w, "Binary path:\t%s\n", vm.BinaryPath)
+	fmt.Fprintf(w, "Repository:\t%s (%s)\n", sourceInfo.Alias, sourceInfo.URL)
+	fmt.Fprintf(w, "Commit:\t%s\n", definition.Commit)
+	fmt.Fprintf(w, "Installed:\t%s\n", installed)
+
+	return w.Flush()
+}
+
+func (i *Info) subnetInfo(sourceInfo storage.SourceInfo, definition storage.Definition[types.Subnet]) error {
+	subnet := definition.Definition
+
+	w := tabwriter.NewWriter(i.out, 1, 1, 1, ' ', 0)
+	fmt.Fprintf(w, "Name:\t%s\n", i.name)
+	fmt.Fprintf(w, "Type:\t%s\n", subnetKey)
+	fmt.Fprintf(w, "ID:\t%s\n", subnet.ID)
+	fmt.Fprintf(w, "Homepage:\t%s\n", subnet.Homepage)
+	fmt.Fprintf(w, "Description:\t%s\n", strings.TrimSpace(subnet.Description))
+	fmt.Fprintf(w, "Maintainers:\t%s\n", strings.Join(subnet.Maintainers, ", "))
+	fmt.Fprintf(w, "Repository:\t%s (%s)\n", sourceInfo.Alias, sourceInfo.URL)
+	fmt.Fprintf(w, "Commit:\t%s\n", definition.Commit)
+	fmt.Fprintf(w, "VMs:\t\n")
+
+	for _, vm := range subnet.VMs {
+		installed, err := i.installedVersion(strings.Join([]string{i.repoAlias, vm}, constant.QualifiedNameDelimiter))
+		if err != nil {
+			return err
+		}
+
+		fmt.Fprintf(w, "  %s\t%s\n", vm, installed)
+	}
+
+	return w.Flush()
+}
+
+// installedVersion returns a human-readable description of whether the VM
+// with the fully qualified [name] is installed.
+func (i *Info) installedVersion(name string) (string, error) {
+	installInfo, err := i.installedVMs.Get([]byte(name))
+	if err == database.ErrNotFound {
+		return "no", nil
+	} else if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("yes (%s)", installInfo.Version.String()), nil
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "none"
+	}
+
+	return value
+}
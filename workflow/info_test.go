@@ -0,0 +1,216 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestInfoExecute(t *testing.T) {
+	const (
+		alias = "organization/repository"
+		url   = "url"
+	)
+
+	var (
+		errWrong = fmt.Errorf("something went wrong")
+		commit   = plumbing.Hash{1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1, 1}
+
+		sourceInfo = storage.SourceInfo{
+			Alias:  alias,
+			URL:    url,
+			Branch: plumbing.NewBranchReferenceName("branch"),
+			Commit: commit,
+		}
+
+		vm = storage.Definition[types.VM]{
+			Definition: types.VM{
+				ID:            "vmID",
+				Alias:         "spacesvm",
+				Homepage:      "https://tryspaces.xyz",
+				Description:   "Virtual machine that processes the spaces subnet.",
+				Maintainers:   []string{"joshua", "kim"},
+				InstallScript: "scripts/build.sh",
+				BinaryPath:    "build/vmID",
+				URL:           "https://www.website.com/spacesvm.tar.gz",
+				SHA256:        "sha256",
+				Version:       version.Semantic{Major: 1, Minor: 2, Patch: 3},
+			},
+			Commit: commit,
+		}
+
+		subnet = storage.Definition[types.Subnet]{
+			Definition: types.Subnet{
+				ID:          "subnetID",
+				Alias:       "spaces",
+				Description: "Spaces subnet.",
+				VMs:         []string{"spacesvm", "timestampvm"},
+			},
+			Commit: commit,
+		}
+	)
+
+	type mocks struct {
+		sourcesList  *storage.MockStorage[storage.SourceInfo]
+		installedVMs *storage.MockStorage[storage.InstallInfo]
+		vms          *storage.MockStorage[storage.Definition[types.VM]]
+		subnets      *storage.MockStorage[storage.Definition[types.Subnet]]
+	}
+	tests := []struct {
+		name    string
+		plugin  string
+		kind    string
+		setup   func(mocks)
+		matches []string
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:   "untracked repository",
+			plugin: "spacesvm",
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Get([]byte(alias)).Return(storage.SourceInfo{}, database.ErrNotFound)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.Error(t, err)
+			},
+		},
+		{
+			name:   "can't read vm definitions",
+			plugin: "spacesvm",
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Get([]byte(alias)).Return(sourceInfo, nil)
+				mocks.vms.EXPECT().Get([]byte("spacesvm")).Return(storage.Definition[types.VM]{}, errWrong)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.Equal(t, errWrong, err)
+			},
+		},
+		{
+			name:   "no definition",
+			plugin: "foobar",
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Get([]byte(alias)).Return(sourceInfo, nil)
+				mocks.vms.EXPECT().Get([]byte("foobar")).Return(storage.Definition[types.VM]{}, database.ErrNotFound)
+				mocks.subnets.EXPECT().Get([]byte("foobar")).Return(storage.Definition[types.Subnet]{}, database.ErrNotFound)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.Error(t, err)
+			},
+		},
+		{
+			name:   "installed vm",
+			plugin: "spacesvm",
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Get([]byte(alias)).Return(sourceInfo, nil)
+				mocks.vms.EXPECT().Get([]byte("spacesvm")).Return(vm, nil)
+				mocks.installedVMs.EXPECT().Get([]byte(alias+":spacesvm")).Return(storage.InstallInfo{
+					ID:      "vmID",
+					Version: version.Semantic{Major: 1, Minor: 2, Patch: 2},
+				}, nil)
+				mocks.subnets.EXPECT().Get([]byte("spacesvm")).Return(storage.Definition[types.Subnet]{}, database.ErrNotFound)
+			},
+			matches: []string{
+				`ID:\s+vmID`,
+				`Version:\s+v1\.2\.3`,
+				`Homepage:\s+https://tryspaces\.xyz`,
+				`Maintainers:\s+joshua, kim`,
+				`URL:\s+https://www\.website\.com/spacesvm\.tar\.gz`,
+				`Install script:\s+scripts/build\.sh`,
+				`Commit:\s+` + commit.String(),
+				`Installed:\s+yes \(v1\.2\.2\)`,
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.NoError(t, err)
+			},
+		},
+		{
+			name:   "subnet with partially installed vms",
+			plugin: "spaces",
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Get([]byte(alias)).Return(sourceInfo, nil)
+				mocks.vms.EXPECT().Get([]byte("spaces")).Return(storage.Definition[types.VM]{}, database.ErrNotFound)
+				mocks.subnets.EXPECT().Get([]byte("spaces")).Return(subnet, nil)
+				mocks.installedVMs.EXPECT().Get([]byte(alias+":spacesvm")).Return(storage.InstallInfo{
+					ID:      "vmID",
+					Version: version.Semantic{Major: 1, Minor: 2, Patch: 3},
+				}, nil)
+				mocks.installedVMs.EXPECT().Get([]byte(alias+":timestampvm")).Return(storage.InstallInfo{}, database.ErrNotFound)
+			},
+			matches: []string{
+				`ID:\s+subnetID`,
+				`spacesvm\s+yes \(v1\.2\.3\)`,
+				`timestampvm\s+no`,
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.NoError(t, err)
+			},
+		},
+		{
+			name:   "only the subnet is shown",
+			plugin: "spaces",
+			kind:   "subnet",
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Get([]byte(alias)).Return(sourceInfo, nil)
+				mocks.subnets.EXPECT().Get([]byte("spaces")).Return(subnet, nil)
+				mocks.installedVMs.EXPECT().Get(gomock.Any()).Return(storage.InstallInfo{}, database.ErrNotFound).Times(2)
+			},
+			matches: []string{
+				`Type:\s+subnet`,
+				`ID:\s+subnetID`,
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.NoError(t, err)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			sourcesList := storage.NewMockStorage[storage.SourceInfo](ctrl)
+			installedVMs := storage.NewMockStorage[storage.InstallInfo](ctrl)
+			vms := storage.NewMockStorage[storage.Definition[types.VM]](ctrl)
+			subnets := storage.NewMockStorage[storage.Definition[types.Subnet]](ctrl)
+
+			test.setup(mocks{
+				sourcesList:  sourcesList,
+				installedVMs: installedVMs,
+				vms:          vms,
+				subnets:      subnets,
+			})
+
+			wf := NewInfo(InfoConfig{
+				Name:      alias + ":" + test.plugin,
+				Plugin:    test.plugin,
+				RepoAlias: alias,
+				Type:      test.kind,
+				Repository: storage.Repository{
+					VMs:     vms,
+					Subnets: subnets,
+				},
+				SourcesList:  sourcesList,
+				InstalledVMs: installedVMs,
+			})
+			out := &bytes.Buffer{}
+			wf.out = out
+
+			test.wantErr(t, wf.Execute())
+			for _, match := range test.matches {
+				assert.Regexp(t, match, out.String())
+			}
+		})
+	}
+}
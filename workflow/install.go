@@ -32,6 +32,11 @@ type InstallConfig struct {
 	VMStorage    storage.Storage[storage.Definition[types.VM]]
 	Fs           afero.Fs
 	Installer    Installer
+	Checker      CompatibilityChecker
+
+	// Definition is installed instead of the one in VMStorage if it's set
+	// (e.g. to install a locked version).
+	Definition *storage.Definition[types.VM]
 }
 
 func NewInstall(config InstallConfig) *Install {
@@ -46,6 +51,8 @@ func NewInstall(config InstallConfig) *Install {
 		vmStorage:    config.VMStorage,
 		fs:           config.Fs,
 		installer:    config.Installer,
+		checker:      config.Checker,
+		definition:   config.Definition,
 		checksummer:  checksum.NewSHA256(config.Fs),
 	}
 }
@@ -62,6 +69,8 @@ type Install struct {
 	vmStorage    storage.Storage[storage.Definition[types.VM]]
 	fs           afero.Fs
 	installer    Installer
+	checker      CompatibilityChecker
+	definition   *storage.Definition[types.VM]
 	checksummer  checksum.Checksummer
 }
 
@@ -71,13 +80,21 @@ func (i Install) Execute() error {
 		err        error
 	)
 
-	definition, err = i.vmStorage.Get([]byte(i.plugin))
-	if err != nil {
-		return err
+	if i.definition != nil {
+		definition = *i.definition
+	} else {
+		definition, err = i.vmStorage.Get([]byte(i.plugin))
+		if err != nil {
+			return err
+		}
 	}
 
 	vm := definition.Definition
 
+	if err := i.checker.Check(i.name, vm); err != nil {
+		return err
+	}
+
 	archiveFile := fmt.Sprintf("%s.tar.gz", i.plugin)
 	tmpPath := filepath.Join(i.tmpPath, i.organization, i.repo)
 	archiveFilePath := filepath.Join(tmpPath, archiveFile)
@@ -121,8 +138,9 @@ func (i Install) Execute() error {
 		fmt.Printf("No install script found for %s.\n", i.name)
 	}
 
+	binaryPath := filepath.Join(i.pluginPath, vm.ID)
 	fmt.Printf("Moving binary %s into plugin directory...\n", vm.ID)
-	if err := i.fs.Rename(filepath.Join(workingDir, vm.BinaryPath), filepath.Join(i.pluginPath, vm.ID)); err != nil {
+	if err := i.fs.Rename(filepath.Join(workingDir, vm.BinaryPath), binaryPath); err != nil {
 		return err
 	}
 
@@ -137,13 +155,17 @@ func (i Install) Execute() error {
 
 	fmt.Printf("Adding virtual machine %s to installation registry...\n", vm.ID)
 	installInfo := storage.InstallInfo{
-		ID:      vm.ID,
-		Version: vm.Version,
+		ID:         vm.ID,
+		Version:    vm.Version,
+		Commit:     definition.Commit,
+		BinaryPath: binaryPath,
+		URL:        vm.URL,
+		SHA256:     vm.SHA256,
 	}
 	if err := i.installedVMs.Put([]byte(i.name), installInfo); err != nil {
 		return err
 	}
 
-	fmt.Printf("Successfully installed %s@v%v.%v.%v in %s\n", i.name, vm.Version.Major, vm.Version.Minor, vm.Version.Patch, filepath.Join(i.pluginPath, vm.ID))
+	fmt.Printf("Successfully installed %s@v%v.%v.%v in %s\n", i.name, vm.Version.Major, vm.Version.Minor, vm.Version.Patch, binaryPath)
 	return nil
 }
@@ -0,0 +1,239 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+	"gopkg.in/yaml.v3"
+
+	"github.com/DioneProtocol/opm/config"
+	"github.com/DioneProtocol/opm/git"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+	"github.com/DioneProtocol/opm/util"
+)
+
+var (
+	ErrLockMismatch = errors.New("doesn't match the lockfile")
+
+	_ Workflow = &InstallLocked{}
+)
+
+type InstallLockedConfig struct {
+	Executor Executor
+
+	Locked     config.LockedVM
+	Repository config.LockedRepository
+	// Definitions are the synced definitions of the repository, which are
+	// installed from for sources that don't keep their history.
+	Definitions storage.Repository
+
+	SourcesList  storage.Storage[storage.SourceInfo]
+	InstalledVMs storage.Storage[storage.InstallInfo]
+
+	RepositoriesPath string
+	TmpPath          string
+	PluginPath       string
+
+	Reader    git.FileReader
+	Installer Installer
+	Checker   CompatibilityChecker
+	Fs        afero.Fs
+}
+
+func NewInstallLocked(config InstallLockedConfig) *InstallLocked {
+	return &InstallLocked{
+		executor:         config.Executor,
+		locked:           config.Locked,
+		repository:       config.Repository,
+		definitions:      config.Definitions,
+		sourcesList:      config.SourcesList,
+		installedVMs:     config.InstalledVMs,
+		repositoriesPath: config.RepositoriesPath,
+		tmpPath:          config.TmpPath,
+		pluginPath:       config.PluginPath,
+		reader:           config.Reader,
+		installer:        config.Installer,
+		checker:          config.Checker,
+		fs:               config.Fs,
+	}
+}
+
+// InstallLocked installs the exact VM recorded in a lockfile, using its
+// definition as of the locked commit.
+type InstallLocked struct {
+	executor Executor
+
+	locked      config.LockedVM
+	repository  config.LockedRepository
+	definitions storage.Repository
+
+	sourcesList  storage.Storage[storage.SourceInfo]
+	installedVMs storage.Storage[storage.InstallInfo]
+
+	repositoriesPath string
+	tmpPath          string
+	pluginPath       string
+
+	reader    git.FileReader
+	installer Installer
+	checker   CompatibilityChecker
+	fs        afero.Fs
+}
+
+func (i *InstallLocked) Execute() error {
+	repoAlias, plugin := util.ParseQualifiedName(i.locked.Name)
+	organization, repo := util.ParseAlias(repoAlias)
+
+	source, err := i.sourcesList.Get([]byte(repoAlias))
+	if err == database.ErrNotFound {
+		return fmt.Errorf("%s isn't tracked. Add it with `%s`", repoAlias, addRepositoryCommand(i.repository))
+	} else if err != nil {
+		return err
+	}
+	if locked := i.repository.SourceInfo(); source.URL != locked.URL || source.Source != locked.Source {
+		return fmt.Errorf("%s tracks %s, which %w (locked from %s)", repoAlias, config.NewRepository(source), ErrLockMismatch, i.repository)
+	}
+
+	installInfo, err := i.installedVMs.Get([]byte(i.locked.Name))
+	if err == nil && IsLocked(installInfo, i.locked) {
+		fmt.Printf("%s is already installed at %s. Skipping.\n", i.locked.Name, i.locked.Version)
+		return nil
+	} else if err != nil && err != database.ErrNotFound {
+		return err
+	}
+
+	definition, err := i.lockedDefinition(source, plugin)
+	if err != nil {
+		return err
+	}
+	if err := i.verify(definition.Definition); err != nil {
+		return err
+	}
+
+	fmt.Printf("Installing %s@%s locked at %s.\n", i.locked.Name, i.locked.Version, definition.Commit)
+	return i.executor.Execute(NewInstall(InstallConfig{
+		Name:         i.locked.Name,
+		Plugin:       plugin,
+		Organization: organization,
+		Repo:         repo,
+		TmpPath:      i.tmpPath,
+		PluginPath:   i.pluginPath,
+		InstalledVMs: i.installedVMs,
+		Fs:           i.fs,
+		Installer:    i.installer,
+		Checker:      i.checker,
+		Definition:   &definition,
+	}))
+}
+
+// lockedDefinition returns the definition of the locked VM [plugin]. Git
+// repositories keep their history, so the definition is read as of the locked
+// commit. Other sources only have their synced definitions, which are checked
+// against the lockfile all the same.
+func (i *InstallLocked) lockedDefinition(source storage.SourceInfo, plugin string) (storage.Definition[types.VM], error) {
+	if source.Source == storage.HTTPSource || source.Source == storage.LocalSource {
+		definition, err := i.definitions.VMs.Get([]byte(plugin))
+		if err == database.ErrNotFound {
+			return storage.Definition[types.VM]{}, fmt.Errorf("vm %s doesn't exist in %s. Run `opm update` to sync it", plugin, i.repository.Alias)
+		}
+		return definition, err
+	}
+
+	organization, repo := util.ParseAlias(i.repository.Alias)
+	commit := plumbing.NewHash(i.locked.Commit)
+	vm, err := definitionAt(i.reader, filepath.Join(i.repositoriesPath, organization, repo), commit, plugin)
+	if errors.Is(err, git.ErrCommitNotFound) {
+		return storage.Definition[types.VM]{}, fmt.Errorf("%w. Run `opm update` to fetch it", err)
+	} else if err != nil {
+		return storage.Definition[types.VM]{}, err
+	}
+
+	return storage.Definition[types.VM]{Definition: vm, Commit: commit}, nil
+}
+
+// addRepositoryCommand returns the command that tracks [repository] the way it
+// was locked.
+func addRepositoryCommand(repository config.LockedRepository) string {
+	sourceInfo := repository.SourceInfo()
+	command := fmt.Sprintf("opm add-repository --alias %s --url %s", sourceInfo.Alias, sourceInfo.URL)
+
+	switch sourceInfo.Source {
+	case storage.HTTPSource:
+		return fmt.Sprintf("%s --source %s --public-key %s", command, sourceInfo.Source, sourceInfo.PublicKey)
+	case storage.LocalSource:
+		return fmt.Sprintf("%s --source %s", command, sourceInfo.Source)
+	default:
+		return fmt.Sprintf("%s --%s %s", command, sourceInfo.ReferenceType, sourceInfo.Tracked())
+	}
+}
+
+// IsLocked returns true if [installInfo] is the [locked] VM. VMs installed by
+// older versions of opm don't have their artifact recorded, so they're matched
+// by the commit they were installed from instead.
+func IsLocked(installInfo storage.InstallInfo, locked config.LockedVM) bool {
+	if installInfo.Version.String() != locked.Version {
+		return false
+	}
+	if installInfo.SHA256 == "" {
+		return installInfo.Commit.String() == locked.Commit
+	}
+
+	return installInfo.SHA256 == locked.SHA256
+}
+
+// verify refuses to install [vm] if its definition differs from the lockfile.
+func (i *InstallLocked) verify(vm types.VM) error {
+	for _, field := range []struct {
+		name           string
+		locked, actual string
+	}{
+		{name: "id", locked: i.locked.ID, actual: vm.ID},
+		{name: "version", locked: i.locked.Version, actual: vm.Version.String()},
+		{name: "url", locked: i.locked.URL, actual: vm.URL},
+		{name: "sha256", locked: i.locked.SHA256, actual: vm.SHA256},
+	} {
+		if field.locked != field.actual {
+			return fmt.Errorf(
+				"the %s of %s at %s is %q, which %w (%q)",
+				field.name,
+				i.locked.Name,
+				i.locked.Commit,
+				field.actual,
+				ErrLockMismatch,
+				field.locked,
+			)
+		}
+	}
+
+	return nil
+}
+
+// definitionAt returns the definition of the VM [alias] in the repository at
+// [repositoryPath] as of [commit].
+func definitionAt(reader git.FileReader, repositoryPath string, commit plumbing.Hash, alias string) (types.VM, error) {
+	files, err := reader.ReadDir(repositoryPath, commit, vmDir)
+	if err != nil {
+		return types.VM{}, err
+	}
+
+	for _, contents := range files {
+		data := make(map[string]types.VM)
+		if err := yaml.Unmarshal(contents, data); err != nil {
+			continue
+		}
+
+		if vm, ok := data[vmKey]; ok && vm.GetAlias() == alias {
+			return vm, nil
+		}
+	}
+
+	return types.VM{}, fmt.Errorf("vm %s doesn't exist in %s at %s", alias, repositoryPath, commit)
+}
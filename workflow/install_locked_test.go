@@ -0,0 +1,265 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/DioneProtocol/opm/config"
+	"github.com/DioneProtocol/opm/git"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+const lockedCommit = "0123456789abcdef0123456789abcdef01234567"
+
+var (
+	lockedRepository = config.LockedRepository{
+		Alias:  "organization/repository",
+		URL:    "https://github.com/organization/repository.git",
+		Branch: "main",
+	}
+	trackedSource = storage.SourceInfo{URL: lockedRepository.URL, Source: storage.GitSource}
+	lockedVM      = config.LockedVM{
+		Name:    "organization/repository:foovm",
+		ID:      "id",
+		Version: "v1.2.3",
+		Commit:  lockedCommit,
+		URL:     "https://foo.com/foovm.tar.gz",
+		SHA256:  "abcd",
+	}
+	lockedDefinition = []byte(`vm:
+  id: id
+  alias: foovm
+  url: https://foo.com/foovm.tar.gz
+  sha256: abcd
+  version:
+    major: 1
+    minor: 2
+    patch: 3
+`)
+)
+
+func TestInstallLockedExecute(t *testing.T) {
+	repositoryPath := filepath.Join("repositories", "organization", "repository")
+	errWrong := fmt.Errorf("something went wrong")
+
+	type mocks struct {
+		sourcesList  storage.Storage[storage.SourceInfo]
+		installedVMs storage.Storage[storage.InstallInfo]
+		definitions  storage.Repository
+		reader       *git.MockFileReader
+		executor     *MockExecutor
+	}
+	localSource := storage.SourceInfo{URL: "/plugins", Source: storage.LocalSource}
+	localRepository := config.NewRepository(localSource)
+	localRepository.Alias = lockedRepository.Alias
+	syncedCommit := plumbing.NewHash("d4e5f6")
+
+	tests := []struct {
+		name       string
+		repository *config.LockedRepository
+		setup      func(*testing.T, mocks)
+		wantErr    assert.ErrorAssertionFunc
+	}{
+		{
+			name:  "repository not tracked",
+			setup: func(*testing.T, mocks) {},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "--url https://github.com/organization/repository.git --branch main")
+			},
+		},
+		{
+			name: "repository url changed",
+			setup: func(t *testing.T, mocks mocks) {
+				assert.NoError(t, mocks.sourcesList.Put([]byte(lockedRepository.Alias), storage.SourceInfo{URL: "https://github.com/foo/bar.git"}))
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrLockMismatch)
+			},
+		},
+		{
+			name: "repository source changed",
+			setup: func(t *testing.T, mocks mocks) {
+				assert.NoError(t, mocks.sourcesList.Put([]byte(lockedRepository.Alias), storage.SourceInfo{URL: lockedRepository.URL, Source: storage.HTTPSource}))
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrLockMismatch)
+			},
+		},
+		{
+			name:       "local repository not synced",
+			repository: &localRepository,
+			setup: func(t *testing.T, mocks mocks) {
+				assert.NoError(t, mocks.sourcesList.Put([]byte(lockedRepository.Alias), localSource))
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorContains(t, err, "Run `opm update` to sync it")
+			},
+		},
+		{
+			name:       "local definition changed",
+			repository: &localRepository,
+			setup: func(t *testing.T, mocks mocks) {
+				assert.NoError(t, mocks.sourcesList.Put([]byte(lockedRepository.Alias), localSource))
+				assert.NoError(t, mocks.definitions.VMs.Put([]byte("foovm"), storage.Definition[types.VM]{
+					Definition: types.VM{ID: "id", Alias: "foovm", URL: "https://foo.com/foovm.tar.gz", SHA256: "beef", Version: version.Semantic{Major: 1, Minor: 2, Patch: 3}},
+					Commit:     syncedCommit,
+				}))
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrLockMismatch)
+			},
+		},
+		{
+			name:       "installs synced definition of local repository",
+			repository: &localRepository,
+			setup: func(t *testing.T, mocks mocks) {
+				assert.NoError(t, mocks.sourcesList.Put([]byte(lockedRepository.Alias), localSource))
+				definition := storage.Definition[types.VM]{
+					Definition: types.VM{ID: "id", Alias: "foovm", Maintainers: []string{}, URL: "https://foo.com/foovm.tar.gz", SHA256: "abcd", Version: version.Semantic{Major: 1, Minor: 2, Patch: 3}},
+					Commit:     syncedCommit,
+				}
+				assert.NoError(t, mocks.definitions.VMs.Put([]byte("foovm"), definition))
+				mocks.executor.EXPECT().Execute(gomock.Any()).DoAndReturn(func(wf Workflow) error {
+					install, ok := wf.(*Install)
+					assert.True(t, ok)
+					assert.Equal(t, &definition, install.definition)
+					return nil
+				})
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "already installed",
+			setup: func(t *testing.T, mocks mocks) {
+				assert.NoError(t, mocks.sourcesList.Put([]byte(lockedRepository.Alias), trackedSource))
+				assert.NoError(t, mocks.installedVMs.Put([]byte(lockedVM.Name), storage.InstallInfo{
+					Version: version.Semantic{Major: 1, Minor: 2, Patch: 3},
+					SHA256:  lockedVM.SHA256,
+				}))
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "commit not fetched",
+			setup: func(t *testing.T, mocks mocks) {
+				assert.NoError(t, mocks.sourcesList.Put([]byte(lockedRepository.Alias), trackedSource))
+				mocks.reader.EXPECT().ReadDir(repositoryPath, plumbing.NewHash(lockedCommit), "vms").Return(nil, git.ErrCommitNotFound)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, git.ErrCommitNotFound)
+			},
+		},
+		{
+			name: "definition changed",
+			setup: func(t *testing.T, mocks mocks) {
+				assert.NoError(t, mocks.sourcesList.Put([]byte(lockedRepository.Alias), trackedSource))
+				mocks.reader.EXPECT().ReadDir(repositoryPath, plumbing.NewHash(lockedCommit), "vms").Return(map[string][]byte{
+					"foovm.yaml": []byte("vm:\n  id: id\n  alias: foovm\n  url: https://foo.com/foovm.tar.gz\n  sha256: beef\n  version:\n    major: 1\n    minor: 2\n    patch: 3\n"),
+				}, nil)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.ErrorIs(t, err, ErrLockMismatch)
+			},
+		},
+		{
+			name: "missing definition",
+			setup: func(t *testing.T, mocks mocks) {
+				assert.NoError(t, mocks.sourcesList.Put([]byte(lockedRepository.Alias), trackedSource))
+				mocks.reader.EXPECT().ReadDir(repositoryPath, plumbing.NewHash(lockedCommit), "vms").Return(map[string][]byte{}, nil)
+			},
+			wantErr: assert.Error,
+		},
+		{
+			name: "install fails",
+			setup: func(t *testing.T, mocks mocks) {
+				assert.NoError(t, mocks.sourcesList.Put([]byte(lockedRepository.Alias), trackedSource))
+				mocks.reader.EXPECT().ReadDir(repositoryPath, plumbing.NewHash(lockedCommit), "vms").Return(map[string][]byte{"foovm.yaml": lockedDefinition}, nil)
+				mocks.executor.EXPECT().Execute(gomock.Any()).Return(errWrong)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.Equal(t, errWrong, err)
+			},
+		},
+		{
+			name: "installs locked definition over other version",
+			setup: func(t *testing.T, mocks mocks) {
+				assert.NoError(t, mocks.sourcesList.Put([]byte(lockedRepository.Alias), trackedSource))
+				assert.NoError(t, mocks.installedVMs.Put([]byte(lockedVM.Name), storage.InstallInfo{
+					Version: version.Semantic{Major: 1, Minor: 3},
+					SHA256:  "beef",
+				}))
+				mocks.reader.EXPECT().ReadDir(repositoryPath, plumbing.NewHash(lockedCommit), "vms").Return(map[string][]byte{
+					"barvm.yaml": []byte("vm:\n  alias: barvm\n"),
+					"foovm.yaml": lockedDefinition,
+				}, nil)
+				mocks.executor.EXPECT().Execute(gomock.Any()).DoAndReturn(func(wf Workflow) error {
+					install, ok := wf.(*Install)
+					assert.True(t, ok)
+					assert.Equal(t, "foovm", install.plugin)
+					assert.Equal(t, &storage.Definition[types.VM]{
+						Definition: types.VM{
+							ID:      "id",
+							Alias:   "foovm",
+							URL:     "https://foo.com/foovm.tar.gz",
+							SHA256:  "abcd",
+							Version: version.Semantic{Major: 1, Minor: 2, Patch: 3},
+						},
+						Commit: plumbing.NewHash(lockedCommit),
+					}, install.definition)
+					return nil
+				})
+			},
+			wantErr: assert.NoError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			db := memdb.New()
+
+			mocks := mocks{
+				sourcesList:  storage.NewSourceInfo(db),
+				installedVMs: storage.NewInstalledVMs(db),
+				definitions:  storage.NewRepositoryFactory(db).GetRepository([]byte(lockedRepository.Alias)),
+				reader:       git.NewMockFileReader(ctrl),
+				executor:     NewMockExecutor(ctrl),
+			}
+			test.setup(t, mocks)
+
+			repository := lockedRepository
+			if test.repository != nil {
+				repository = *test.repository
+			}
+			wf := NewInstallLocked(InstallLockedConfig{
+				Executor:         mocks.executor,
+				Locked:           lockedVM,
+				Repository:       repository,
+				Definitions:      mocks.definitions,
+				SourcesList:      mocks.sourcesList,
+				InstalledVMs:     mocks.installedVMs,
+				RepositoriesPath: "repositories",
+				TmpPath:          "tmp",
+				PluginPath:       "plugins",
+				Reader:           mocks.reader,
+				Installer:        NewMockInstaller(ctrl),
+				Checker:          NewMockCompatibilityChecker(ctrl),
+				Fs:               afero.NewMemMapFs(),
+			})
+
+			test.wantErr(t, wf.Execute())
+		})
+	}
+}
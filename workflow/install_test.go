@@ -44,8 +44,12 @@ func TestInstallExecute(t *testing.T) {
 	}
 	vm := definition.Definition
 	expectedVMInstallInfo := storage.InstallInfo{
-		ID:      vm.ID,
-		Version: vm.Version,
+		ID:         vm.ID,
+		Version:    vm.Version,
+		Commit:     definition.Commit,
+		BinaryPath: filepath.Join("pluginPath", vm.ID),
+		URL:        vm.URL,
+		SHA256:     vm.SHA256,
 	}
 
 	noInstallScriptDefinition := storage.Definition[types.VM]{
@@ -69,8 +73,12 @@ func TestInstallExecute(t *testing.T) {
 	}
 	noInstallScriptVM := noInstallScriptDefinition.Definition
 	expectedNoInstallScriptVMInstallInfo := storage.InstallInfo{
-		ID:      noInstallScriptVM.ID,
-		Version: noInstallScriptVM.Version,
+		ID:         noInstallScriptVM.ID,
+		Version:    noInstallScriptVM.Version,
+		Commit:     noInstallScriptDefinition.Commit,
+		BinaryPath: filepath.Join("pluginPath", noInstallScriptVM.ID),
+		URL:        noInstallScriptVM.URL,
+		SHA256:     noInstallScriptVM.SHA256,
 	}
 
 	installPath := filepath.Join("tmpPath", "organization", "repo")
@@ -82,13 +90,15 @@ func TestInstallExecute(t *testing.T) {
 		installedVMs *storage.MockStorage[storage.InstallInfo]
 		vmStorage    *storage.MockStorage[storage.Definition[types.VM]]
 		installer    *MockInstaller
+		checker      *MockCompatibilityChecker
 		checksummer  *checksum.MockChecksummer
 		fs           afero.Fs
 	}
 	tests := []struct {
-		name    string
-		setup   func(mocks)
-		wantErr assert.ErrorAssertionFunc
+		name       string
+		definition *storage.Definition[types.VM]
+		setup      func(mocks)
+		wantErr    assert.ErrorAssertionFunc
 	}{
 		{
 			name: "read vm registry fails",
@@ -99,10 +109,21 @@ func TestInstallExecute(t *testing.T) {
 				return assert.Equal(t, err, errWrong)
 			},
 		},
+		{
+			name: "incompatible with node",
+			setup: func(mocks mocks) {
+				mocks.vmStorage.EXPECT().Get([]byte("plugin")).Return(definition, nil)
+				mocks.checker.EXPECT().Check("name", vm).Return(errWrong)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.Equal(t, err, errWrong)
+			},
+		},
 		{
 			name: "download fails",
 			setup: func(mocks mocks) {
 				mocks.vmStorage.EXPECT().Get([]byte("plugin")).Return(definition, nil)
+				mocks.checker.EXPECT().Check("name", vm).Return(nil)
 				mocks.installer.EXPECT().Download(vm.URL, tarPath).Return(errWrong)
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
@@ -113,6 +134,7 @@ func TestInstallExecute(t *testing.T) {
 			name: "wrong checksum",
 			setup: func(mocks mocks) {
 				mocks.vmStorage.EXPECT().Get([]byte("plugin")).Return(definition, nil)
+				mocks.checker.EXPECT().Check("name", vm).Return(nil)
 				mocks.installer.EXPECT().Download(vm.URL, tarPath).Do(func(string, string) error {
 					return afero.WriteFile(mocks.fs, tarPath, nil, perms.ReadWrite)
 				})
@@ -126,6 +148,7 @@ func TestInstallExecute(t *testing.T) {
 			name: "decompress fails",
 			setup: func(mocks mocks) {
 				mocks.vmStorage.EXPECT().Get([]byte("plugin")).Return(definition, nil)
+				mocks.checker.EXPECT().Check("name", vm).Return(nil)
 				mocks.installer.EXPECT().Download(vm.URL, tarPath).Do(func(string, string) error {
 					return afero.WriteFile(mocks.fs, tarPath, nil, perms.ReadWrite)
 				})
@@ -140,6 +163,7 @@ func TestInstallExecute(t *testing.T) {
 			name: "install fails",
 			setup: func(mocks mocks) {
 				mocks.vmStorage.EXPECT().Get([]byte("plugin")).Return(definition, nil)
+				mocks.checker.EXPECT().Check("name", vm).Return(nil)
 				mocks.installer.EXPECT().Download(vm.URL, tarPath).Do(func(string, string) error {
 					return afero.WriteFile(mocks.fs, tarPath, nil, perms.ReadWrite)
 				})
@@ -157,6 +181,7 @@ func TestInstallExecute(t *testing.T) {
 			name: "installation registry fails",
 			setup: func(mocks mocks) {
 				mocks.vmStorage.EXPECT().Get([]byte("plugin")).Return(definition, nil)
+				mocks.checker.EXPECT().Check("name", vm).Return(nil)
 				mocks.installer.EXPECT().Download(vm.URL, tarPath).Do(func(string, string) error {
 					return afero.WriteFile(mocks.fs, tarPath, nil, perms.ReadWrite)
 				})
@@ -175,6 +200,7 @@ func TestInstallExecute(t *testing.T) {
 			name: "happy case clean install",
 			setup: func(mocks mocks) {
 				mocks.vmStorage.EXPECT().Get([]byte("plugin")).Return(definition, nil)
+				mocks.checker.EXPECT().Check("name", vm).Return(nil)
 				mocks.installer.EXPECT().Download(vm.URL, tarPath).Do(func(string, string) error {
 					return afero.WriteFile(mocks.fs, tarPath, nil, perms.ReadWrite)
 				})
@@ -193,6 +219,25 @@ func TestInstallExecute(t *testing.T) {
 			name: "happy case no install script",
 			setup: func(mocks mocks) {
 				mocks.vmStorage.EXPECT().Get([]byte("plugin")).Return(noInstallScriptDefinition, nil)
+				mocks.checker.EXPECT().Check("name", noInstallScriptVM).Return(nil)
+				mocks.installer.EXPECT().Download(noInstallScriptVM.URL, tarPath).Do(func(string, string) error {
+					return afero.WriteFile(mocks.fs, tarPath, nil, perms.ReadWrite)
+				})
+				mocks.checksummer.EXPECT().Checksum(tarPath).Return(hash)
+				mocks.installer.EXPECT().Decompress(tarPath, workingDir).Do(func(string, string) error {
+					return afero.WriteFile(mocks.fs, filepath.Join(workingDir, noInstallScriptVM.BinaryPath), nil, perms.ReadWrite)
+				})
+				mocks.installedVMs.EXPECT().Put([]byte("name"), expectedNoInstallScriptVMInstallInfo).Return(nil)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.Nil(t, err)
+			},
+		},
+		{
+			name:       "happy case given definition",
+			definition: &noInstallScriptDefinition,
+			setup: func(mocks mocks) {
+				mocks.checker.EXPECT().Check("name", noInstallScriptVM).Return(nil)
 				mocks.installer.EXPECT().Download(noInstallScriptVM.URL, tarPath).Do(func(string, string) error {
 					return afero.WriteFile(mocks.fs, tarPath, nil, perms.ReadWrite)
 				})
@@ -220,6 +265,7 @@ func TestInstallExecute(t *testing.T) {
 			installedVMs = storage.NewMockStorage[storage.InstallInfo](ctrl)
 			vmStorage = storage.NewMockStorage[storage.Definition[types.VM]](ctrl)
 			installer := NewMockInstaller(ctrl)
+			checker := NewMockCompatibilityChecker(ctrl)
 			fs := afero.NewMemMapFs()
 			checksummer := checksum.NewMockChecksummer(ctrl)
 
@@ -227,6 +273,7 @@ func TestInstallExecute(t *testing.T) {
 				installedVMs: installedVMs,
 				vmStorage:    vmStorage,
 				installer:    installer,
+				checker:      checker,
 				fs:           fs,
 				checksummer:  checksummer,
 			})
@@ -243,6 +290,8 @@ func TestInstallExecute(t *testing.T) {
 					VMStorage:    vmStorage,
 					Fs:           fs,
 					Installer:    installer,
+					Checker:      checker,
+					Definition:   test.definition,
 				},
 			)
 			wf.checksummer = checksummer
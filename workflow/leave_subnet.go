@@ -0,0 +1,91 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"fmt"
+
+	"github.com/DioneProtocol/odysseygo/database"
+	"github.com/spf13/afero"
+
+	"github.com/DioneProtocol/opm/node"
+	"github.com/DioneProtocol/opm/storage"
+)
+
+var _ Workflow = &LeaveSubnet{}
+
+type LeaveSubnetConfig struct {
+	// Name is the fully qualified name of the subnet.
+	Name string
+	// SubnetID is the ID of the subnet according to its current definition.
+	// The ID the subnet was joined with takes precedence if it's known.
+	SubnetID string
+
+	NodeConfig    *node.NodeConfig
+	JoinedSubnets storage.Storage[storage.JoinInfo]
+	ConfigFiles   storage.Storage[storage.ConfigFile]
+	Fs            afero.Fs
+}
+
+func NewLeaveSubnet(config LeaveSubnetConfig) *LeaveSubnet {
+	return &LeaveSubnet{
+		name:          config.Name,
+		subnetID:      config.SubnetID,
+		nodeConfig:    config.NodeConfig,
+		joinedSubnets: config.JoinedSubnets,
+		configFiles:   config.ConfigFiles,
+		fs:            config.Fs,
+	}
+}
+
+// LeaveSubnet stops the node from tracking a subnet and removes the chain
+// configs installed for it. The subnet's VMs are left installed since other
+// subnets may depend on them.
+type LeaveSubnet struct {
+	name     string
+	subnetID string
+
+	nodeConfig    *node.NodeConfig
+	joinedSubnets storage.Storage[storage.JoinInfo]
+	configFiles   storage.Storage[storage.ConfigFile]
+	fs            afero.Fs
+}
+
+func (l *LeaveSubnet) Execute() error {
+	subnetID := l.subnetID
+
+	joinInfo, err := l.joinedSubnets.Get([]byte(l.name))
+	switch err {
+	case nil:
+		subnetID = joinInfo.ID
+	case database.ErrNotFound:
+		fmt.Printf("Subnet %s wasn't joined using the opm. Removing it anyways...\n", l.name)
+	default:
+		return err
+	}
+
+	removed, err := l.nodeConfig.UntrackSubnet(subnetID)
+	if err != nil {
+		return err
+	}
+	if removed {
+		fmt.Printf("Removed subnet %s from %s in %s.\n", subnetID, node.TrackSubnetsKey, l.nodeConfig.Path())
+	} else {
+		fmt.Printf("Subnet %s isn't tracked in %s. Nothing to remove here.\n", subnetID, l.nodeConfig.Path())
+	}
+
+	if err := removeConfigFiles(l.fs, l.configFiles, l.name, nil); err != nil {
+		return err
+	}
+
+	if err := l.joinedSubnets.Delete([]byte(l.name)); err != nil {
+		return err
+	}
+
+	if removed {
+		fmt.Printf("Restart your node for it to stop tracking subnet %s.\n", subnetID)
+	}
+	fmt.Printf("Left subnet %s. Its virtual machines are still installed and can be removed with uninstall-vm.\n", l.name)
+	return nil
+}
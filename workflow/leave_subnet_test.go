@@ -0,0 +1,95 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/node"
+	"github.com/DioneProtocol/opm/storage"
+)
+
+func TestLeaveSubnetExecute(t *testing.T) {
+	const (
+		name           = "organization/repository:spaces"
+		joinedID       = "2bRCr6B4MiEfSjidDwxDpdCyviwnfUVqB2HGwhm947w9YYqb7r"
+		definitionID   = "Vn3aX6hNRstj5VHHm63TCgPNaeGnRSqCYXQqemSqDd2TQH4qJ"
+		nodeConfigPath = "node.json"
+	)
+
+	chainConfigPath := filepath.Join("configs", "chains", "spaces", node.ChainConfigFile)
+
+	tests := []struct {
+		name           string
+		joined         bool
+		trackedSubnets string
+		wantTracked    []string
+	}{
+		{
+			name:           "joined with the opm",
+			joined:         true,
+			trackedSubnets: definitionID + "," + joinedID,
+			wantTracked:    []string{definitionID},
+		},
+		{
+			name:           "not joined with the opm",
+			trackedSubnets: definitionID + "," + joinedID,
+			wantTracked:    []string{joinedID},
+		},
+		{
+			name:           "not tracked",
+			joined:         true,
+			trackedSubnets: definitionID,
+			wantTracked:    []string{definitionID},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fs := afero.NewMemMapFs()
+			db := memdb.New()
+			joinedSubnets := storage.NewJoinedSubnets(db)
+			configFiles := storage.NewConfigFiles(db)
+
+			assert.NoError(t, afero.WriteFile(fs, nodeConfigPath, []byte(`{"track-subnets": "`+test.trackedSubnets+`"}`), perms.ReadWrite))
+			assert.NoError(t, afero.WriteFile(fs, chainConfigPath, []byte(`{}`), perms.ReadWrite))
+			assert.NoError(t, configFiles.Put([]byte(chainConfigPath), storage.ConfigFile{
+				Owner:    name,
+				Contents: `{}`,
+			}))
+			if test.joined {
+				assert.NoError(t, joinedSubnets.Put([]byte(name), storage.JoinInfo{ID: joinedID}))
+			}
+
+			nodeConfig := node.NewNodeConfig(fs, nodeConfigPath)
+			wf := NewLeaveSubnet(LeaveSubnetConfig{
+				Name:          name,
+				SubnetID:      definitionID,
+				NodeConfig:    nodeConfig,
+				JoinedSubnets: joinedSubnets,
+				ConfigFiles:   configFiles,
+				Fs:            fs,
+			})
+			assert.NoError(t, wf.Execute())
+
+			tracked, err := nodeConfig.TrackedSubnets()
+			assert.NoError(t, err)
+			assert.Equal(t, test.wantTracked, tracked)
+
+			ok, err := joinedSubnets.Has([]byte(name))
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			ok, err = afero.Exists(fs, chainConfigPath)
+			assert.NoError(t, err)
+			assert.False(t, ok)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/go-git/go-git/v5/plumbing"
+
+	"github.com/DioneProtocol/opm/storage"
+)
+
+var _ Workflow = &ListInstalled{}
+
+type ListInstalledConfig struct {
+	InstalledVMs storage.Storage[storage.InstallInfo]
+	PluginPath   string
+}
+
+func NewListInstalled(config ListInstalledConfig) *ListInstalled {
+	return &ListInstalled{
+		installedVMs: config.InstalledVMs,
+		pluginPath:   config.PluginPath,
+		out:          os.Stdout,
+	}
+}
+
+// ListInstalled lists the installed VMs, along with where they were installed
+// from and to.
+type ListInstalled struct {
+	installedVMs storage.Storage[storage.InstallInfo]
+	pluginPath   string
+
+	out io.Writer
+}
+
+func (l *ListInstalled) Execute() error {
+	itr := l.installedVMs.Iterator()
+	defer itr.Release()
+
+	w := tabwriter.NewWriter(l.out, 1, 1, 1, ' ', 0)
+	fmt.Fprintln(w, "name\tid\tversion\tcommit\tpath")
+	for itr.Next() {
+		installInfo, err := itr.Value()
+		if err != nil {
+			return err
+		}
+
+		// VMs installed by older versions of opm don't have their commit or
+		// path recorded.
+		commit := "unknown"
+		if installInfo.Commit != plumbing.ZeroHash {
+			commit = installInfo.Commit.String()
+		}
+		binaryPath := installInfo.BinaryPath
+		if binaryPath == "" {
+			binaryPath = filepath.Join(l.pluginPath, installInfo.ID)
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", itr.Key(), installInfo.ID, installInfo.Version.String(), commit, binaryPath)
+	}
+	if err := itr.Error(); err != nil {
+		return err
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,46 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"bytes"
+	"path/filepath"
+	"regexp"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/storage"
+)
+
+func TestListInstalledExecute(t *testing.T) {
+	installedVMs := storage.NewInstalledVMs(memdb.New())
+	commit := plumbing.NewHash(lockedCommit)
+
+	assert.NoError(t, installedVMs.Put([]byte("organization/repository:foovm"), storage.InstallInfo{
+		ID:         "foovm",
+		Version:    version.Semantic{Major: 1, Minor: 2, Patch: 3},
+		Commit:     commit,
+		BinaryPath: filepath.Join("node", "plugins", "foovm"),
+	}))
+	// Installed by an older version of opm.
+	assert.NoError(t, installedVMs.Put([]byte("organization/repository:barvm"), storage.InstallInfo{
+		ID:      "barvm",
+		Version: version.Semantic{Major: 1},
+	}))
+
+	wf := NewListInstalled(ListInstalledConfig{
+		InstalledVMs: installedVMs,
+		PluginPath:   "plugins",
+	})
+	out := &bytes.Buffer{}
+	wf.out = out
+
+	assert.NoError(t, wf.Execute())
+	assert.Regexp(t, `organization/repository:foovm +foovm +v1\.2\.3 +`+lockedCommit+` +`+regexp.QuoteMeta(filepath.Join("node", "plugins", "foovm")), out.String())
+	assert.Regexp(t, `organization/repository:barvm +barvm +v1\.0\.0 +unknown +`+regexp.QuoteMeta(filepath.Join("plugins", "barvm")), out.String())
+}
@@ -0,0 +1,136 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"syscall"
+
+	"github.com/DioneProtocol/odysseygo/ids"
+
+	"github.com/DioneProtocol/opm/admin"
+)
+
+var _ Workflow = &LoadVMs{}
+
+type LoadVMsConfig struct {
+	// VMs maps the fully qualified names of the VMs that were just installed to
+	// their IDs.
+	VMs map[string]string
+
+	AdminClient      admin.Client
+	AdminAPIEndpoint string
+}
+
+func NewLoadVMs(config LoadVMsConfig) *LoadVMs {
+	return &LoadVMs{
+		vms:              config.VMs,
+		adminClient:      config.AdminClient,
+		adminAPIEndpoint: config.AdminAPIEndpoint,
+	}
+}
+
+// LoadVMs asks a running node to load newly installed VMs and reports which
+// VMs the node loaded. Fails if the node couldn't load any of the installed
+// VMs.
+type LoadVMs struct {
+	vms map[string]string
+
+	adminClient      admin.Client
+	adminAPIEndpoint string
+}
+
+func (l *LoadVMs) Execute() error {
+	fmt.Printf("Updating virtual machines...\n")
+	result, err := l.adminClient.LoadVMs()
+	if errors.Is(err, syscall.ECONNREFUSED) {
+		fmt.Printf("Node at %s was offline. Virtual machines will be available upon node startup.\n", l.adminAPIEndpoint)
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	names := make(map[string]string, len(l.vms))
+	for name, id := range l.vms {
+		names[id] = name
+	}
+
+	for _, id := range sortedIDs(result.NewVMs) {
+		fmt.Printf("Node loaded virtual machine %s%s.\n", describeVM(names, id), describeAliases(result.NewVMs[id]))
+	}
+
+	failed := []string{}
+	for _, id := range sortedIDs(result.FailedVMs) {
+		reason := result.FailedVMs[id]
+		if _, ok := names[id.String()]; !ok {
+			fmt.Printf("Warning - node failed to load virtual machine %s: %s\n", id, reason)
+			continue
+		}
+
+		fmt.Printf("Node failed to load virtual machine %s: %s\n", describeVM(names, id), reason)
+		failed = append(failed, names[id.String()])
+	}
+
+	sortedNames := make([]string, 0, len(l.vms))
+	for name := range l.vms {
+		sortedNames = append(sortedNames, name)
+	}
+	sort.Strings(sortedNames)
+
+	for _, name := range sortedNames {
+		id, err := ids.FromString(l.vms[name])
+		if err != nil {
+			// The node can only load VMs with valid IDs, so there's nothing
+			// else to report here.
+			fmt.Printf("Warning - virtual machine %s has an invalid ID %s: %s\n", name, l.vms[name], err)
+			continue
+		}
+
+		_, loaded := result.NewVMs[id]
+		_, failedToLoad := result.FailedVMs[id]
+		if !loaded && !failedToLoad {
+			fmt.Printf("Virtual machine %s was already loaded by the node. Restart the node to run the newly installed binary.\n", describeVM(names, id))
+		}
+	}
+
+	if len(failed) > 0 {
+		sort.Strings(failed)
+		return fmt.Errorf("node failed to load virtual machines: %s", strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
+// describeVM returns the name of the VM with [id] if it's known, along with
+// its ID.
+func describeVM(names map[string]string, id ids.ID) string {
+	if name, ok := names[id.String()]; ok {
+		return fmt.Sprintf("%s (%s)", name, id)
+	}
+
+	return id.String()
+}
+
+func describeAliases(aliases []string) string {
+	if len(aliases) == 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" with aliases %s", strings.Join(aliases, ", "))
+}
+
+func sortedIDs[V any](m map[ids.ID]V) []ids.ID {
+	result := make([]ids.ID, 0, len(m))
+	for id := range m {
+		result = append(result, id)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].String() < result[j].String()
+	})
+
+	return result
+}
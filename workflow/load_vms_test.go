@@ -0,0 +1,116 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/ids"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/admin"
+)
+
+func TestLoadVMsExecute(t *testing.T) {
+	var (
+		fooID   = ids.GenerateTestID()
+		barID   = ids.GenerateTestID()
+		otherID = ids.GenerateTestID()
+
+		vms = map[string]string{
+			"organization/repository:foovm": fooID.String(),
+			"organization/repository:barvm": barID.String(),
+		}
+
+		errFoo = errors.New("foo")
+	)
+
+	tests := []struct {
+		name    string
+		result  admin.LoadVMsResult
+		err     error
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name: "all loaded",
+			result: admin.LoadVMsResult{
+				NewVMs: map[ids.ID][]string{
+					fooID: {"foovm"},
+					barID: nil,
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "already loaded",
+			result: admin.LoadVMsResult{
+				NewVMs: map[ids.ID][]string{
+					fooID: {"foovm"},
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "unrelated VM failed",
+			result: admin.LoadVMsResult{
+				NewVMs: map[ids.ID][]string{
+					fooID: {"foovm"},
+					barID: {"barvm"},
+				},
+				FailedVMs: map[ids.ID]string{
+					otherID: "bad binary",
+				},
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "installed VM failed",
+			result: admin.LoadVMsResult{
+				NewVMs: map[ids.ID][]string{
+					fooID: {"foovm"},
+				},
+				FailedVMs: map[ids.ID]string{
+					barID: "bad binary",
+				},
+			},
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorContains(t, err, "organization/repository:barvm")
+			},
+		},
+		{
+			name:    "node offline",
+			err:     fmt.Errorf("failed to issue request: %w", syscall.ECONNREFUSED),
+			wantErr: assert.NoError,
+		},
+		{
+			name: "request failed",
+			err:  errFoo,
+			wantErr: func(t assert.TestingT, err error, _ ...interface{}) bool {
+				return assert.ErrorIs(t, err, errFoo)
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			adminClient := admin.NewMockClient(ctrl)
+			adminClient.EXPECT().LoadVMs().Return(test.result, test.err)
+
+			wf := NewLoadVMs(LoadVMsConfig{
+				VMs:              vms,
+				AdminClient:      adminClient,
+				AdminAPIEndpoint: "127.0.0.1:9650/ext/admin",
+			})
+
+			test.wantErr(t, wf.Execute())
+		})
+	}
+}
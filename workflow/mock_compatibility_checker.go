@@ -0,0 +1,52 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+// Code generated by MockGen. DO NOT EDIT.
+// Source: workflow/compatibility.go
+
+// Package workflow is a generated GoMock package.
+package workflow
+
+import (
+	reflect "reflect"
+
+	types "github.com/DioneProtocol/opm/types"
+	gomock "go.uber.org/mock/gomock"
+)
+
+// MockCompatibilityChecker is a mock of CompatibilityChecker interface.
+type MockCompatibilityChecker struct {
+	ctrl     *gomock.Controller
+	recorder *MockCompatibilityCheckerMockRecorder
+}
+
+// MockCompatibilityCheckerMockRecorder is the mock recorder for MockCompatibilityChecker.
+type MockCompatibilityCheckerMockRecorder struct {
+	mock *MockCompatibilityChecker
+}
+
+// NewMockCompatibilityChecker creates a new mock instance.
+func NewMockCompatibilityChecker(ctrl *gomock.Controller) *MockCompatibilityChecker {
+	mock := &MockCompatibilityChecker{ctrl: ctrl}
+	mock.recorder = &MockCompatibilityCheckerMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCompatibilityChecker) EXPECT() *MockCompatibilityCheckerMockRecorder {
+	return m.recorder
+}
+
+// Check mocks base method.
+func (m *MockCompatibilityChecker) Check(name string, vm types.VM) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Check", name, vm)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Check indicates an expected call of Check.
+func (mr *MockCompatibilityCheckerMockRecorder) Check(name, vm interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Check", reflect.TypeOf((*MockCompatibilityChecker)(nil).Check), name, vm)
+}
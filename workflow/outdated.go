@@ -0,0 +1,87 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/DioneProtocol/odysseygo/database"
+
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/util"
+)
+
+var _ Workflow = &Outdated{}
+
+type OutdatedConfig struct {
+	InstalledVMs storage.Storage[storage.InstallInfo]
+	RepoFactory  storage.RepositoryFactory
+}
+
+func NewOutdated(config OutdatedConfig) *Outdated {
+	return &Outdated{
+		installedVMs: config.InstalledVMs,
+		repoFactory:  config.RepoFactory,
+		out:          os.Stdout,
+	}
+}
+
+// Outdated lists the installed VMs that have a newer synced version, or whose
+// definitions have disappeared.
+type Outdated struct {
+	installedVMs storage.Storage[storage.InstallInfo]
+	repoFactory  storage.RepositoryFactory
+
+	out io.Writer
+}
+
+func (o *Outdated) Execute() error {
+	itr := o.installedVMs.Iterator()
+	defer itr.Release()
+
+	outdated := 0
+
+	w := tabwriter.NewWriter(o.out, 1, 1, 1, ' ', 0)
+	fmt.Fprintln(w, "name\tinstalled\tlatest\tstatus")
+	for itr.Next() {
+		name := string(itr.Key())
+		installInfo, err := itr.Value()
+		if err != nil {
+			return err
+		}
+
+		repoAlias, vmName := util.ParseQualifiedName(name)
+		repository := o.repoFactory.GetRepository([]byte(repoAlias))
+
+		definition, err := repository.VMs.Get([]byte(vmName))
+		if err == database.ErrNotFound {
+			outdated++
+			fmt.Fprintf(w, "%s\t%s\t-\tdefinition removed (uninstall recommended)\n", name, installInfo.Version.String())
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		latest := definition.Definition.Version
+		if installInfo.Version.Compare(&latest) >= 0 {
+			continue
+		}
+
+		outdated++
+		fmt.Fprintf(w, "%s\t%s\t%s\tupgrade available\n", name, installInfo.Version.String(), latest.String())
+	}
+	if err := itr.Error(); err != nil {
+		return err
+	}
+
+	if outdated == 0 {
+		fmt.Fprintln(o.out, "All installed virtual machines are up-to-date.")
+		return nil
+	}
+
+	return w.Flush()
+}
@@ -0,0 +1,79 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestOutdatedExecute(t *testing.T) {
+	const alias = "organization/repository"
+
+	tests := []struct {
+		name      string
+		installed version.Semantic
+		synced    *version.Semantic
+		want      []string
+		wantNot   []string
+	}{
+		{
+			name:      "up-to-date",
+			installed: version.Semantic{Major: 1, Minor: 2},
+			synced:    &version.Semantic{Major: 1, Minor: 2},
+			want:      []string{"All installed virtual machines are up-to-date."},
+			wantNot:   []string{alias + ":foovm"},
+		},
+		{
+			name:      "upgrade available",
+			installed: version.Semantic{Major: 1, Minor: 2},
+			synced:    &version.Semantic{Major: 1, Minor: 3},
+			want:      []string{alias + ":foovm", "v1.2.0", "v1.3.0", "upgrade available"},
+			wantNot:   []string{"up-to-date"},
+		},
+		{
+			name:      "definition removed",
+			installed: version.Semantic{Major: 1, Minor: 2},
+			want:      []string{alias + ":foovm", "v1.2.0", "definition removed (uninstall recommended)"},
+			wantNot:   []string{"up-to-date"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := memdb.New()
+			installedVMs := storage.NewInstalledVMs(db)
+			repoFactory := storage.NewRepositoryFactory(db)
+
+			assert.NoError(t, installedVMs.Put([]byte(alias+":foovm"), storage.InstallInfo{ID: "foovm", Version: test.installed}))
+			if test.synced != nil {
+				assert.NoError(t, repoFactory.GetRepository([]byte(alias)).VMs.Put([]byte("foovm"), storage.Definition[types.VM]{
+					Definition: types.VM{ID: "foovm", Alias: "foovm", Version: *test.synced},
+				}))
+			}
+
+			wf := NewOutdated(OutdatedConfig{
+				InstalledVMs: installedVMs,
+				RepoFactory:  repoFactory,
+			})
+			out := &bytes.Buffer{}
+			wf.out = out
+
+			assert.NoError(t, wf.Execute())
+			for _, want := range test.want {
+				assert.Contains(t, out.String(), want)
+			}
+			for _, wantNot := range test.wantNot {
+				assert.NotContains(t, out.String(), wantNot)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/DioneProtocol/opm/storage"
+)
+
+var (
+	ErrInconsistentRegistry = errors.New("registry is inconsistent with the stored definitions")
+
+	_ Workflow = &Reindex{}
+)
+
+type ReindexConfig struct {
+	// Check only reports inconsistencies, without rebuilding the registry.
+	Check bool
+
+	SourcesList storage.Storage[storage.SourceInfo]
+	Registry    storage.Registry
+	RepoFactory storage.RepositoryFactory
+}
+
+func NewReindex(config ReindexConfig) *Reindex {
+	return &Reindex{
+		check:       config.Check,
+		sourcesList: config.SourcesList,
+		registry:    config.Registry,
+		repoFactory: config.RepoFactory,
+	}
+}
+
+// Reindex rebuilds the registry from the definitions stored for every tracked
+// repository.
+type Reindex struct {
+	check bool
+
+	sourcesList storage.Storage[storage.SourceInfo]
+	registry    storage.Registry
+	repoFactory storage.RepositoryFactory
+}
+
+func (r *Reindex) Execute() error {
+	expectedVMs, expectedSubnets, err := r.index()
+	if err != nil {
+		return err
+	}
+
+	vmRegistryBatch := r.registry.VMs.NewBatch()
+	vmInconsistencies, err := reindex(vmKey, r.registry.VMs, vmRegistryBatch, expectedVMs)
+	if err != nil {
+		return err
+	}
+	subnetRegistryBatch := r.registry.Subnets.NewBatch()
+	subnetInconsistencies, err := reindex(subnetKey, r.registry.Subnets, subnetRegistryBatch, expectedSubnets)
+	if err != nil {
+		return err
+	}
+
+	inconsistencies := vmInconsistencies + subnetInconsistencies
+	if inconsistencies == 0 {
+		fmt.Printf("Registry is consistent (%d vms, %d subnets).\n", len(expectedVMs), len(expectedSubnets))
+		return nil
+	}
+
+	if r.check {
+		return fmt.Errorf("%w: found %d inconsistent aliases. Run `opm reindex` to rebuild it", ErrInconsistentRegistry, inconsistencies)
+	}
+
+	if err := storage.WriteAll(vmRegistryBatch, subnetRegistryBatch); err != nil {
+		return err
+	}
+
+	fmt.Printf("Rebuilt registry. Fixed %d inconsistent aliases.\n", inconsistencies)
+	return nil
+}
+
+// index returns the sorted repositories that define each VM and subnet alias.
+func (r *Reindex) index() (map[string][]string, map[string][]string, error) {
+	vms := map[string][]string{}
+	subnets := map[string][]string{}
+
+	itr := r.sourcesList.Iterator()
+	defer itr.Release()
+
+	// Repositories are iterated in order, so each list is built sorted.
+	for itr.Next() {
+		repositoryAlias := string(itr.Key())
+		repository := r.repoFactory.GetRepository(itr.Key())
+
+		if err := index(repository.VMs.Iterator(), repositoryAlias, vms); err != nil {
+			return nil, nil, err
+		}
+		if err := index(repository.Subnets.Iterator(), repositoryAlias, subnets); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return vms, subnets, itr.Error()
+}
+
+// index adds [repositoryAlias] to the repositories of every key in [itr].
+func index[V any](itr storage.Iterator[V], repositoryAlias string, aliases map[string][]string) error {
+	defer itr.Release()
+
+	for itr.Next() {
+		alias := string(itr.Key())
+		aliases[alias] = append(aliases[alias], repositoryAlias)
+	}
+
+	return itr.Error()
+}
+
+// reindex adds the writes that make [registry] match [expected] to [batch].
+// The number of inconsistent aliases is returned.
+func reindex(
+	kind string,
+	registry storage.Storage[storage.RepoList],
+	batch *storage.Batch[storage.RepoList],
+	expected map[string][]string,
+) (int, error) {
+	actual, err := registered(registry)
+	if err != nil {
+		return 0, err
+	}
+
+	inconsistencies := 0
+	for _, alias := range sortedUnion(expected, actual) {
+		want, registered := expected[alias], actual[alias]
+		if strings.Join(want, ",") == strings.Join(registered, ",") {
+			continue
+		}
+
+		inconsistencies++
+		switch {
+		case len(want) == 0:
+			fmt.Printf("%s %s is registered to %s, but isn't defined by any tracked repository.\n", kind, alias, strings.Join(registered, ", "))
+			err = batch.Delete([]byte(alias))
+		case len(registered) == 0:
+			fmt.Printf("%s %s is defined by %s, but isn't registered.\n", kind, alias, strings.Join(want, ", "))
+			err = batch.Put([]byte(alias), storage.RepoList{Repositories: want})
+		default:
+			fmt.Printf("%s %s is registered to %s, but is defined by %s.\n", kind, alias, strings.Join(registered, ", "), strings.Join(want, ", "))
+			err = batch.Put([]byte(alias), storage.RepoList{Repositories: want})
+		}
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return inconsistencies, nil
+}
+
+// registered returns the repositories registered for each alias in
+// [registry].
+func registered(registry storage.Storage[storage.RepoList]) (map[string][]string, error) {
+	registered := map[string][]string{}
+
+	itr := registry.Iterator()
+	defer itr.Release()
+
+	for itr.Next() {
+		repoList, err := itr.Value()
+		if err != nil {
+			return nil, err
+		}
+		registered[string(itr.Key())] = repoList.Repositories
+	}
+
+	return registered, itr.Error()
+}
+
+func sortedUnion(a map[string][]string, b map[string][]string) []string {
+	keys := make([]string, 0, len(a)+len(b))
+	for key := range a {
+		keys = append(keys, key)
+	}
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,152 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestReindexExecute(t *testing.T) {
+	const (
+		foo = "organization/foo"
+		bar = "organization/bar"
+	)
+
+	type stores struct {
+		sourcesList storage.Storage[storage.SourceInfo]
+		registry    storage.Registry
+		repoFactory storage.RepositoryFactory
+	}
+
+	// registries holds the contents of the VM and subnet registries.
+	type registries struct {
+		vms     map[string]storage.RepoList
+		subnets map[string]storage.RepoList
+	}
+
+	// define tracks [foo] and [bar], which both define foovm. Only [foo]
+	// defines the spaces subnet, and only [bar] defines the spaces VM.
+	define := func(t *testing.T, s stores) {
+		assert.NoError(t, s.sourcesList.Put([]byte(foo), storage.SourceInfo{Alias: foo}))
+		assert.NoError(t, s.sourcesList.Put([]byte(bar), storage.SourceInfo{Alias: bar}))
+
+		fooRepository := s.repoFactory.GetRepository([]byte(foo))
+		assert.NoError(t, fooRepository.VMs.Put([]byte("foovm"), storage.Definition[types.VM]{}))
+		assert.NoError(t, fooRepository.Subnets.Put([]byte("spaces"), storage.Definition[types.Subnet]{}))
+
+		barRepository := s.repoFactory.GetRepository([]byte(bar))
+		assert.NoError(t, barRepository.VMs.Put([]byte("foovm"), storage.Definition[types.VM]{}))
+		assert.NoError(t, barRepository.VMs.Put([]byte("spaces"), storage.Definition[types.VM]{}))
+
+		// Definitions of repositories that aren't tracked are ignored.
+		untracked := s.repoFactory.GetRepository([]byte("organization/untracked"))
+		assert.NoError(t, untracked.VMs.Put([]byte("untrackedvm"), storage.Definition[types.VM]{}))
+	}
+
+	consistent := registries{
+		vms: map[string]storage.RepoList{
+			"foovm":  {Repositories: []string{bar, foo}},
+			"spaces": {Repositories: []string{bar}},
+		},
+		subnets: map[string]storage.RepoList{
+			"spaces": {Repositories: []string{foo}},
+		},
+	}
+
+	tests := []struct {
+		name         string
+		check        bool
+		registry     registries
+		wantRegistry registries
+		wantErr      error
+	}{
+		{
+			name:         "consistent",
+			registry:     consistent,
+			wantRegistry: consistent,
+		},
+		{
+			name: "rebuilt",
+			registry: registries{
+				vms: map[string]storage.RepoList{
+					"foovm":       {Repositories: []string{foo}},
+					"removedvm":   {Repositories: []string{foo}},
+					"spaces":      {Repositories: []string{bar, foo}},
+					"untrackedvm": {Repositories: []string{"organization/untracked"}},
+				},
+				subnets: map[string]storage.RepoList{},
+			},
+			wantRegistry: consistent,
+		},
+		{
+			name:  "inconsistencies are only reported when checking",
+			check: true,
+			registry: registries{
+				vms: map[string]storage.RepoList{
+					"foovm":     {Repositories: []string{foo}},
+					"removedvm": {Repositories: []string{foo}},
+				},
+				subnets: map[string]storage.RepoList{},
+			},
+			wantRegistry: registries{
+				vms: map[string]storage.RepoList{
+					"foovm":     {Repositories: []string{foo}},
+					"removedvm": {Repositories: []string{foo}},
+				},
+				subnets: map[string]storage.RepoList{},
+			},
+			wantErr: ErrInconsistentRegistry,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			db := memdb.New()
+			s := stores{
+				sourcesList: storage.NewSourceInfo(db),
+				registry:    storage.NewRegistry(db),
+				repoFactory: storage.NewRepositoryFactory(db),
+			}
+			define(t, s)
+			for alias, repoList := range test.registry.vms {
+				assert.NoError(t, s.registry.VMs.Put([]byte(alias), repoList))
+			}
+			for alias, repoList := range test.registry.subnets {
+				assert.NoError(t, s.registry.Subnets.Put([]byte(alias), repoList))
+			}
+
+			err := NewReindex(ReindexConfig{
+				Check:       test.check,
+				SourcesList: s.sourcesList,
+				Registry:    s.registry,
+				RepoFactory: s.repoFactory,
+			}).Execute()
+			assert.ErrorIs(t, err, test.wantErr)
+
+			assert.Equal(t, test.wantRegistry.vms, contents(t, s.registry.VMs))
+			assert.Equal(t, test.wantRegistry.subnets, contents(t, s.registry.Subnets))
+		})
+	}
+}
+
+func contents(t *testing.T, registry storage.Storage[storage.RepoList]) map[string]storage.RepoList {
+	itr := registry.Iterator()
+	defer itr.Release()
+
+	entries := map[string]storage.RepoList{}
+	for itr.Next() {
+		repoList, err := itr.Value()
+		assert.NoError(t, err)
+		entries[string(itr.Key())] = repoList
+	}
+	assert.NoError(t, itr.Error())
+
+	return entries
+}
@@ -0,0 +1,223 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/afero"
+
+	"github.com/DioneProtocol/opm/constant"
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/util"
+)
+
+var _ Workflow = &RemoveRepository{}
+
+type RemoveRepositoryConfig struct {
+	Executor Executor
+
+	Alias string
+	// Purge uninstalls the VMs installed from the repository. Otherwise,
+	// they're left installed and listed.
+	Purge bool
+
+	SourcesList   storage.Storage[storage.SourceInfo]
+	Registry      storage.Registry
+	Repository    storage.Repository
+	InstalledVMs  storage.Storage[storage.InstallInfo]
+	JoinedSubnets storage.Storage[storage.JoinInfo]
+	ConfigFiles   storage.Storage[storage.ConfigFile]
+
+	RepositoriesPath string
+	PluginPath       string
+	Fs               afero.Fs
+}
+
+func NewRemoveRepository(config RemoveRepositoryConfig) *RemoveRepository {
+	return &RemoveRepository{
+		executor:         config.Executor,
+		alias:            config.Alias,
+		purge:            config.Purge,
+		sourcesList:      config.SourcesList,
+		registry:         config.Registry,
+		repository:       config.Repository,
+		installedVMs:     config.InstalledVMs,
+		joinedSubnets:    config.JoinedSubnets,
+		configFiles:      config.ConfigFiles,
+		repositoriesPath: config.RepositoriesPath,
+		pluginPath:       config.PluginPath,
+		fs:               config.Fs,
+	}
+}
+
+// RemoveRepository stops tracking a repository. Its definitions and registry
+// entries are removed, and its local clone is deleted.
+type RemoveRepository struct {
+	executor Executor
+
+	alias string
+	purge bool
+
+	sourcesList   storage.Storage[storage.SourceInfo]
+	registry      storage.Registry
+	repository    storage.Repository
+	installedVMs  storage.Storage[storage.InstallInfo]
+	joinedSubnets storage.Storage[storage.JoinInfo]
+	configFiles   storage.Storage[storage.ConfigFile]
+
+	repositoriesPath string
+	pluginPath       string
+	fs               afero.Fs
+}
+
+func (r *RemoveRepository) Execute() error {
+	aliasBytes := []byte(r.alias)
+
+	ok, err := r.sourcesList.Has(aliasBytes)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%s isn't a tracked repository", r.alias)
+	}
+
+	installed, err := fromRepository(r.installedVMs.Iterator(), r.alias)
+	if err != nil {
+		return err
+	}
+	joined, err := fromRepository(r.joinedSubnets.Iterator(), r.alias)
+	if err != nil {
+		return err
+	}
+
+	// VMs are uninstalled while their definitions are still around, since
+	// they're needed to find the binaries of VMs installed by older versions
+	// of opm.
+	if r.purge {
+		for _, name := range installed {
+			_, plugin := util.ParseQualifiedName(name)
+			if err := r.executor.Execute(NewUninstall(UninstallConfig{
+				Name:         name,
+				Plugin:       plugin,
+				RepoAlias:    r.alias,
+				VMStorage:    r.repository.VMs,
+				InstalledVMs: r.installedVMs,
+				ConfigFiles:  r.configFiles,
+				Fs:           r.fs,
+				PluginPath:   r.pluginPath,
+			})); err != nil {
+				return fmt.Errorf("failed to uninstall %s: %w", name, err)
+			}
+		}
+	} else if len(installed) > 0 {
+		fmt.Printf("Warning - these virtual machines were installed from %s and will be left installed:\n", r.alias)
+		for _, name := range installed {
+			fmt.Printf("  %s\n", name)
+		}
+		fmt.Println("Run remove-repository with --purge to uninstall them too, or uninstall them with uninstall-vm.")
+	}
+
+	if len(joined) > 0 {
+		fmt.Printf("Warning - these subnets were joined from %s and will stay joined:\n", r.alias)
+		for _, name := range joined {
+			fmt.Printf("  %s\n", name)
+		}
+		fmt.Println("Leave them with leave-subnet.")
+	}
+
+	// The definitions, registry entries and the repository are removed
+	// atomically, so an interrupted removal doesn't leave anything behind for
+	// a repository that's no longer tracked.
+	vmRegistryBatch := r.registry.VMs.NewBatch()
+	subnetRegistryBatch := r.registry.Subnets.NewBatch()
+	vmsBatch := r.repository.VMs.NewBatch()
+	subnetsBatch := r.repository.Subnets.NewBatch()
+	sourcesBatch := r.sourcesList.NewBatch()
+
+	if err := r.removeFromRegistry(r.registry.VMs, vmRegistryBatch); err != nil {
+		return err
+	}
+	if err := r.removeFromRegistry(r.registry.Subnets, subnetRegistryBatch); err != nil {
+		return err
+	}
+	if err := deleteAll(r.repository.VMs.Iterator(), vmsBatch); err != nil {
+		return err
+	}
+	if err := deleteAll(r.repository.Subnets.Iterator(), subnetsBatch); err != nil {
+		return err
+	}
+	if err := sourcesBatch.Delete(aliasBytes); err != nil {
+		return err
+	}
+
+	if err := storage.WriteAll(vmRegistryBatch, subnetRegistryBatch, vmsBatch, subnetsBatch, sourcesBatch); err != nil {
+		return err
+	}
+
+	organization, repo := util.ParseAlias(r.alias)
+	clonePath := filepath.Join(r.repositoriesPath, organization, repo)
+	fmt.Printf("Deleting %s...\n", clonePath)
+	if err := r.fs.RemoveAll(clonePath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Removed repository %s.\n", r.alias)
+	return nil
+}
+
+// fromRepository returns the keys in [itr] that are fully qualified names from
+// the repository [alias].
+func fromRepository[V any](itr storage.Iterator[V], alias string) ([]string, error) {
+	defer itr.Release()
+
+	prefix := alias + constant.QualifiedNameDelimiter
+	names := []string{}
+	for itr.Next() {
+		if name := string(itr.Key()); strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+
+	return names, itr.Error()
+}
+
+// removeFromRegistry adds the removal of the repository from every alias in
+// [registry] to [batch]. Aliases only provided by the repository are removed
+// from the registry.
+func (r *RemoveRepository) removeFromRegistry(
+	registry storage.Storage[storage.RepoList],
+	batch *storage.Batch[storage.RepoList],
+) error {
+	itr := registry.Iterator()
+	defer itr.Release()
+
+	for itr.Next() {
+		repoList, err := itr.Value()
+		if err != nil {
+			return err
+		}
+
+		if err := unregisterFrom(batch, itr.Key(), repoList, r.alias); err != nil {
+			return err
+		}
+	}
+
+	return itr.Error()
+}
+
+// deleteAll adds every key in [itr] to [batch] for deletion.
+func deleteAll[V any](itr storage.Iterator[V], batch *storage.Batch[V]) error {
+	defer itr.Release()
+
+	for itr.Next() {
+		if err := batch.Delete(itr.Key()); err != nil {
+			return err
+		}
+	}
+
+	return itr.Error()
+}
@@ -0,0 +1,225 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/DioneProtocol/odysseygo/utils/perms"
+	"github.com/spf13/afero"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestRemoveRepositoryExecute(t *testing.T) {
+	const (
+		alias            = "organization/repository"
+		otherAlias       = "organization/other"
+		repositoriesPath = "repositories"
+		pluginPath       = "plugins"
+	)
+
+	var (
+		clonePath      = filepath.Join(repositoriesPath, "organization", "repository")
+		otherClonePath = filepath.Join(repositoriesPath, "organization", "other")
+	)
+
+	type stores struct {
+		sourcesList   storage.Storage[storage.SourceInfo]
+		registry      storage.Registry
+		repository    storage.Repository
+		other         storage.Repository
+		installedVMs  storage.Storage[storage.InstallInfo]
+		joinedSubnets storage.Storage[storage.JoinInfo]
+		configFiles   storage.Storage[storage.ConfigFile]
+	}
+
+	// setup tracks two repositories that both provide foovm, and a VM and a
+	// subnet only provided by the one that's removed.
+	setup := func(t *testing.T, fs afero.Fs, s stores) {
+		assert.NoError(t, s.sourcesList.Put([]byte(alias), storage.SourceInfo{Alias: alias}))
+		assert.NoError(t, s.sourcesList.Put([]byte(otherAlias), storage.SourceInfo{Alias: otherAlias}))
+
+		assert.NoError(t, s.registry.VMs.Put([]byte("foovm"), storage.RepoList{Repositories: []string{otherAlias, alias}}))
+		assert.NoError(t, s.registry.VMs.Put([]byte("barvm"), storage.RepoList{Repositories: []string{alias}}))
+		assert.NoError(t, s.registry.Subnets.Put([]byte("spaces"), storage.RepoList{Repositories: []string{alias}}))
+
+		assert.NoError(t, s.repository.VMs.Put([]byte("foovm"), storage.Definition[types.VM]{}))
+		assert.NoError(t, s.repository.VMs.Put([]byte("barvm"), storage.Definition[types.VM]{}))
+		assert.NoError(t, s.repository.Subnets.Put([]byte("spaces"), storage.Definition[types.Subnet]{}))
+		assert.NoError(t, s.other.VMs.Put([]byte("foovm"), storage.Definition[types.VM]{}))
+
+		assert.NoError(t, s.installedVMs.Put([]byte(alias+":barvm"), storage.InstallInfo{ID: "barvm"}))
+		assert.NoError(t, s.installedVMs.Put([]byte(otherAlias+":foovm"), storage.InstallInfo{ID: "foovm"}))
+
+		assert.NoError(t, fs.MkdirAll(filepath.Join(clonePath, "vms"), perms.ReadWriteExecute))
+		assert.NoError(t, fs.MkdirAll(otherClonePath, perms.ReadWriteExecute))
+	}
+
+	tests := []struct {
+		name    string
+		alias   string
+		purge   bool
+		setup   func(*testing.T, afero.Fs, stores)
+		expect  func(*MockExecutor, afero.Fs, stores)
+		check   func(*testing.T, afero.Fs, stores)
+		wantErr assert.ErrorAssertionFunc
+	}{
+		{
+			name:    "not tracked",
+			alias:   alias,
+			setup:   func(*testing.T, afero.Fs, stores) {},
+			expect:  func(*MockExecutor, afero.Fs, stores) {},
+			check:   func(*testing.T, afero.Fs, stores) {},
+			wantErr: assert.Error,
+		},
+		{
+			name:   "installed vms are left installed",
+			alias:  alias,
+			setup:  setup,
+			expect: func(*MockExecutor, afero.Fs, stores) {},
+			check: func(t *testing.T, fs afero.Fs, s stores) {
+				ok, err := s.installedVMs.Has([]byte(alias + ":barvm"))
+				assert.NoError(t, err)
+				assert.True(t, ok)
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "vms left installed can be uninstalled",
+			alias: alias,
+			setup: func(t *testing.T, fs afero.Fs, s stores) {
+				setup(t, fs, s)
+				assert.NoError(t, afero.WriteFile(fs, filepath.Join(pluginPath, "barvm"), []byte("barvm"), perms.ReadWrite))
+				assert.NoError(t, afero.WriteFile(fs, filepath.Join(pluginPath, "foovm"), []byte("foovm"), perms.ReadWrite))
+			},
+			expect: func(*MockExecutor, afero.Fs, stores) {},
+			check: func(t *testing.T, fs afero.Fs, s stores) {
+				assert.NoError(t, NewUninstall(UninstallConfig{
+					Name:         alias + ":barvm",
+					Plugin:       "barvm",
+					RepoAlias:    alias,
+					VMStorage:    s.repository.VMs,
+					InstalledVMs: s.installedVMs,
+					ConfigFiles:  s.configFiles,
+					Fs:           fs,
+					PluginPath:   pluginPath,
+				}).Execute())
+
+				ok, err := s.installedVMs.Has([]byte(alias + ":barvm"))
+				assert.NoError(t, err)
+				assert.False(t, ok)
+				exists, err := afero.Exists(fs, filepath.Join(pluginPath, "barvm"))
+				assert.NoError(t, err)
+				assert.False(t, exists)
+				exists, err = afero.Exists(fs, filepath.Join(pluginPath, "foovm"))
+				assert.NoError(t, err)
+				assert.True(t, exists)
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name:  "installed vms are purged",
+			alias: alias,
+			purge: true,
+			setup: setup,
+			expect: func(executor *MockExecutor, fs afero.Fs, s stores) {
+				executor.EXPECT().Execute(NewUninstall(UninstallConfig{
+					Name:         alias + ":barvm",
+					Plugin:       "barvm",
+					RepoAlias:    alias,
+					VMStorage:    s.repository.VMs,
+					InstalledVMs: s.installedVMs,
+					ConfigFiles:  s.configFiles,
+					Fs:           fs,
+					PluginPath:   pluginPath,
+				})).Return(nil)
+			},
+			check:   func(*testing.T, afero.Fs, stores) {},
+			wantErr: assert.NoError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			executor := NewMockExecutor(ctrl)
+			db := memdb.New()
+			repoFactory := storage.NewRepositoryFactory(db)
+			s := stores{
+				sourcesList:   storage.NewSourceInfo(db),
+				registry:      storage.NewRegistry(db),
+				repository:    repoFactory.GetRepository([]byte(alias)),
+				other:         repoFactory.GetRepository([]byte(otherAlias)),
+				installedVMs:  storage.NewInstalledVMs(db),
+				joinedSubnets: storage.NewJoinedSubnets(db),
+				configFiles:   storage.NewConfigFiles(db),
+			}
+			fs := afero.NewMemMapFs()
+			test.setup(t, fs, s)
+			test.expect(executor, fs, s)
+
+			err := NewRemoveRepository(RemoveRepositoryConfig{
+				Executor:         executor,
+				Alias:            test.alias,
+				Purge:            test.purge,
+				SourcesList:      s.sourcesList,
+				Registry:         s.registry,
+				Repository:       s.repository,
+				InstalledVMs:     s.installedVMs,
+				JoinedSubnets:    s.joinedSubnets,
+				ConfigFiles:      s.configFiles,
+				RepositoriesPath: repositoriesPath,
+				PluginPath:       pluginPath,
+				Fs:               fs,
+			}).Execute()
+			test.wantErr(t, err)
+			test.check(t, fs, s)
+			if err != nil {
+				return
+			}
+
+			ok, err := s.sourcesList.Has([]byte(alias))
+			assert.NoError(t, err)
+			assert.False(t, ok)
+			ok, err = s.sourcesList.Has([]byte(otherAlias))
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			repoList, err := s.registry.VMs.Get([]byte("foovm"))
+			assert.NoError(t, err)
+			assert.Equal(t, storage.RepoList{Repositories: []string{otherAlias}}, repoList)
+			ok, err = s.registry.VMs.Has([]byte("barvm"))
+			assert.NoError(t, err)
+			assert.False(t, ok)
+			ok, err = s.registry.Subnets.Has([]byte("spaces"))
+			assert.NoError(t, err)
+			assert.False(t, ok)
+
+			for _, key := range []string{"foovm", "barvm"} {
+				ok, err := s.repository.VMs.Has([]byte(key))
+				assert.NoError(t, err)
+				assert.False(t, ok)
+			}
+			ok, err = s.repository.Subnets.Has([]byte("spaces"))
+			assert.NoError(t, err)
+			assert.False(t, ok)
+			ok, err = s.other.VMs.Has([]byte("foovm"))
+			assert.NoError(t, err)
+			assert.True(t, ok)
+
+			exists, err := afero.DirExists(fs, clonePath)
+			assert.NoError(t, err)
+			assert.False(t, exists)
+			exists, err = afero.DirExists(fs, otherClonePath)
+			assert.NoError(t, err)
+			assert.True(t, exists)
+		})
+	}
+}
@@ -0,0 +1,263 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+const (
+	// weights used to rank search results, from most to least relevant.
+	aliasExactWeight    = 100
+	idExactWeight       = 90
+	aliasPrefixWeight   = 75
+	aliasContainsWeight = 50
+	idContainsWeight    = 40
+	maintainerWeight    = 20
+	descriptionWeight   = 15
+	homepageWeight      = 10
+
+	// maxDescriptionLength is the longest description shown in the results
+	// before it's truncated.
+	maxDescriptionLength = 60
+)
+
+var _ Workflow = &Search{}
+
+type SearchConfig struct {
+	Query string
+	// Type restricts results to a definition type (vmKey or subnetKey). An
+	// empty type matches both.
+	Type string
+	// Repository restricts results to a single repository alias. An empty
+	// repository matches all tracked repositories.
+	Repository string
+
+	SourcesList storage.Storage[storage.SourceInfo]
+	RepoFactory storage.RepositoryFactory
+}
+
+func NewSearch(config SearchConfig) *Search {
+	return &Search{
+		terms:       strings.Fields(strings.ToLower(config.Query)),
+		typ:         config.Type,
+		repository:  config.Repository,
+		sourcesList: config.SourcesList,
+		repoFactory: config.RepoFactory,
+	}
+}
+
+type Search struct {
+	terms      []string
+	typ        string
+	repository string
+
+	sourcesList storage.Storage[storage.SourceInfo]
+	repoFactory storage.RepositoryFactory
+}
+
+// searchResult is a single definition that matched a search query.
+type searchResult struct {
+	Repository  string
+	Type        string
+	Alias       string
+	Version     string
+	Description string
+	Score       int
+}
+
+func (s *Search) Execute() error {
+	if s.typ != "" && s.typ != vmKey && s.typ != subnetKey {
+		return fmt.Errorf("unknown definition type %q (must be one of %s, %s)", s.typ, vmKey, subnetKey)
+	}
+
+	results, err := s.search()
+	if err != nil {
+		return err
+	}
+
+	if len(results) == 0 {
+		fmt.Printf("No matches found for %q.\n", strings.Join(s.terms, " "))
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 1, 1, 1, ' ', 0)
+	fmt.Fprintln(w, "name\ttype\tversion\tdescription")
+	for _, result := range results {
+		fmt.Fprintf(w, "%s:%s\t%s\t%s\t%s\n", result.Repository, result.Alias, result.Type, result.Version, summarize(result.Description))
+	}
+	return w.Flush()
+}
+
+func (s *Search) search() ([]searchResult, error) {
+	if s.repository != "" {
+		if ok, err := s.sourcesList.Has([]byte(s.repository)); err != nil {
+			return nil, err
+		} else if !ok {
+			return nil, fmt.Errorf("%s is not a tracked repository", s.repository)
+		}
+	}
+
+	itr := s.sourcesList.Iterator()
+	defer itr.Release()
+
+	results := []searchResult{}
+	for itr.Next() {
+		alias := string(itr.Key())
+		if s.repository != "" && s.repository != alias {
+			continue
+		}
+
+		repository := s.repoFactory.GetRepository(itr.Key())
+
+		if s.typ == "" || s.typ == vmKey {
+			vms, err := searchDefinitions[types.VM](alias, vmKey, s.terms, repository.VMs, func(vm types.VM) string {
+				return vm.Version.String()
+			})
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, vms...)
+		}
+
+		if s.typ == "" || s.typ == subnetKey {
+			subnets, err := searchDefinitions[types.Subnet](alias, subnetKey, s.terms, repository.Subnets, func(types.Subnet) string {
+				return "-"
+			})
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, subnets...)
+		}
+	}
+	if err := itr.Error(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].Score != results[j].Score {
+			return results[i].Score > results[j].Score
+		}
+		if results[i].Alias != results[j].Alias {
+			return results[i].Alias < results[j].Alias
+		}
+		return results[i].Repository < results[j].Repository
+	})
+
+	return results, nil
+}
+
+func searchDefinitions[T types.Definition](
+	repository string,
+	typ string,
+	terms []string,
+	db storage.Storage[storage.Definition[T]],
+	version func(T) string,
+) ([]searchResult, error) {
+	itr := db.Iterator()
+	defer itr.Release()
+
+	results := []searchResult{}
+	for itr.Next() {
+		definition, err := itr.Value()
+		if err != nil {
+			return nil, err
+		}
+
+		score := rank(terms, definition.Definition)
+		if score == 0 {
+			continue
+		}
+
+		results = append(results, searchResult{
+			Repository:  repository,
+			Type:        typ,
+			Alias:       definition.Definition.GetAlias(),
+			Version:     version(definition.Definition),
+			Description: definition.Definition.GetDescription(),
+			Score:       score,
+		})
+	}
+
+	return results, itr.Error()
+}
+
+// rank scores how relevant a definition is to the search terms. Every term
+// must match at least one field of the definition, otherwise the definition
+// is scored 0. An empty set of terms matches everything.
+func rank(terms []string, definition types.Definition) int {
+	if len(terms) == 0 {
+		return 1
+	}
+
+	alias := strings.ToLower(definition.GetAlias())
+	id := strings.ToLower(definition.GetID())
+	description := strings.ToLower(definition.GetDescription())
+	homepage := strings.ToLower(definition.GetHomepage())
+
+	total := 0
+	for _, term := range terms {
+		score := 0
+
+		switch {
+		case alias == term:
+			score += aliasExactWeight
+		case strings.HasPrefix(alias, term):
+			score += aliasPrefixWeight
+		case strings.Contains(alias, term):
+			score += aliasContainsWeight
+		}
+
+		switch {
+		case id == term:
+			score += idExactWeight
+		case strings.Contains(id, term):
+			score += idContainsWeight
+		}
+
+		for _, maintainer := range definition.GetMaintainers() {
+			if strings.Contains(strings.ToLower(maintainer), term) {
+				score += maintainerWeight
+				break
+			}
+		}
+
+		if strings.Contains(description, term) {
+			score += descriptionWeight
+		}
+
+		if strings.Contains(homepage, term) {
+			score += homepageWeight
+		}
+
+		if score == 0 {
+			return 0
+		}
+		total += score
+	}
+
+	return total
+}
+
+// summarize returns the first line of a description, truncated so it fits in
+// a table.
+func summarize(description string) string {
+	description = strings.TrimSpace(description)
+	if idx := strings.IndexByte(description, '\n'); idx >= 0 {
+		description = description[:idx]
+	}
+
+	if runes := []rune(description); len(runes) > maxDescriptionLength {
+		return string(runes[:maxDescriptionLength-3]) + "..."
+	}
+
+	return description
+}
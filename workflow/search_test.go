@@ -0,0 +1,330 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	"github.com/DioneProtocol/odysseygo/version"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/mock/gomock"
+	"gopkg.in/yaml.v3"
+
+	"github.com/DioneProtocol/opm/storage"
+	mockdb "github.com/DioneProtocol/opm/storage/mocks"
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestRank(t *testing.T) {
+	vm := types.VM{
+		ID:          "sqja3uK17MJxfC7AN8nGadBw9JK5BcrsNwNynsqP5Gih8M5Bm",
+		Alias:       "spacesvm",
+		Homepage:    "https://tryspaces.xyz",
+		Description: "Virtual machine that processes the spaces subnet.",
+		Maintainers: []string{"dev@dioneprotocol.com"},
+	}
+
+	tests := []struct {
+		name  string
+		terms []string
+		want  int
+	}{
+		{
+			name:  "no terms",
+			terms: []string{},
+			want:  1,
+		},
+		{
+			name:  "exact alias",
+			terms: []string{"spacesvm"},
+			want:  aliasExactWeight,
+		},
+		{
+			name:  "alias prefix",
+			terms: []string{"spaces"},
+			want:  aliasPrefixWeight + descriptionWeight + homepageWeight,
+		},
+		{
+			name:  "alias substring",
+			terms: []string{"esvm"},
+			want:  aliasContainsWeight,
+		},
+		{
+			name:  "exact id",
+			terms: []string{"sqja3uk17mjxfc7an8ngadbw9jk5bcrsnwnynsqp5gih8m5bm"},
+			want:  idExactWeight,
+		},
+		{
+			name:  "maintainer",
+			terms: []string{"dioneprotocol"},
+			want:  maintainerWeight,
+		},
+		{
+			name:  "description",
+			terms: []string{"virtual"},
+			want:  descriptionWeight,
+		},
+		{
+			name:  "all terms must match",
+			terms: []string{"spacesvm", "timestamp"},
+			want:  0,
+		},
+		{
+			name:  "multiple terms",
+			terms: []string{"spacesvm", "virtual"},
+			want:  aliasExactWeight + descriptionWeight,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.want, rank(test.terms, vm))
+		})
+	}
+}
+
+func TestSearchExecute(t *testing.T) {
+	const (
+		alias = "organization/repository"
+	)
+
+	errWrong := fmt.Errorf("something went wrong")
+
+	vm := storage.Definition[types.VM]{
+		Definition: types.VM{
+			ID:          "id",
+			Alias:       "spacesvm",
+			Description: "Virtual machine that processes the spaces subnet.",
+			Version:     version.Semantic{Major: 1, Minor: 2, Patch: 3},
+		},
+	}
+	vmBytes, err := yaml.Marshal(vm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	subnet := storage.Definition[types.Subnet]{
+		Definition: types.Subnet{
+			ID:          "id",
+			Alias:       "spaces",
+			Description: "Authenticated, hierarchical storage of arbitrary keys/values.",
+			VMs:         []string{"spacesvm"},
+		},
+	}
+	subnetBytes, err := yaml.Marshal(subnet)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type mocks struct {
+		ctrl        *gomock.Controller
+		sourcesList *storage.MockStorage[storage.SourceInfo]
+		repoFactory *storage.MockRepositoryFactory
+		vms         *storage.MockStorage[storage.Definition[types.VM]]
+		subnets     *storage.MockStorage[storage.Definition[types.Subnet]]
+	}
+
+	sources := func(mocks mocks) {
+		mocks.sourcesList.EXPECT().Iterator().DoAndReturn(func() storage.Iterator[storage.SourceInfo] {
+			itr := mockdb.NewMockIterator(mocks.ctrl)
+			defer itr.EXPECT().Release()
+
+			itr.EXPECT().Next().Return(true)
+			itr.EXPECT().Key().Return([]byte(alias)).AnyTimes()
+			itr.EXPECT().Next().Return(false)
+			itr.EXPECT().Error().Return(nil)
+
+			return *storage.NewIterator[storage.SourceInfo](itr)
+		})
+		mocks.repoFactory.EXPECT().GetRepository([]byte(alias)).Return(storage.Repository{
+			VMs:     mocks.vms,
+			Subnets: mocks.subnets,
+		})
+	}
+	vms := func(mocks mocks) {
+		mocks.vms.EXPECT().Iterator().DoAndReturn(func() storage.Iterator[storage.Definition[types.VM]] {
+			itr := mockdb.NewMockIterator(mocks.ctrl)
+			defer itr.EXPECT().Release()
+
+			itr.EXPECT().Next().Return(true)
+			itr.EXPECT().Value().Return(vmBytes)
+			itr.EXPECT().Next().Return(false)
+			itr.EXPECT().Error().Return(nil)
+
+			return *storage.NewIterator[storage.Definition[types.VM]](itr)
+		})
+	}
+	subnets := func(mocks mocks) {
+		mocks.subnets.EXPECT().Iterator().DoAndReturn(func() storage.Iterator[storage.Definition[types.Subnet]] {
+			itr := mockdb.NewMockIterator(mocks.ctrl)
+			defer itr.EXPECT().Release()
+
+			itr.EXPECT().Next().Return(true)
+			itr.EXPECT().Value().Return(subnetBytes)
+			itr.EXPECT().Next().Return(false)
+			itr.EXPECT().Error().Return(nil)
+
+			return *storage.NewIterator[storage.Definition[types.Subnet]](itr)
+		})
+	}
+
+	tests := []struct {
+		name       string
+		query      string
+		typ        string
+		repository string
+		setup      func(mocks)
+		want       []searchResult
+		wantErr    error
+	}{
+		{
+			name:       "untracked repository",
+			query:      "spaces",
+			repository: alias,
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Has([]byte(alias)).Return(false, nil)
+			},
+			wantErr: fmt.Errorf("%s is not a tracked repository", alias),
+		},
+		{
+			name:       "can't read from sources list",
+			query:      "spaces",
+			repository: alias,
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Has([]byte(alias)).Return(false, errWrong)
+			},
+			wantErr: errWrong,
+		},
+		{
+			name:  "vms and subnets ranked",
+			query: "spaces",
+			setup: func(mocks mocks) {
+				sources(mocks)
+				vms(mocks)
+				subnets(mocks)
+			},
+			want: []searchResult{
+				{
+					Repository:  alias,
+					Type:        subnetKey,
+					Alias:       "spaces",
+					Version:     "-",
+					Description: subnet.Definition.Description,
+					Score:       aliasExactWeight,
+				},
+				{
+					Repository:  alias,
+					Type:        vmKey,
+					Alias:       "spacesvm",
+					Version:     "v1.2.3",
+					Description: vm.Definition.Description,
+					Score:       aliasPrefixWeight + descriptionWeight,
+				},
+			},
+		},
+		{
+			name:       "filtered by type and repository",
+			query:      "spaces",
+			typ:        vmKey,
+			repository: alias,
+			setup: func(mocks mocks) {
+				mocks.sourcesList.EXPECT().Has([]byte(alias)).Return(true, nil)
+				sources(mocks)
+				vms(mocks)
+			},
+			want: []searchResult{
+				{
+					Repository:  alias,
+					Type:        vmKey,
+					Alias:       "spacesvm",
+					Version:     "v1.2.3",
+					Description: vm.Definition.Description,
+					Score:       aliasPrefixWeight + descriptionWeight,
+				},
+			},
+		},
+		{
+			name:  "no matches",
+			query: "timestamp",
+			setup: func(mocks mocks) {
+				sources(mocks)
+				vms(mocks)
+				subnets(mocks)
+			},
+			want: []searchResult{},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+
+			sourcesList := storage.NewMockStorage[storage.SourceInfo](ctrl)
+			repoFactory := storage.NewMockRepositoryFactory(ctrl)
+			vms := storage.NewMockStorage[storage.Definition[types.VM]](ctrl)
+			subnets := storage.NewMockStorage[storage.Definition[types.Subnet]](ctrl)
+
+			test.setup(mocks{
+				ctrl:        ctrl,
+				sourcesList: sourcesList,
+				repoFactory: repoFactory,
+				vms:         vms,
+				subnets:     subnets,
+			})
+
+			wf := NewSearch(SearchConfig{
+				Query:       test.query,
+				Type:        test.typ,
+				Repository:  test.repository,
+				SourcesList: sourcesList,
+				RepoFactory: repoFactory,
+			})
+
+			got, err := wf.search()
+			assert.Equal(t, test.wantErr, err)
+			if test.wantErr == nil {
+				assert.Equal(t, test.want, got)
+			}
+		})
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	tests := []struct {
+		name        string
+		description string
+		want        string
+	}{
+		{
+			name:        "short",
+			description: "  a virtual machine\nwith details  ",
+			want:        "a virtual machine",
+		},
+		{
+			name:        "long",
+			description: strings.Repeat("a", maxDescriptionLength+1),
+			want:        strings.Repeat("a", maxDescriptionLength-3) + "...",
+		},
+		{
+			name:        "multi-byte fits",
+			description: strings.Repeat("é", maxDescriptionLength),
+			want:        strings.Repeat("é", maxDescriptionLength),
+		},
+		{
+			name:        "multi-byte truncated by runes",
+			description: strings.Repeat("日本", maxDescriptionLength),
+			want:        strings.Repeat("日本", (maxDescriptionLength-3)/2) + "日...",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := summarize(test.description)
+			assert.Equal(t, test.want, got)
+			assert.True(t, utf8.ValidString(got))
+		})
+	}
+}
@@ -25,6 +25,7 @@ func NewUninstall(config UninstallConfig) *Uninstall {
 		plugin:       config.Plugin,
 		vmStorage:    config.VMStorage,
 		installedVMs: config.InstalledVMs,
+		configFiles:  config.ConfigFiles,
 		fs:           config.Fs,
 		pluginPath:   config.PluginPath,
 	}
@@ -36,6 +37,7 @@ type UninstallConfig struct {
 	RepoAlias    string
 	VMStorage    storage.Storage[storage.Definition[types.VM]]
 	InstalledVMs storage.Storage[storage.InstallInfo]
+	ConfigFiles  storage.Storage[storage.ConfigFile]
 	Fs           afero.Fs
 	PluginPath   string
 }
@@ -46,46 +48,40 @@ type Uninstall struct {
 	repoAlias    string
 	vmStorage    storage.Storage[storage.Definition[types.VM]]
 	installedVMs storage.Storage[storage.InstallInfo]
+	configFiles  storage.Storage[storage.ConfigFile]
 	fs           afero.Fs
 	pluginPath   string
 }
 
 func (u Uninstall) Execute() error {
-	ok, err := u.installedVMs.Has([]byte(u.name))
-	if err != nil {
-		return err
-	}
-
-	if !ok {
+	installInfo, err := u.installedVMs.Get([]byte(u.name))
+	if err == database.ErrNotFound {
 		fmt.Printf("VM %s is already not installed. Skipping.\n", u.name)
 		return nil
-	}
-
-	vm, err := u.vmStorage.Get([]byte(u.plugin))
-	if err == database.ErrNotFound {
-		// If we don't have the definition, provide a warning log. It's possible
-		// this used to exist and was removed for whatever reason. In that case,
-		// we should still remove it from our installation registry to unblock
-		// the user.
-		fmt.Printf("Virtual machine %s doesn't exist under the repository for %s. Continuing uninstall anyways...\n", u.plugin, u.repoAlias)
 	} else if err != nil {
 		return err
 	}
 
-	vmPath := filepath.Join(u.pluginPath, vm.Definition.GetID())
+	vmPath, err := u.binaryPath(installInfo)
+	if err != nil {
+		return err
+	}
 
-	switch _, err := u.fs.Stat(vmPath); err {
-	case nil:
+	if vmPath == "" {
+		fmt.Printf("The binary of %s isn't known. Nothing to delete here.\n", u.name)
+	} else if _, err := u.fs.Stat(vmPath); err == nil {
 		fmt.Printf("Deleting %s...\n", vmPath)
 		if err := u.fs.Remove(vmPath); err != nil {
 			return err
 		}
-	default:
-		if errors.Is(err, fs.ErrNotExist) {
-			fmt.Printf("%s doesn't exist already. Nothing to delete here.\n", vmPath)
-		} else {
-			return err
-		}
+	} else if errors.Is(err, fs.ErrNotExist) {
+		fmt.Printf("%s doesn't exist already. Nothing to delete here.\n", vmPath)
+	} else {
+		return err
+	}
+
+	if err := removeConfigFiles(u.fs, u.configFiles, u.name, nil); err != nil {
+		return err
 	}
 
 	if err := u.installedVMs.Delete([]byte(u.name)); err != nil {
@@ -95,3 +91,33 @@ func (u Uninstall) Execute() error {
 
 	return nil
 }
+
+// binaryPath returns where the binary described by [installInfo] was
+// installed, without relying on the definition, which is gone once its
+// repository is removed. VMs installed by older versions of opm didn't record
+// it, so it's found from their definition instead. An empty path is returned
+// if neither is known.
+func (u Uninstall) binaryPath(installInfo storage.InstallInfo) (string, error) {
+	switch {
+	case installInfo.BinaryPath != "":
+		return installInfo.BinaryPath, nil
+	case installInfo.ID != "":
+		return filepath.Join(u.pluginPath, installInfo.ID), nil
+	}
+
+	vm, err := u.vmStorage.Get([]byte(u.plugin))
+	if err == database.ErrNotFound {
+		// It's possible the definition used to exist and was removed for
+		// whatever reason. In that case, we should still remove it from our
+		// installation registry to unblock the user.
+		fmt.Printf("Virtual machine %s doesn't exist under the repository for %s. Continuing uninstall anyways...\n", u.plugin, u.repoAlias)
+		return "", nil
+	} else if err != nil {
+		return "", err
+	}
+	if vm.Definition.GetID() == "" {
+		return "", nil
+	}
+
+	return filepath.Join(u.pluginPath, vm.Definition.GetID()), nil
+}
@@ -15,6 +15,7 @@ import (
 	"go.uber.org/mock/gomock"
 
 	"github.com/DioneProtocol/opm/storage"
+	mockdb "github.com/DioneProtocol/opm/storage/mocks"
 	"github.com/DioneProtocol/opm/types"
 )
 
@@ -40,8 +41,22 @@ func TestUninstallExecute(t *testing.T) {
 	}
 
 	type mocks struct {
+		ctrl         *gomock.Controller
 		vmStorage    *storage.MockStorage[storage.Definition[types.VM]]
 		installedVMs *storage.MockStorage[storage.InstallInfo]
+		configFiles  *storage.MockStorage[storage.ConfigFile]
+	}
+
+	noConfigFiles := func(mocks mocks) {
+		mocks.configFiles.EXPECT().Iterator().DoAndReturn(func() storage.Iterator[storage.ConfigFile] {
+			itr := mockdb.NewMockIterator(mocks.ctrl)
+			defer itr.EXPECT().Release()
+
+			itr.EXPECT().Next().Return(false)
+			itr.EXPECT().Error().Return(nil)
+
+			return *storage.NewIterator[storage.ConfigFile](itr)
+		})
 	}
 	tests := []struct {
 		name    string
@@ -51,7 +66,7 @@ func TestUninstallExecute(t *testing.T) {
 		{
 			name: "can't read from installed vms",
 			setup: func(mocks mocks) {
-				mocks.installedVMs.EXPECT().Has(nameBytes).Return(false, errWrong)
+				mocks.installedVMs.EXPECT().Get(nameBytes).Return(storage.InstallInfo{}, errWrong)
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 				return assert.Equal(t, err, errWrong)
@@ -60,7 +75,7 @@ func TestUninstallExecute(t *testing.T) {
 		{
 			name: "vm already uninstalled",
 			setup: func(mocks mocks) {
-				mocks.installedVMs.EXPECT().Has(nameBytes).Return(false, nil)
+				mocks.installedVMs.EXPECT().Get(nameBytes).Return(storage.InstallInfo{}, database.ErrNotFound)
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 				return assert.Nil(t, err)
@@ -69,7 +84,7 @@ func TestUninstallExecute(t *testing.T) {
 		{
 			name: "can't read from repository vms",
 			setup: func(mocks mocks) {
-				mocks.installedVMs.EXPECT().Has(nameBytes).Return(true, nil)
+				mocks.installedVMs.EXPECT().Get(nameBytes).Return(storage.InstallInfo{}, nil)
 				mocks.vmStorage.EXPECT().Get(pluginBytes).Return(storage.Definition[types.VM]{}, errWrong)
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
@@ -79,8 +94,9 @@ func TestUninstallExecute(t *testing.T) {
 		{
 			name: "uninstalling an invalid vm",
 			setup: func(mocks mocks) {
-				mocks.installedVMs.EXPECT().Has(nameBytes).Return(true, nil)
+				mocks.installedVMs.EXPECT().Get(nameBytes).Return(storage.InstallInfo{}, nil)
 				mocks.vmStorage.EXPECT().Get(pluginBytes).Return(storage.Definition[types.VM]{}, database.ErrNotFound)
+				noConfigFiles(mocks)
 				mocks.installedVMs.EXPECT().Delete(nameBytes).Return(nil)
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
@@ -90,8 +106,9 @@ func TestUninstallExecute(t *testing.T) {
 		{
 			name: "removing from installation registry fails",
 			setup: func(mocks mocks) {
-				mocks.installedVMs.EXPECT().Has(nameBytes).Return(true, nil)
+				mocks.installedVMs.EXPECT().Get(nameBytes).Return(storage.InstallInfo{}, nil)
 				mocks.vmStorage.EXPECT().Get(pluginBytes).Return(definition, nil)
+				noConfigFiles(mocks)
 				mocks.installedVMs.EXPECT().Delete(nameBytes).Return(errWrong)
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
@@ -101,8 +118,20 @@ func TestUninstallExecute(t *testing.T) {
 		{
 			name: "success",
 			setup: func(mocks mocks) {
-				mocks.installedVMs.EXPECT().Has(nameBytes).Return(true, nil)
+				mocks.installedVMs.EXPECT().Get(nameBytes).Return(storage.InstallInfo{}, nil)
 				mocks.vmStorage.EXPECT().Get(pluginBytes).Return(definition, nil)
+				noConfigFiles(mocks)
+				mocks.installedVMs.EXPECT().Delete(nameBytes).Return(nil)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.Nil(t, err)
+			},
+		},
+		{
+			name: "recorded binary without definition",
+			setup: func(mocks mocks) {
+				mocks.installedVMs.EXPECT().Get(nameBytes).Return(storage.InstallInfo{ID: "id", BinaryPath: "plugins/id"}, nil)
+				noConfigFiles(mocks)
 				mocks.installedVMs.EXPECT().Delete(nameBytes).Return(nil)
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
@@ -120,10 +149,13 @@ func TestUninstallExecute(t *testing.T) {
 
 			vmStorage = storage.NewMockStorage[storage.Definition[types.VM]](ctrl)
 			installedVMs = storage.NewMockStorage[storage.InstallInfo](ctrl)
+			configFiles := storage.NewMockStorage[storage.ConfigFile](ctrl)
 
 			test.setup(mocks{
+				ctrl:         ctrl,
 				vmStorage:    vmStorage,
 				installedVMs: installedVMs,
+				configFiles:  configFiles,
 			})
 
 			wf := NewUninstall(
@@ -133,6 +165,7 @@ func TestUninstallExecute(t *testing.T) {
 					RepoAlias:    "organization/repository",
 					VMStorage:    vmStorage,
 					InstalledVMs: installedVMs,
+					ConfigFiles:  configFiles,
 					Fs:           afero.NewMemMapFs(),
 				},
 			)
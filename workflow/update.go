@@ -8,10 +8,10 @@ import (
 	"path/filepath"
 
 	"github.com/DioneProtocol/odysseygo/database"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/spf13/afero"
 
 	"github.com/DioneProtocol/opm/git"
+	"github.com/DioneProtocol/opm/source"
 	"github.com/DioneProtocol/opm/storage"
 	"github.com/DioneProtocol/opm/util"
 )
@@ -20,7 +20,7 @@ var _ Workflow = &Update{}
 
 type UpdateConfig struct {
 	Executor         Executor
-	Registry         storage.Storage[storage.RepoList]
+	Registry         storage.Registry
 	InstalledVMs     storage.Storage[storage.InstallInfo]
 	SourcesList      storage.Storage[storage.SourceInfo]
 	DB               database.Database
@@ -28,10 +28,14 @@ type UpdateConfig struct {
 	PluginPath       string
 	Installer        Installer
 	RepositoriesPath string
-	Auth             http.BasicAuth
-	GitFactory       git.Factory
+	Sources          map[storage.SourceType]source.Source
+	Differ           git.Differ
 	RepoFactory      storage.RepositoryFactory
 	Fs               afero.Fs
+
+	// Aliases limits the update to these repositories. Every repository is
+	// updated if it's empty.
+	Aliases []string
 }
 
 func NewUpdate(config UpdateConfig) *Update {
@@ -45,27 +49,29 @@ func NewUpdate(config UpdateConfig) *Update {
 		installer:        config.Installer,
 		sourcesList:      config.SourcesList,
 		repositoriesPath: config.RepositoriesPath,
-		auth:             config.Auth,
-		gitFactory:       config.GitFactory,
+		sources:          config.Sources,
+		differ:           config.Differ,
 		repoFactory:      config.RepoFactory,
 		fs:               config.Fs,
+		aliases:          config.Aliases,
 	}
 }
 
 type Update struct {
 	executor         Executor
 	db               database.Database
-	registry         storage.Storage[storage.RepoList]
+	registry         storage.Registry
 	installedVMs     storage.Storage[storage.InstallInfo]
 	sourcesList      storage.Storage[storage.SourceInfo]
 	installer        Installer
-	auth             http.BasicAuth
 	tmpPath          string
 	pluginPath       string
 	repositoriesPath string
-	gitFactory       git.Factory
+	sources          map[storage.SourceType]source.Source
+	differ           git.Differ
 	repoFactory      storage.RepositoryFactory
 	fs               afero.Fs
+	aliases          []string
 }
 
 func (u Update) Execute() error {
@@ -75,6 +81,9 @@ func (u Update) Execute() error {
 	for itr.Next() {
 		aliasBytes := itr.Key()
 		alias := string(aliasBytes)
+		if !u.includes(alias) {
+			continue
+		}
 		organization, repo := util.ParseAlias(alias)
 
 		sourceInfo, err := itr.Value()
@@ -83,7 +92,11 @@ func (u Update) Execute() error {
 		}
 		previousCommit := sourceInfo.Commit
 		repositoryPath := filepath.Join(u.repositoriesPath, organization, repo)
-		latestCommit, err := u.gitFactory.GetRepository(sourceInfo.URL, repositoryPath, sourceInfo.Branch, &u.auth)
+		source, ok := u.sources[sourceInfo.Source]
+		if !ok {
+			return fmt.Errorf("%s has an unknown source %q", alias, sourceInfo.Source)
+		}
+		definitionsPath, latestCommit, err := source.Sync(sourceInfo, repositoryPath)
 		if err != nil {
 			return err
 		}
@@ -93,9 +106,16 @@ func (u Update) Execute() error {
 			continue
 		}
 
+		// Only git repositories have a history to diff against, so the others
+		// are rescanned.
+		var differ git.Differ
+		if sourceInfo.Source == storage.GitSource {
+			differ = u.differ
+		}
+
 		workflow := NewUpdateRepository(UpdateRepositoryConfig{
 			RepoName:       repo,
-			RepositoryPath: repositoryPath,
+			RepositoryPath: definitionsPath,
 			AliasBytes:     aliasBytes,
 			PreviousCommit: previousCommit,
 			LatestCommit:   latestCommit,
@@ -103,6 +123,7 @@ func (u Update) Execute() error {
 			Registry:       u.registry,
 			SourceInfo:     sourceInfo,
 			SourcesList:    u.sourcesList,
+			Differ:         differ,
 			Fs:             u.fs,
 		})
 
@@ -113,3 +134,17 @@ func (u Update) Execute() error {
 
 	return nil
 }
+
+// includes returns true if the repository [alias] should be updated.
+func (u Update) includes(alias string) bool {
+	if len(u.aliases) == 0 {
+		return true
+	}
+
+	for _, included := range u.aliases {
+		if included == alias {
+			return true
+		}
+	}
+	return false
+}
@@ -4,7 +4,10 @@
 package workflow
 
 import (
+	"errors"
 	"fmt"
+	"os"
+	"path"
 	"path/filepath"
 	"sort"
 
@@ -13,6 +16,7 @@ import (
 	"github.com/spf13/afero"
 	"gopkg.in/yaml.v3"
 
+	"github.com/DioneProtocol/opm/git"
 	"github.com/DioneProtocol/opm/storage"
 	"github.com/DioneProtocol/opm/types"
 )
@@ -38,10 +42,11 @@ type UpdateRepositoryConfig struct {
 
 	SourceInfo  storage.SourceInfo
 	Repository  storage.Repository
-	Registry    storage.Storage[storage.RepoList]
+	Registry    storage.Registry
 	SourcesList storage.Storage[storage.SourceInfo]
 
-	Fs afero.Fs
+	Differ git.Differ
+	Fs     afero.Fs
 }
 
 func NewUpdateRepository(config UpdateRepositoryConfig) *UpdateRepository {
@@ -55,6 +60,7 @@ func NewUpdateRepository(config UpdateRepositoryConfig) *UpdateRepository {
 		registry:           config.Registry,
 		sourcesList:        config.SourcesList,
 		repositoryMetadata: config.SourceInfo,
+		differ:             config.Differ,
 		fs:                 config.Fs,
 	}
 }
@@ -69,28 +75,39 @@ type UpdateRepository struct {
 	latestCommit   plumbing.Hash
 
 	repository  storage.Repository
-	registry    storage.Storage[storage.RepoList]
+	registry    storage.Registry
 	sourcesList storage.Storage[storage.SourceInfo]
 
 	repositoryMetadata storage.SourceInfo
 
-	fs afero.Fs
+	differ git.Differ
+	fs     afero.Fs
 }
 
+// Execute syncs the definitions in the repository to [latestCommit]. The
+// definitions, the registry and the repository's checkpoint are written
+// atomically, so an interrupted update leaves everything at the previous
+// commit.
 func (u *UpdateRepository) Execute() error {
-	if err := u.update(); err != nil {
+	vmRegistryBatch := u.registry.VMs.NewBatch()
+	subnetRegistryBatch := u.registry.Subnets.NewBatch()
+	vmsBatch := u.repository.VMs.NewBatch()
+	subnetsBatch := u.repository.Subnets.NewBatch()
+	sourcesBatch := u.sourcesList.NewBatch()
+
+	if err := u.update(vmRegistryBatch, subnetRegistryBatch, vmsBatch, subnetsBatch); err != nil {
 		fmt.Printf("Unexpected error while updating definitions. %s", err)
 		return err
 	}
 
 	// checkpoint progress
-	updatedCheckpoint := storage.SourceInfo{
-		Alias:  u.repositoryMetadata.Alias,
-		URL:    u.repositoryMetadata.URL,
-		Commit: u.latestCommit,
-		Branch: u.repositoryMetadata.Branch,
+	updatedCheckpoint := u.repositoryMetadata
+	updatedCheckpoint.Commit = u.latestCommit
+	if err := sourcesBatch.Put(u.aliasBytes, updatedCheckpoint); err != nil {
+		return err
 	}
-	if err := u.sourcesList.Put(u.aliasBytes, updatedCheckpoint); err != nil {
+
+	if err := storage.WriteAll(vmRegistryBatch, subnetRegistryBatch, vmsBatch, subnetsBatch, sourcesBatch); err != nil {
 		return err
 	}
 
@@ -99,35 +116,178 @@ func (u *UpdateRepository) Execute() error {
 	return nil
 }
 
-func (u *UpdateRepository) update() error {
+// update syncs the definitions that changed since [previousCommit]. All
+// definitions are rescanned if there's no previous commit, if it isn't in the
+// history anymore (e.g. after a force push), or if the repository doesn't have
+// a history to diff.
+func (u *UpdateRepository) update(
+	vmRegistryBatch *storage.Batch[storage.RepoList],
+	subnetRegistryBatch *storage.Batch[storage.RepoList],
+	vmsBatch *storage.Batch[storage.Definition[types.VM]],
+	subnetsBatch *storage.Batch[storage.Definition[types.Subnet]],
+) error {
+	if u.previousCommit == plumbing.ZeroHash {
+		if err := u.rescan(vmRegistryBatch, subnetRegistryBatch, vmsBatch, subnetsBatch); err != nil {
+			return err
+		}
+
+		fmt.Printf("Finished initializing definitions for %s@%s.\n", u.repoName, u.latestCommit)
+		return nil
+	}
+	if u.differ == nil {
+		if err := u.rescan(vmRegistryBatch, subnetRegistryBatch, vmsBatch, subnetsBatch); err != nil {
+			return err
+		}
+
+		fmt.Printf("Finished updating definitions from %s to %s@%s.\n", u.previousCommit, u.repoName, u.latestCommit)
+		return nil
+	}
+
+	changes, err := u.differ.Diff(u.repositoryPath, u.previousCommit, u.latestCommit, []string{vmDir, subnetDir})
+	switch {
+	case err == nil:
+		repositoryAlias := string(u.aliasBytes)
+		if err := applyChanges[types.VM](changes, vmKey, vmDir, repositoryAlias, u.latestCommit, u.registry.VMs, vmRegistryBatch, vmsBatch); err != nil {
+			return err
+		}
+		if err := applyChanges[types.Subnet](changes, subnetKey, subnetDir, repositoryAlias, u.latestCommit, u.registry.Subnets, subnetRegistryBatch, subnetsBatch); err != nil {
+			return err
+		}
+	case errors.Is(err, git.ErrCommitNotFound):
+		fmt.Printf("%s is no longer in the history of %s. Rescanning all definitions.\n", u.previousCommit, u.repoName)
+		if err := u.rescan(vmRegistryBatch, subnetRegistryBatch, vmsBatch, subnetsBatch); err != nil {
+			return err
+		}
+	default:
+		return err
+	}
+
+	fmt.Printf("Finished updating definitions from %s to %s@%s.\n", u.previousCommit, u.repoName, u.latestCommit)
+	return nil
+}
+
+// rescan loads every definition in the repository, and deletes the stored ones
+// that weren't loaded.
+func (u *UpdateRepository) rescan(
+	vmRegistryBatch *storage.Batch[storage.RepoList],
+	subnetRegistryBatch *storage.Batch[storage.RepoList],
+	vmsBatch *storage.Batch[storage.Definition[types.VM]],
+	subnetsBatch *storage.Batch[storage.Definition[types.Subnet]],
+) error {
 	vmsPath := filepath.Join(u.repositoryPath, vmDir)
 
-	if err := loadFromYAML[types.VM](u.fs, vmKey, vmsPath, u.aliasBytes, u.latestCommit, u.registry, u.repository.VMs); err != nil {
+	vms, err := loadFromYAML[types.VM](u.fs, vmKey, vmsPath, u.aliasBytes, u.latestCommit, u.registry.VMs, vmRegistryBatch, vmsBatch)
+	if err != nil {
 		return err
 	}
 
 	subnetsPath := filepath.Join(u.repositoryPath, subnetDir)
-	if err := loadFromYAML[types.Subnet](u.fs, subnetKey, subnetsPath, u.aliasBytes, u.latestCommit, u.registry, u.repository.Subnets); err != nil {
+	subnets, err := loadFromYAML[types.Subnet](u.fs, subnetKey, subnetsPath, u.aliasBytes, u.latestCommit, u.registry.Subnets, subnetRegistryBatch, subnetsBatch)
+	if err != nil {
 		return err
 	}
 
 	// Now we need to delete anything that wasn't updated in the latest commit
-	if err := deleteStaleDefinitions[types.VM](u.repository.VMs, u.latestCommit); err != nil {
+	staleVMs, err := deleteStaleDefinitions[types.VM](u.repository.VMs, vmsBatch, vms, u.latestCommit)
+	if err != nil {
 		return err
 	}
-	if err := deleteStaleDefinitions[types.Subnet](u.repository.Subnets, u.latestCommit); err != nil {
+	staleSubnets, err := deleteStaleDefinitions[types.Subnet](u.repository.Subnets, subnetsBatch, subnets, u.latestCommit)
+	if err != nil {
 		return err
 	}
 
-	if u.previousCommit == plumbing.ZeroHash {
-		fmt.Printf("Finished initializing definitions for %s@%s.\n", u.repoName, u.latestCommit)
-	} else {
-		fmt.Printf("Finished updating definitions from %s to %s@%s.\n", u.previousCommit, u.repoName, u.latestCommit)
+	for alias := range staleVMs {
+		if err := unregister(u.registry.VMs, vmRegistryBatch, alias, string(u.aliasBytes)); err != nil {
+			return err
+		}
+	}
+	for alias := range staleSubnets {
+		if err := unregister(u.registry.Subnets, subnetRegistryBatch, alias, string(u.aliasBytes)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// applyChanges adds the [changes] to the files in [dir] to [batch] and
+// [registryBatch]. Definitions of files that were modified or deleted are
+// deleted, unless a changed file still defines them.
+func applyChanges[T types.Definition](
+	changes []git.Change,
+	key string,
+	dir string,
+	repositoryAlias string,
+	commit plumbing.Hash,
+	registry storage.Storage[storage.RepoList],
+	registryBatch *storage.Batch[storage.RepoList],
+	batch *storage.Batch[storage.Definition[T]],
+) error {
+	loaded := map[string]struct{}{}
+	removed := map[string]struct{}{}
+
+	for _, change := range changes {
+		if change.From != "" && path.Dir(change.From) == dir {
+			// The previous version of the file was either loaded or skipped, so
+			// it's only used to find the alias it defined.
+			definition, ok, err := parseDefinition[T](key, path.Base(change.From), change.Before)
+			if err == nil && ok && definition.Verify() == nil {
+				removed[definition.GetAlias()] = struct{}{}
+			}
+		}
+
+		if change.To == "" || path.Dir(change.To) != dir {
+			continue
+		}
+		fileName := path.Base(change.To)
+		definition, ok, err := parseDefinition[T](key, fileName, change.After)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := definition.Verify(); err != nil {
+			fmt.Printf("Skipping invalid %s definition in %s: %s.\n", key, fileName, err)
+			continue
+		}
+
+		alias := definition.GetAlias()
+		if err := register(registry, registryBatch, alias, repositoryAlias); err != nil {
+			return err
+		}
+		if err := batch.Put([]byte(alias), storage.Definition[T]{
+			Definition: definition,
+			Commit:     commit,
+		}); err != nil {
+			return err
+		}
+		loaded[alias] = struct{}{}
+
+		fmt.Printf("Updated plugin definition in registry for %s:%s@%s.\n", repositoryAlias, alias, commit)
+	}
+
+	for alias := range removed {
+		if _, ok := loaded[alias]; ok {
+			continue
+		}
+
+		fmt.Printf("Deleting a stale plugin: %s as of %s.\n", alias, commit)
+		if err := batch.Delete([]byte(alias)); err != nil {
+			return err
+		}
+		if err := unregister(registry, registryBatch, alias, repositoryAlias); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
 
+// loadFromYAML adds the definitions in [path] to [batch] and registers them in
+// [registryBatch]. The aliases of the loaded definitions are returned. A
+// missing directory has no definitions.
 func loadFromYAML[T types.Definition](
 	fs afero.Fs,
 	key string,
@@ -135,97 +295,185 @@ func loadFromYAML[T types.Definition](
 	repositoryAlias []byte,
 	commit plumbing.Hash,
 	registry storage.Storage[storage.RepoList],
-	repository storage.Storage[storage.Definition[T]],
-) error {
+	registryBatch *storage.Batch[storage.RepoList],
+	batch *storage.Batch[storage.Definition[T]],
+) (map[string]struct{}, error) {
 	files, err := afero.ReadDir(fs, path)
-	if err != nil {
-		return err
+	if errors.Is(err, os.ErrNotExist) {
+		return map[string]struct{}{}, nil
+	} else if err != nil {
+		return nil, err
 	}
-	// globalBatch := registry.NewBatch()
-	// repoBatch := repository.NewBatch()
 
+	loaded := make(map[string]struct{}, len(files))
 	for _, file := range files {
 		if file.IsDir() {
 			continue
 		}
 
-		nameWithExtension := file.Name()
-		// Strip any extension from the file. This is to support windows .exe
-		// files.
-		name := nameWithExtension[:len(nameWithExtension)-len(filepath.Ext(nameWithExtension))]
-
-		// Skip hidden files.
-		if len(name) == 0 {
-			continue
+		fileBytes, err := afero.ReadFile(fs, filepath.Join(path, file.Name()))
+		if err != nil {
+			return nil, err
 		}
 
-		fileBytes, err := afero.ReadFile(fs, filepath.Join(path, file.Name()))
+		definition, ok, err := parseDefinition[T](key, file.Name(), fileBytes)
 		if err != nil {
-			return err
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+		if err := definition.Verify(); err != nil {
+			fmt.Printf("Skipping invalid %s definition in %s: %s.\n", key, file.Name(), err)
+			continue
 		}
-		data := make(map[string]T)
 
-		if err := yaml.Unmarshal(fileBytes, data); err != nil {
-			return err
+		alias := definition.GetAlias()
+		if err := register(registry, registryBatch, alias, string(repositoryAlias)); err != nil {
+			return nil, err
 		}
-		definition := storage.Definition[T]{
-			Definition: data[key],
+		if err := batch.Put([]byte(alias), storage.Definition[T]{
+			Definition: definition,
 			Commit:     commit,
+		}); err != nil {
+			return nil, err
 		}
+		loaded[alias] = struct{}{}
 
-		alias := data[key].GetAlias()
-		aliasBytes := []byte(alias)
+		fmt.Printf("Updated plugin definition in registry for %s:%s@%s.\n", repositoryAlias, alias, commit)
+	}
 
-		repoList, err := registry.Get(aliasBytes)
-		if err == database.ErrNotFound {
-			repoList = storage.RepoList{ // TODO check if this can be removed
-				Repositories: []string{},
-			}
-		} else if err != nil {
-			return err
-		}
+	return loaded, nil
+}
 
-		repositoryAliasStr := string(repositoryAlias)
-		idx := sort.SearchStrings(repoList.Repositories, repositoryAliasStr)
+// parseDefinition parses the [key] definition in [contents] of the file
+// [fileName]. Hidden files are skipped.
+func parseDefinition[T types.Definition](key string, fileName string, contents []byte) (T, bool, error) {
+	// Strip any extension from the file. This is to support windows .exe
+	// files.
+	name := fileName[:len(fileName)-len(filepath.Ext(fileName))]
 
-		if idx == len(repoList.Repositories) {
-			repoList.Repositories = append(repoList.Repositories, repositoryAliasStr)
-		} else if repoList.Repositories[idx] != repositoryAliasStr {
-			repoList.Repositories = append(repoList.Repositories[:idx+1], repoList.Repositories[idx:]...)
-			repoList.Repositories[idx] = repositoryAliasStr
-		}
+	// Skip hidden files.
+	if len(name) == 0 {
+		return *new(T), false, nil
+	}
 
-		if err := registry.Put(aliasBytes, repoList); err != nil {
-			return err
-		}
-		if err := repository.Put(aliasBytes, definition); err != nil {
-			return err
+	data := make(map[string]T)
+	if err := yaml.Unmarshal(contents, data); err != nil {
+		return *new(T), false, err
+	}
+
+	return data[key], true, nil
+}
+
+// register adds [repositoryAlias] to the repositories providing [alias] in
+// [registryBatch], keeping them sorted.
+func register(
+	registry storage.Storage[storage.RepoList],
+	registryBatch *storage.Batch[storage.RepoList],
+	alias string,
+	repositoryAlias string,
+) error {
+	aliasBytes := []byte(alias)
+
+	repoList, err := registry.Get(aliasBytes)
+	if err == database.ErrNotFound {
+		repoList = storage.RepoList{ // TODO check if this can be removed
+			Repositories: []string{},
 		}
+	} else if err != nil {
+		return err
+	}
 
-		fmt.Printf("Updated plugin definition in registry for %s:%s@%s.\n", repositoryAlias, alias, commit)
+	idx := sort.SearchStrings(repoList.Repositories, repositoryAlias)
+
+	if idx == len(repoList.Repositories) {
+		repoList.Repositories = append(repoList.Repositories, repositoryAlias)
+	} else if repoList.Repositories[idx] != repositoryAlias {
+		repoList.Repositories = append(repoList.Repositories[:idx+1], repoList.Repositories[idx:]...)
+		repoList.Repositories[idx] = repositoryAlias
 	}
 
-	return nil
+	return registryBatch.Put(aliasBytes, repoList)
 }
 
-func deleteStaleDefinitions[T types.Definition](db storage.Storage[storage.Definition[T]], latestCommit plumbing.Hash) error {
+// deleteStaleDefinitions adds every definition in [db] that isn't in [loaded]
+// to [batch] for deletion. The aliases of the deleted definitions are returned.
+func deleteStaleDefinitions[T types.Definition](
+	db storage.Storage[storage.Definition[T]],
+	batch *storage.Batch[storage.Definition[T]],
+	loaded map[string]struct{},
+	latestCommit plumbing.Hash,
+) (map[string]struct{}, error) {
 	itr := db.Iterator()
 	defer itr.Release()
-	// TODO batching
 
+	stale := map[string]struct{}{}
 	for itr.Next() {
+		// Definitions loaded from the latest commit are only in the batch, so
+		// the stored ones are checked against what was loaded instead of by
+		// commit.
+		alias := string(itr.Key())
+		if _, ok := loaded[alias]; ok {
+			continue
+		}
+
 		definition, err := itr.Value()
 		if err != nil {
-			return err
+			return nil, err
 		}
 
-		if definition.Commit != latestCommit {
-			fmt.Printf("Deleting a stale plugin: %s@%s as of %s.\n", definition.Definition.GetAlias(), definition.Commit, latestCommit)
-			if err := db.Delete(itr.Key()); err != nil {
-				return err
-			}
+		fmt.Printf("Deleting a stale plugin: %s@%s as of %s.\n", definition.Definition.GetAlias(), definition.Commit, latestCommit)
+		if err := batch.Delete(itr.Key()); err != nil {
+			return nil, err
 		}
+		stale[alias] = struct{}{}
 	}
 
-	return nil
+	return stale, itr.Error()
+}
+
+// unregister adds the removal of [repositoryAlias] from the repositories
+// providing [alias] to [batch]. The alias is removed from the registry if no
+// other repository provides it.
+func unregister(
+	registry storage.Storage[storage.RepoList],
+	batch *storage.Batch[storage.RepoList],
+	alias string,
+	repositoryAlias string,
+) error {
+	aliasBytes := []byte(alias)
+	repoList, err := registry.Get(aliasBytes)
+	if err == database.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	return unregisterFrom(batch, aliasBytes, repoList, repositoryAlias)
+}
+
+// unregisterFrom adds the removal of [repositoryAlias] from [repoList], which
+// is registered under [key], to [batch].
+func unregisterFrom(
+	batch *storage.Batch[storage.RepoList],
+	key []byte,
+	repoList storage.RepoList,
+	repositoryAlias string,
+) error {
+	repositories := make([]string, 0, len(repoList.Repositories))
+	for _, repository := range repoList.Repositories {
+		if repository != repositoryAlias {
+			repositories = append(repositories, repository)
+		}
+	}
+
+	switch {
+	case len(repositories) == len(repoList.Repositories):
+		return nil
+	case len(repositories) == 0:
+		return batch.Delete(key)
+	default:
+		return batch.Put(key, storage.RepoList{Repositories: repositories})
+	}
 }
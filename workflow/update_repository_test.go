@@ -7,6 +7,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/DioneProtocol/odysseygo/database/memdb"
 	"github.com/DioneProtocol/odysseygo/utils/perms"
 	"github.com/DioneProtocol/odysseygo/utils/wrappers"
 	"github.com/go-git/go-git/v5/plumbing"
@@ -14,8 +15,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 
+	"github.com/DioneProtocol/opm/git"
 	"github.com/DioneProtocol/opm/storage"
-	mockdb "github.com/DioneProtocol/opm/storage/mocks"
 	"github.com/DioneProtocol/opm/types"
 )
 
@@ -115,125 +116,272 @@ func TestUpdateRepositoryExecute(t *testing.T) {
 		}
 	}
 
-	type mocks struct {
-		ctrl *gomock.Controller
-
-		fs afero.Fs
+	type stores struct {
+		registry    storage.Registry
+		sourcesList storage.Storage[storage.SourceInfo]
+		repository  storage.Repository
+	}
 
-		registry    *storage.MockStorage[storage.RepoList]
-		sourcesList *storage.MockStorage[storage.SourceInfo]
-		vms         *storage.MockStorage[storage.Definition[types.VM]]
-		subnets     *storage.MockStorage[storage.Definition[types.Subnet]]
+	staleVM := storage.Definition[types.VM]{
+		Definition: types.VM{Alias: "stalevm", Maintainers: []string{}},
+		Commit:     previousCommit,
 	}
+
+	removedVM := []byte(`vm:
+  id: "removedid"
+  alias: "removedvm"`,
+	)
+
 	tests := []struct {
-		name    string
-		setup   func(*testing.T, mocks)
-		wantErr assert.ErrorAssertionFunc
+		name  string
+		setup func(*testing.T, afero.Fs, stores)
+		// changes are the changes since the previous commit. If they're nil,
+		// the previous commit isn't in the history and everything is rescanned.
+		changes []git.Change
+		// noHistory is set for repositories that can't be diffed.
+		noHistory bool
+		check     func(*testing.T, stores)
+		wantErr   assert.ErrorAssertionFunc
 	}{
+		{
+			name: "success: changes applied incrementally",
+			setup: func(t *testing.T, _ afero.Fs, s stores) {
+				assert.NoError(t, s.repository.VMs.Put([]byte(spacesVM), storage.Definition[types.VM]{Commit: previousCommit}))
+				assert.NoError(t, s.repository.VMs.Put([]byte("unchangedvm"), staleVM))
+				assert.NoError(t, s.repository.VMs.Put([]byte("removedvm"), staleVM))
+				assert.NoError(t, s.registry.VMs.Put([]byte(spacesVM), storage.RepoList{Repositories: []string{alias}}))
+				assert.NoError(t, s.registry.VMs.Put([]byte("unchangedvm"), storage.RepoList{Repositories: []string{alias}}))
+				assert.NoError(t, s.registry.VMs.Put([]byte("removedvm"), storage.RepoList{Repositories: []string{"organization/other", alias}}))
+			},
+			changes: []git.Change{
+				{From: "vms/vm-1.yaml", To: "vms/vm-1.yaml", Before: vm, After: vm},
+				{From: "vms/removedvm.yaml", Before: removedVM},
+				{From: "subnets/old.yaml", To: "subnets/subnet-1.yaml", Before: subnet, After: subnet},
+				{To: "vms/.hidden", After: []byte("garbage")},
+			},
+			check: func(t *testing.T, s stores) {
+				definition, err := s.repository.VMs.Get([]byte(spacesVM))
+				assert.NoError(t, err)
+				assert.Equal(t, latestCommit, definition.Commit)
+				assert.Equal(t, spacesVM, definition.Definition.GetAlias())
+
+				// Definitions that didn't change aren't rewritten.
+				definition, err = s.repository.VMs.Get([]byte("unchangedvm"))
+				assert.NoError(t, err)
+				assert.Equal(t, staleVM, definition)
+
+				ok, err := s.repository.VMs.Has([]byte("removedvm"))
+				assert.NoError(t, err)
+				assert.False(t, ok)
+				repoList, err := s.registry.VMs.Get([]byte("removedvm"))
+				assert.NoError(t, err)
+				assert.Equal(t, storage.RepoList{Repositories: []string{"organization/other"}}, repoList)
+
+				subnetDefinition, err := s.repository.Subnets.Get([]byte(spacesSubnet))
+				assert.NoError(t, err)
+				assert.Equal(t, latestCommit, subnetDefinition.Commit)
+				repoList, err = s.registry.Subnets.Get([]byte(spacesSubnet))
+				assert.NoError(t, err)
+				assert.Equal(t, storage.RepoList{Repositories: []string{alias}}, repoList)
+
+				source, err := s.sourcesList.Get(aliasBytes)
+				assert.NoError(t, err)
+				assert.Equal(t, latestCommit, source.Commit)
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "failure: malformed changed file",
+			setup: func(t *testing.T, _ afero.Fs, s stores) {
+				assert.NoError(t, s.sourcesList.Put(aliasBytes, sourceInfo))
+			},
+			changes: []git.Change{
+				{From: "vms/vm-1.yaml", To: "vms/vm-1.yaml", Before: vm, After: []byte("garbage")},
+			},
+			check: func(t *testing.T, s stores) {
+				source, err := s.sourcesList.Get(aliasBytes)
+				assert.NoError(t, err)
+				assert.Equal(t, previousCommit, source.Commit)
+			},
+			wantErr: assert.Error,
+		},
 		{
 			name: "success: vm definitions updated",
-			setup: func(t *testing.T, mocks mocks) {
-				setupFs(mocks.fs)
-				assert.Nil(t, afero.WriteFile(mocks.fs, filepath.Join(vmsPath, "vm-1.yaml"), vm, perms.ReadWrite))
-
-				// upgrade subnet definitions
-				mocks.registry.EXPECT().Get([]byte(spacesVM)).Return(storage.RepoList{Repositories: []string{}}, nil)
-				mocks.registry.EXPECT().Put([]byte(spacesVM), storage.RepoList{Repositories: []string{alias}}).Return(nil)
-				mocks.vms.EXPECT().Put([]byte(spacesVM), gomock.Any()).Return(nil) // TODO fix
-
-				mocks.vms.EXPECT().Iterator().DoAndReturn(func() storage.Iterator[storage.Definition[types.VM]] {
-					itr := mockdb.NewMockIterator(mocks.ctrl)
-					defer itr.EXPECT().Release()
-					itr.EXPECT().Next().Return(false)
-
-					return *storage.NewIterator[storage.Definition[types.VM]](itr)
-				})
-				mocks.subnets.EXPECT().Iterator().DoAndReturn(func() storage.Iterator[storage.Definition[types.Subnet]] {
-					itr := mockdb.NewMockIterator(mocks.ctrl)
-					defer itr.EXPECT().Release()
-					itr.EXPECT().Next().Return(false)
-
-					return *storage.NewIterator[storage.Definition[types.Subnet]](itr)
-				})
-				mocks.sourcesList.EXPECT().Put([]byte(alias), storage.SourceInfo{
-					Alias:  alias,
-					URL:    url,
-					Branch: branch,
-					Commit: latestCommit,
-				})
+			setup: func(t *testing.T, fs afero.Fs, _ stores) {
+				setupFs(fs)
+				assert.Nil(t, afero.WriteFile(fs, filepath.Join(vmsPath, "vm-1.yaml"), vm, perms.ReadWrite))
+			},
+			check: func(t *testing.T, s stores) {
+				repoList, err := s.registry.VMs.Get([]byte(spacesVM))
+				assert.NoError(t, err)
+				assert.Equal(t, storage.RepoList{Repositories: []string{alias}}, repoList)
+
+				definition, err := s.repository.VMs.Get([]byte(spacesVM))
+				assert.NoError(t, err)
+				assert.Equal(t, latestCommit, definition.Commit)
+				assert.Equal(t, spacesVM, definition.Definition.GetAlias())
+
+				source, err := s.sourcesList.Get(aliasBytes)
+				assert.NoError(t, err)
+				assert.Equal(t, latestCommit, source.Commit)
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "success: repository without history is rescanned",
+			setup: func(t *testing.T, fs afero.Fs, s stores) {
+				// The subnets directory is missing.
+				assert.NoError(t, fs.MkdirAll(vmsPath, perms.ReadWriteExecute))
+				assert.Nil(t, afero.WriteFile(fs, filepath.Join(vmsPath, "vm-1.yaml"), vm, perms.ReadWrite))
+				assert.NoError(t, s.repository.VMs.Put([]byte("stalevm"), staleVM))
 			},
-			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.NoError(t, err)
+			noHistory: true,
+			check: func(t *testing.T, s stores) {
+				definition, err := s.repository.VMs.Get([]byte(spacesVM))
+				assert.NoError(t, err)
+				assert.Equal(t, latestCommit, definition.Commit)
+
+				ok, err := s.repository.VMs.Has([]byte("stalevm"))
+				assert.NoError(t, err)
+				assert.False(t, ok)
 			},
+			wantErr: assert.NoError,
 		},
 		{
 			name: "success: subnet definitions updated",
-			setup: func(t *testing.T, mocks mocks) {
-				setupFs(mocks.fs)
-				assert.Nil(t, afero.WriteFile(mocks.fs, filepath.Join(subnetsPath, "subnet-1.yaml"), subnet, perms.ReadWrite))
-
-				// upgrade subnet definitions
-				mocks.registry.EXPECT().Get([]byte(spacesSubnet)).Return(storage.RepoList{Repositories: []string{}}, nil)
-				mocks.registry.EXPECT().Put([]byte(spacesSubnet), storage.RepoList{Repositories: []string{alias}}).Return(nil)
-				mocks.subnets.EXPECT().Put([]byte(spacesSubnet), gomock.Any()).Return(nil) // TODO fix
-
-				mocks.vms.EXPECT().Iterator().DoAndReturn(func() storage.Iterator[storage.Definition[types.VM]] {
-					itr := mockdb.NewMockIterator(mocks.ctrl)
-					defer itr.EXPECT().Release()
-					itr.EXPECT().Next().Return(false)
-
-					return *storage.NewIterator[storage.Definition[types.VM]](itr)
-				})
-				mocks.subnets.EXPECT().Iterator().DoAndReturn(func() storage.Iterator[storage.Definition[types.Subnet]] {
-					itr := mockdb.NewMockIterator(mocks.ctrl)
-					defer itr.EXPECT().Release()
-					itr.EXPECT().Next().Return(false)
-
-					return *storage.NewIterator[storage.Definition[types.Subnet]](itr)
-				})
-				mocks.sourcesList.EXPECT().Put([]byte(alias), storage.SourceInfo{
-					Alias:  alias,
-					URL:    url,
-					Branch: branch,
-					Commit: latestCommit,
-				})
+			setup: func(t *testing.T, fs afero.Fs, _ stores) {
+				setupFs(fs)
+				assert.Nil(t, afero.WriteFile(fs, filepath.Join(subnetsPath, "subnet-1.yaml"), subnet, perms.ReadWrite))
 			},
-			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
-				return assert.NoError(t, err)
+			check: func(t *testing.T, s stores) {
+				repoList, err := s.registry.Subnets.Get([]byte(spacesSubnet))
+				assert.NoError(t, err)
+				assert.Equal(t, storage.RepoList{Repositories: []string{alias}}, repoList)
+
+				definition, err := s.repository.Subnets.Get([]byte(spacesSubnet))
+				assert.NoError(t, err)
+				assert.Equal(t, latestCommit, definition.Commit)
+
+				source, err := s.sourcesList.Get(aliasBytes)
+				assert.NoError(t, err)
+				assert.Equal(t, latestCommit, source.Commit)
 			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "success: stale definitions deleted",
+			setup: func(t *testing.T, fs afero.Fs, s stores) {
+				setupFs(fs)
+				assert.Nil(t, afero.WriteFile(fs, filepath.Join(vmsPath, "vm-1.yaml"), vm, perms.ReadWrite))
+
+				assert.NoError(t, s.repository.VMs.Put([]byte(spacesVM), storage.Definition[types.VM]{Commit: previousCommit}))
+				assert.NoError(t, s.repository.VMs.Put([]byte("stalevm"), staleVM))
+				assert.NoError(t, s.repository.VMs.Put([]byte("removedvm"), staleVM))
+				assert.NoError(t, s.registry.VMs.Put([]byte(spacesVM), storage.RepoList{Repositories: []string{alias}}))
+				assert.NoError(t, s.registry.VMs.Put([]byte("stalevm"), storage.RepoList{Repositories: []string{"organization/other", alias}}))
+				assert.NoError(t, s.registry.VMs.Put([]byte("removedvm"), storage.RepoList{Repositories: []string{alias}}))
+			},
+			check: func(t *testing.T, s stores) {
+				definition, err := s.repository.VMs.Get([]byte(spacesVM))
+				assert.NoError(t, err)
+				assert.Equal(t, latestCommit, definition.Commit)
+
+				ok, err := s.repository.VMs.Has([]byte("stalevm"))
+				assert.NoError(t, err)
+				assert.False(t, ok)
+
+				repoList, err := s.registry.VMs.Get([]byte(spacesVM))
+				assert.NoError(t, err)
+				assert.Equal(t, storage.RepoList{Repositories: []string{alias}}, repoList)
+
+				repoList, err = s.registry.VMs.Get([]byte("stalevm"))
+				assert.NoError(t, err)
+				assert.Equal(t, storage.RepoList{Repositories: []string{"organization/other"}}, repoList)
+
+				ok, err = s.registry.VMs.Has([]byte("removedvm"))
+				assert.NoError(t, err)
+				assert.False(t, ok)
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "success: stale vm sharing an alias with a subnet",
+			setup: func(t *testing.T, fs afero.Fs, s stores) {
+				setupFs(fs)
+				assert.Nil(t, afero.WriteFile(fs, filepath.Join(subnetsPath, "subnet-1.yaml"), subnet, perms.ReadWrite))
+
+				assert.NoError(t, s.repository.VMs.Put([]byte(spacesSubnet), staleVM))
+				assert.NoError(t, s.registry.VMs.Put([]byte(spacesSubnet), storage.RepoList{Repositories: []string{alias}}))
+			},
+			check: func(t *testing.T, s stores) {
+				ok, err := s.repository.VMs.Has([]byte(spacesSubnet))
+				assert.NoError(t, err)
+				assert.False(t, ok)
+
+				ok, err = s.registry.VMs.Has([]byte(spacesSubnet))
+				assert.NoError(t, err)
+				assert.False(t, ok)
+
+				repoList, err := s.registry.Subnets.Get([]byte(spacesSubnet))
+				assert.NoError(t, err)
+				assert.Equal(t, storage.RepoList{Repositories: []string{alias}}, repoList)
+			},
+			wantErr: assert.NoError,
+		},
+		{
+			name: "failure: nothing is written",
+			setup: func(t *testing.T, fs afero.Fs, s stores) {
+				setupFs(fs)
+				assert.Nil(t, afero.WriteFile(fs, filepath.Join(vmsPath, "vm-1.yaml"), vm, perms.ReadWrite))
+				assert.Nil(t, afero.WriteFile(fs, filepath.Join(subnetsPath, "subnet-1.yaml"), []byte("garbage"), perms.ReadWrite))
+
+				assert.NoError(t, s.repository.VMs.Put([]byte("stalevm"), staleVM))
+				assert.NoError(t, s.sourcesList.Put(aliasBytes, sourceInfo))
+			},
+			check: func(t *testing.T, s stores) {
+				ok, err := s.registry.VMs.Has([]byte(spacesVM))
+				assert.NoError(t, err)
+				assert.False(t, ok)
+
+				ok, err = s.repository.VMs.Has([]byte(spacesVM))
+				assert.NoError(t, err)
+				assert.False(t, ok)
+
+				definition, err := s.repository.VMs.Get([]byte("stalevm"))
+				assert.NoError(t, err)
+				assert.Equal(t, staleVM, definition)
+
+				source, err := s.sourcesList.Get(aliasBytes)
+				assert.NoError(t, err)
+				assert.Equal(t, previousCommit, source.Commit)
+			},
+			wantErr: assert.Error,
 		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
-			ctrl := gomock.NewController(t)
 			fs := afero.NewMemMapFs()
-
-			var (
-				registry    *storage.MockStorage[storage.RepoList]
-				sourcesList *storage.MockStorage[storage.SourceInfo]
-				vms         *storage.MockStorage[storage.Definition[types.VM]]
-				subnets     *storage.MockStorage[storage.Definition[types.Subnet]]
-			)
-
-			registry = storage.NewMockStorage[storage.RepoList](ctrl)
-			sourcesList = storage.NewMockStorage[storage.SourceInfo](ctrl)
-			vms = storage.NewMockStorage[storage.Definition[types.VM]](ctrl)
-			subnets = storage.NewMockStorage[storage.Definition[types.Subnet]](ctrl)
-
-			repository := storage.Repository{
-				VMs:     vms,
-				Subnets: subnets,
+			db := memdb.New()
+			s := stores{
+				registry:    storage.NewRegistry(db),
+				sourcesList: storage.NewSourceInfo(db),
+				repository:  storage.NewRepositoryFactory(db).GetRepository(aliasBytes),
 			}
 
-			test.setup(t, mocks{
-				ctrl:        ctrl,
-				fs:          fs,
-				registry:    registry,
-				sourcesList: sourcesList,
-				vms:         vms,
-				subnets:     subnets,
-			})
+			test.setup(t, fs, s)
+
+			var differ git.Differ
+			if !test.noHistory {
+				mockDiffer := git.NewMockDiffer(gomock.NewController(t))
+				diff := mockDiffer.EXPECT().Diff(repositoryPath, previousCommit, latestCommit, []string{vmDir, subnetDir})
+				if test.changes == nil {
+					diff.Return(nil, git.ErrCommitNotFound)
+				} else {
+					diff.Return(test.changes, nil)
+				}
+				differ = mockDiffer
+			}
 
 			wf := NewUpdateRepository(
 				UpdateRepositoryConfig{
@@ -243,14 +391,16 @@ func TestUpdateRepositoryExecute(t *testing.T) {
 					PreviousCommit: previousCommit,
 					LatestCommit:   latestCommit,
 					SourceInfo:     sourceInfo,
-					Repository:     repository,
-					Registry:       registry,
-					SourcesList:    sourcesList,
+					Repository:     s.repository,
+					Registry:       s.registry,
+					SourcesList:    s.sourcesList,
+					Differ:         differ,
 					Fs:             fs,
 				},
 			)
 
 			test.wantErr(t, wf.Execute())
+			test.check(t, s)
 		})
 	}
 }
@@ -9,13 +9,13 @@ import (
 	"testing"
 
 	"github.com/go-git/go-git/v5/plumbing"
-	"github.com/go-git/go-git/v5/plumbing/transport/http"
 	"github.com/spf13/afero"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/mock/gomock"
 	"gopkg.in/yaml.v3"
 
 	"github.com/DioneProtocol/opm/git"
+	"github.com/DioneProtocol/opm/source"
 	"github.com/DioneProtocol/opm/storage"
 	mockdb "github.com/DioneProtocol/opm/storage/mocks"
 )
@@ -29,6 +29,7 @@ func TestUpdateExecute(t *testing.T) {
 		tmpPath          = "tmpPath"
 		pluginPath       = "pluginPath"
 		repositoriesPath = "repositoriesPath"
+		localPath        = "/plugins"
 	)
 
 	var (
@@ -39,18 +40,15 @@ func TestUpdateExecute(t *testing.T) {
 		repoInstallPath = filepath.Join(repositoriesPath, organization, repo)
 		repository      = storage.Repository{}
 
-		auth = http.BasicAuth{
-			Username: "username",
-			Password: "password",
-		}
-
 		branch = plumbing.NewBranchReferenceName("branch")
 
 		sourceInfo = storage.SourceInfo{
-			Alias:  alias,
-			URL:    url,
-			Branch: branch,
-			Commit: previousCommit,
+			Alias:         alias,
+			URL:           url,
+			Branch:        branch,
+			Commit:        previousCommit,
+			ReferenceType: storage.BranchReference,
+			Source:        storage.GitSource,
 		}
 
 		fs = afero.NewMemMapFs()
@@ -61,22 +59,43 @@ func TestUpdateExecute(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	localSourceInfo := storage.SourceInfo{
+		Alias:  alias,
+		URL:    localPath,
+		Commit: previousCommit,
+		Source: storage.LocalSource,
+	}
+	localSourceInfoBytes, err := yaml.Marshal(localSourceInfo)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	httpSourceInfoBytes, err := yaml.Marshal(storage.SourceInfo{
+		Alias:  alias,
+		URL:    url,
+		Source: storage.HTTPSource,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	garbageBytes := []byte("garbage")
 
 	type mocks struct {
 		ctrl         *gomock.Controller
 		executor     *MockExecutor
-		registry     *storage.MockStorage[storage.RepoList]
+		registry     storage.Registry
 		installedVMs *storage.MockStorage[storage.InstallInfo]
 		sourcesList  *storage.MockStorage[storage.SourceInfo]
 		db           *mockdb.MockDatabase
 		installer    *MockInstaller
-		gitFactory   *git.MockFactory
+		source       *source.MockSource
+		differ       *git.MockDiffer
 		repoFactory  *storage.MockRepositoryFactory
-		auth         http.BasicAuth
 	}
 	tests := []struct {
 		name    string
+		aliases []string
 		setup   func(mocks)
 		wantErr assert.ErrorAssertionFunc
 	}{
@@ -116,7 +135,7 @@ func TestUpdateExecute(t *testing.T) {
 					return *storage.NewIterator[storage.SourceInfo](itr)
 				})
 
-				mocks.gitFactory.EXPECT().GetRepository(url, repoInstallPath, branch, &mocks.auth).Return(plumbing.ZeroHash, errWrong)
+				mocks.source.EXPECT().Sync(sourceInfo, repoInstallPath).Return("", plumbing.ZeroHash, errWrong)
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 				return assert.Equal(t, errWrong, err)
@@ -148,10 +167,11 @@ func TestUpdateExecute(t *testing.T) {
 					Registry:       mocks.registry,
 					SourceInfo:     sourceInfo,
 					SourcesList:    mocks.sourcesList,
+					Differ:         mocks.differ,
 					Fs:             fs,
 				})
 
-				mocks.gitFactory.EXPECT().GetRepository(url, repoInstallPath, branch, &mocks.auth).Return(latestCommit, nil)
+				mocks.source.EXPECT().Sync(sourceInfo, repoInstallPath).Return(repoInstallPath, latestCommit, nil)
 				mocks.repoFactory.EXPECT().GetRepository([]byte(alias)).Return(repository)
 				mocks.executor.EXPECT().Execute(wf).Return(errWrong)
 			},
@@ -176,7 +196,7 @@ func TestUpdateExecute(t *testing.T) {
 					return *storage.NewIterator[storage.SourceInfo](itr)
 				})
 
-				mocks.gitFactory.EXPECT().GetRepository(url, repoInstallPath, branch, &mocks.auth).Return(previousCommit, nil)
+				mocks.source.EXPECT().Sync(sourceInfo, repoInstallPath).Return(repoInstallPath, previousCommit, nil)
 			},
 			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
 				return assert.NoError(t, err)
@@ -209,10 +229,49 @@ func TestUpdateExecute(t *testing.T) {
 					Registry:       mocks.registry,
 					SourceInfo:     sourceInfo,
 					SourcesList:    mocks.sourcesList,
+					Differ:         mocks.differ,
+					Fs:             fs,
+				})
+
+				mocks.source.EXPECT().Sync(sourceInfo, repoInstallPath).Return(repoInstallPath, latestCommit, nil)
+				mocks.repoFactory.EXPECT().GetRepository([]byte(alias)).Return(repository)
+				mocks.executor.EXPECT().Execute(wf).Return(nil)
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.NoError(t, err)
+			},
+		},
+		{
+			name: "local repository is rescanned in place",
+			setup: func(mocks mocks) {
+				// iterator with only one key/value pair
+				mocks.sourcesList.EXPECT().Iterator().DoAndReturn(func() storage.Iterator[storage.SourceInfo] {
+					itr := mockdb.NewMockIterator(mocks.ctrl)
+					defer itr.EXPECT().Release()
+
+					itr.EXPECT().Next().Return(true)
+					itr.EXPECT().Key().Return([]byte(alias))
+
+					itr.EXPECT().Value().Return(localSourceInfoBytes)
+					itr.EXPECT().Next().Return(false)
+
+					return *storage.NewIterator[storage.SourceInfo](itr)
+				})
+
+				wf := NewUpdateRepository(UpdateRepositoryConfig{
+					RepoName:       repo,
+					RepositoryPath: localPath,
+					AliasBytes:     []byte(alias),
+					PreviousCommit: previousCommit,
+					LatestCommit:   latestCommit,
+					Repository:     repository,
+					Registry:       mocks.registry,
+					SourceInfo:     localSourceInfo,
+					SourcesList:    mocks.sourcesList,
 					Fs:             fs,
 				})
 
-				mocks.gitFactory.EXPECT().GetRepository(url, repoInstallPath, branch, &mocks.auth).Return(latestCommit, nil)
+				mocks.source.EXPECT().Sync(localSourceInfo, repoInstallPath).Return(localPath, latestCommit, nil)
 				mocks.repoFactory.EXPECT().GetRepository([]byte(alias)).Return(repository)
 				mocks.executor.EXPECT().Execute(wf).Return(nil)
 			},
@@ -220,23 +279,66 @@ func TestUpdateExecute(t *testing.T) {
 				return assert.NoError(t, err)
 			},
 		},
+		{
+			name:    "repositories that aren't included are skipped",
+			aliases: []string{"organization/other"},
+			setup: func(mocks mocks) {
+				// iterator with only one key/value pair
+				mocks.sourcesList.EXPECT().Iterator().DoAndReturn(func() storage.Iterator[storage.SourceInfo] {
+					itr := mockdb.NewMockIterator(mocks.ctrl)
+					defer itr.EXPECT().Release()
+
+					itr.EXPECT().Next().Return(true)
+					itr.EXPECT().Key().Return([]byte(alias))
+					itr.EXPECT().Next().Return(false)
+
+					return *storage.NewIterator[storage.SourceInfo](itr)
+				})
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.NoError(t, err)
+			},
+		},
+		{
+			name: "unknown source",
+			setup: func(mocks mocks) {
+				// iterator with only one key/value pair
+				mocks.sourcesList.EXPECT().Iterator().DoAndReturn(func() storage.Iterator[storage.SourceInfo] {
+					itr := mockdb.NewMockIterator(mocks.ctrl)
+					defer itr.EXPECT().Release()
+
+					itr.EXPECT().Next().Return(true)
+					itr.EXPECT().Key().Return([]byte(alias))
+
+					itr.EXPECT().Value().Return(httpSourceInfoBytes)
+
+					return *storage.NewIterator[storage.SourceInfo](itr)
+				})
+			},
+			wantErr: func(t assert.TestingT, err error, i ...interface{}) bool {
+				return assert.Error(t, err)
+			},
+		},
 	}
 
 	for _, test := range tests {
 		t.Run(test.name, func(t *testing.T) {
 			ctrl := gomock.NewController(t)
 
-			var registry *storage.MockStorage[storage.RepoList]
 			var installedVMs *storage.MockStorage[storage.InstallInfo]
 			var sourcesList *storage.MockStorage[storage.SourceInfo]
 
 			executor := NewMockExecutor(ctrl)
 			db := mockdb.NewMockDatabase(ctrl)
 			installer := NewMockInstaller(ctrl)
-			gitFactory := git.NewMockFactory(ctrl)
+			mockSource := source.NewMockSource(ctrl)
+			differ := git.NewMockDiffer(ctrl)
 			repoFactory := storage.NewMockRepositoryFactory(ctrl)
 
-			registry = storage.NewMockStorage[storage.RepoList](ctrl)
+			registry := storage.Registry{
+				VMs:     storage.NewMockStorage[storage.RepoList](ctrl),
+				Subnets: storage.NewMockStorage[storage.RepoList](ctrl),
+			}
 			installedVMs = storage.NewMockStorage[storage.InstallInfo](ctrl)
 			sourcesList = storage.NewMockStorage[storage.SourceInfo](ctrl)
 
@@ -248,8 +350,8 @@ func TestUpdateExecute(t *testing.T) {
 				sourcesList:  sourcesList,
 				db:           db,
 				installer:    installer,
-				gitFactory:   gitFactory,
-				auth:         auth,
+				source:       mockSource,
+				differ:       differ,
 				repoFactory:  repoFactory,
 			})
 
@@ -264,10 +366,11 @@ func TestUpdateExecute(t *testing.T) {
 					PluginPath:       pluginPath,
 					Installer:        installer,
 					RepositoriesPath: repositoriesPath,
-					Auth:             auth,
-					GitFactory:       gitFactory,
+					Sources:          map[storage.SourceType]source.Source{storage.GitSource: mockSource, storage.LocalSource: mockSource},
+					Differ:           differ,
 					RepoFactory:      repoFactory,
 					Fs:               fs,
+					Aliases:          test.aliases,
 				},
 			)
 			test.wantErr(t, wf.Execute())
@@ -8,34 +8,43 @@ import (
 
 	"github.com/spf13/afero"
 
+	"github.com/DioneProtocol/opm/node"
 	"github.com/DioneProtocol/opm/storage"
 )
 
 type UpgradeConfig struct {
 	Executor Executor
 
-	RepoFactory  storage.RepositoryFactory
-	Registry     storage.Storage[storage.RepoList]
-	SourcesList  storage.Storage[storage.SourceInfo]
-	InstalledVMs storage.Storage[storage.InstallInfo]
+	RepoFactory   storage.RepositoryFactory
+	Registry      storage.Storage[storage.RepoList]
+	SourcesList   storage.Storage[storage.SourceInfo]
+	InstalledVMs  storage.Storage[storage.InstallInfo]
+	ConfigFiles   storage.Storage[storage.ConfigFile]
+	JoinedSubnets storage.Storage[storage.JoinInfo]
 
 	TmpPath    string
 	PluginPath string
+	ConfigDir  *node.ConfigDir
 	Installer  Installer
+	Checker    CompatibilityChecker
 	Fs         afero.Fs
 }
 
 func NewUpgrade(config UpgradeConfig) *Upgrade {
 	return &Upgrade{
-		executor:     config.Executor,
-		repoFactory:  config.RepoFactory,
-		registry:     config.Registry,
-		installedVMs: config.InstalledVMs,
-		tmpPath:      config.TmpPath,
-		pluginPath:   config.PluginPath,
-		installer:    config.Installer,
-		sourcesList:  config.SourcesList,
-		fs:           config.Fs,
+		executor:      config.Executor,
+		repoFactory:   config.RepoFactory,
+		registry:      config.Registry,
+		installedVMs:  config.InstalledVMs,
+		configFiles:   config.ConfigFiles,
+		joinedSubnets: config.JoinedSubnets,
+		tmpPath:       config.TmpPath,
+		pluginPath:    config.PluginPath,
+		configDir:     config.ConfigDir,
+		installer:     config.Installer,
+		checker:       config.Checker,
+		sourcesList:   config.SourcesList,
+		fs:            config.Fs,
 	}
 }
 
@@ -45,13 +54,17 @@ type Upgrade struct {
 	repoFactory storage.RepositoryFactory
 	registry    storage.Storage[storage.RepoList]
 
-	installedVMs storage.Storage[storage.InstallInfo]
-	sourcesList  storage.Storage[storage.SourceInfo]
+	installedVMs  storage.Storage[storage.InstallInfo]
+	sourcesList   storage.Storage[storage.SourceInfo]
+	configFiles   storage.Storage[storage.ConfigFile]
+	joinedSubnets storage.Storage[storage.JoinInfo]
 
 	tmpPath    string
 	pluginPath string
+	configDir  *node.ConfigDir
 
 	installer Installer
+	checker   CompatibilityChecker
 	fs        afero.Fs
 }
 
@@ -63,14 +76,18 @@ func (u *Upgrade) Execute() error {
 
 	for itr.Next() {
 		wf := NewUpgradeVM(UpgradeVMConfig{
-			Executor:     u.executor,
-			RepoFactory:  u.repoFactory,
-			FullVMName:   string(itr.Key()),
-			InstalledVMs: u.installedVMs,
-			TmpPath:      u.tmpPath,
-			PluginPath:   u.pluginPath,
-			Installer:    u.installer,
-			Fs:           u.fs,
+			Executor:      u.executor,
+			RepoFactory:   u.repoFactory,
+			FullVMName:    string(itr.Key()),
+			InstalledVMs:  u.installedVMs,
+			ConfigFiles:   u.configFiles,
+			JoinedSubnets: u.joinedSubnets,
+			TmpPath:       u.tmpPath,
+			PluginPath:    u.pluginPath,
+			ConfigDir:     u.configDir,
+			Installer:     u.installer,
+			Checker:       u.checker,
+			Fs:            u.fs,
 		})
 
 		err := u.executor.Execute(wf)
@@ -81,6 +98,16 @@ func (u *Upgrade) Execute() error {
 		}
 	}
 
+	if err := itr.Error(); err != nil {
+		return err
+	}
+
+	// The chain configs of joined subnets follow their latest definitions, the
+	// same way as the chain configs of upgraded VMs.
+	if err := u.upgradeSubnetChainConfigs(); err != nil {
+		return err
+	}
+
 	if !upgraded {
 		fmt.Printf("No changes detected.\n")
 		return nil
@@ -88,3 +115,26 @@ func (u *Upgrade) Execute() error {
 
 	return nil
 }
+
+// upgradeSubnetChainConfigs installs the chain configs of every joined subnet
+// from its synced definition.
+func (u *Upgrade) upgradeSubnetChainConfigs() error {
+	subnets, err := joinedSubnetDefinitions(u.joinedSubnets, u.repoFactory)
+	if err != nil {
+		return err
+	}
+
+	for _, subnet := range subnets {
+		if err := u.executor.Execute(NewInstallChainConfigs(InstallChainConfigsConfig{
+			Owner:        subnet.name,
+			ChainConfigs: subnet.definition.ChainConfigs,
+			ConfigDir:    u.configDir,
+			ConfigFiles:  u.configFiles,
+			Fs:           u.fs,
+		})); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
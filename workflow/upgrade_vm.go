@@ -10,6 +10,7 @@ import (
 	"github.com/DioneProtocol/odysseygo/database"
 	"github.com/spf13/afero"
 
+	"github.com/DioneProtocol/opm/node"
 	"github.com/DioneProtocol/opm/storage"
 	"github.com/DioneProtocol/opm/types"
 	"github.com/DioneProtocol/opm/util"
@@ -20,26 +21,39 @@ var ErrAlreadyUpdated = errors.New("already up-to-date")
 type UpgradeVMConfig struct {
 	Executor Executor
 
-	FullVMName   string
-	RepoFactory  storage.RepositoryFactory
-	InstalledVMs storage.Storage[storage.InstallInfo]
+	FullVMName    string
+	RepoFactory   storage.RepositoryFactory
+	InstalledVMs  storage.Storage[storage.InstallInfo]
+	ConfigFiles   storage.Storage[storage.ConfigFile]
+	JoinedSubnets storage.Storage[storage.JoinInfo]
 
 	TmpPath    string
 	PluginPath string
+	ConfigDir  *node.ConfigDir
 	Installer  Installer
+	Checker    CompatibilityChecker
 	Fs         afero.Fs
+
+	// Reinstall reinstalls the VM whenever its definition changed the
+	// artifact, even if the version wasn't bumped.
+	Reinstall bool
 }
 
 func NewUpgradeVM(config UpgradeVMConfig) *UpgradeVM {
 	return &UpgradeVM{
-		executor:     config.Executor,
-		fullVMName:   config.FullVMName,
-		repoFactory:  config.RepoFactory,
-		installedVMs: config.InstalledVMs,
-		tmpPath:      config.TmpPath,
-		pluginPath:   config.PluginPath,
-		installer:    config.Installer,
-		fs:           config.Fs,
+		executor:      config.Executor,
+		fullVMName:    config.FullVMName,
+		repoFactory:   config.RepoFactory,
+		installedVMs:  config.InstalledVMs,
+		configFiles:   config.ConfigFiles,
+		joinedSubnets: config.JoinedSubnets,
+		tmpPath:       config.TmpPath,
+		pluginPath:    config.PluginPath,
+		configDir:     config.ConfigDir,
+		installer:     config.Installer,
+		checker:       config.Checker,
+		fs:            config.Fs,
+		reinstall:     config.Reinstall,
 	}
 }
 
@@ -49,13 +63,18 @@ type UpgradeVM struct {
 
 	repoFactory storage.RepositoryFactory
 
-	installedVMs storage.Storage[storage.InstallInfo]
+	installedVMs  storage.Storage[storage.InstallInfo]
+	configFiles   storage.Storage[storage.ConfigFile]
+	joinedSubnets storage.Storage[storage.JoinInfo]
 
 	tmpPath    string
 	pluginPath string
+	configDir  *node.ConfigDir
 
 	installer Installer
+	checker   CompatibilityChecker
 	fs        afero.Fs
+	reinstall bool
 }
 
 func (u *UpgradeVM) Execute() error {
@@ -81,17 +100,23 @@ func (u *UpgradeVM) Execute() error {
 
 	upgradedVM := definition.Definition
 
-	if installInfo.Version.Compare(&upgradedVM.Version) < 0 {
-		fmt.Printf(
-			"Detected an upgrade for %s from v%v.%v.%v to v%v.%v.%v.\n",
-			u.fullVMName,
-			installInfo.Version.Major,
-			installInfo.Version.Minor,
-			installInfo.Version.Patch,
-			upgradedVM.Version.Major,
-			upgradedVM.Version.Minor,
-			upgradedVM.Version.Patch,
-		)
+	upgrade := installInfo.Version.Compare(&upgradedVM.Version) < 0
+	reinstall := u.reinstall && !installInfo.Installs(upgradedVM)
+	if upgrade || reinstall {
+		if upgrade {
+			fmt.Printf(
+				"Detected an upgrade for %s from v%v.%v.%v to v%v.%v.%v.\n",
+				u.fullVMName,
+				installInfo.Version.Major,
+				installInfo.Version.Minor,
+				installInfo.Version.Patch,
+				upgradedVM.Version.Major,
+				upgradedVM.Version.Minor,
+				upgradedVM.Version.Patch,
+			)
+		} else {
+			fmt.Printf("Detected a change to the artifact of %s.\n", u.fullVMName)
+		}
 		installWorkflow := NewInstall(InstallConfig{
 			Name:         u.fullVMName,
 			Plugin:       vmName,
@@ -102,6 +127,7 @@ func (u *UpgradeVM) Execute() error {
 			InstalledVMs: u.installedVMs,
 			VMStorage:    repository.VMs,
 			Installer:    u.installer,
+			Checker:      u.checker,
 			Fs:           u.fs,
 		})
 
@@ -115,7 +141,44 @@ func (u *UpgradeVM) Execute() error {
 		if err := u.executor.Execute(installWorkflow); err != nil {
 			return err
 		}
+
+		// Chain configs are only installed for VMs of joined subnets, which
+		// may start defining them in a later version.
+		chainConfigs, joined, err := u.chainConfigs(upgradedVM)
+		if err != nil {
+			return err
+		}
+		if joined {
+			fmt.Printf("Updating chain configs for %s...\n", u.fullVMName)
+			if err := u.executor.Execute(NewInstallChainConfigs(InstallChainConfigsConfig{
+				Owner:        u.fullVMName,
+				ChainConfigs: chainConfigs,
+				ConfigDir:    u.configDir,
+				ConfigFiles:  u.configFiles,
+				Fs:           u.fs,
+			})); err != nil {
+				return err
+			}
+		}
 	}
 
 	return ErrAlreadyUpdated
 }
+
+// chainConfigs returns the chain configs of [vm] that aren't overridden by a
+// joined subnet running it, and whether any joined subnet runs it.
+func (u *UpgradeVM) chainConfigs(vm types.VM) ([]types.ChainConfig, bool, error) {
+	joined, err := joinedSubnetDefinitions(u.joinedSubnets, u.repoFactory)
+	if err != nil {
+		return nil, false, err
+	}
+
+	subnets := []types.Subnet{}
+	for _, subnet := range joined {
+		if subnet.includes(u.fullVMName) {
+			subnets = append(subnets, subnet.definition)
+		}
+	}
+
+	return VMChainConfigs(vm, subnets...), len(subnets) > 0, nil
+}
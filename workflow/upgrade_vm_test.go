@@ -0,0 +1,48 @@
+// Copyright (C) 2019-2021, Ava Labs, Inc. All rights reserved.
+// See the file LICENSE for licensing terms.
+
+package workflow
+
+import (
+	"testing"
+
+	"github.com/DioneProtocol/odysseygo/database/memdb"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/DioneProtocol/opm/storage"
+	"github.com/DioneProtocol/opm/types"
+)
+
+func TestUpgradeVMChainConfigs(t *testing.T) {
+	const repoAlias = "organization/repository"
+
+	db := memdb.New()
+	joinedSubnets := storage.NewJoinedSubnets(db)
+	repoFactory := storage.NewRepositoryFactory(db)
+
+	upgrade := NewUpgradeVM(UpgradeVMConfig{
+		FullVMName:    repoAlias + ":spacesvm",
+		RepoFactory:   repoFactory,
+		ConfigFiles:   storage.NewConfigFiles(db),
+		JoinedSubnets: joinedSubnets,
+	})
+
+	// The upgraded version is the first one to define chain configs.
+	vm := types.VM{
+		ChainConfigs: []types.ChainConfig{{Chain: "spaces", Config: `{"a": 1}`}},
+	}
+
+	chainConfigs, joined, err := upgrade.chainConfigs(vm)
+	assert.NoError(t, err)
+	assert.False(t, joined)
+	assert.Equal(t, vm.ChainConfigs, chainConfigs)
+
+	spaces := types.Subnet{ID: "id", Alias: "spaces", Maintainers: []string{}, VMs: []string{"spacesvm"}}
+	assert.NoError(t, repoFactory.GetRepository([]byte(repoAlias)).Subnets.Put([]byte(spaces.Alias), storage.Definition[types.Subnet]{Definition: spaces}))
+	assert.NoError(t, joinedSubnets.Put([]byte(repoAlias+":spaces"), storage.JoinInfo{ID: spaces.ID}))
+
+	chainConfigs, joined, err = upgrade.chainConfigs(vm)
+	assert.NoError(t, err)
+	assert.True(t, joined)
+	assert.Equal(t, vm.ChainConfigs, chainConfigs)
+}